@@ -0,0 +1,83 @@
+#!/usr/bin/env python3
+"""Generate the application's configuration file for a job.
+
+Substitutes {{VARIABLE}} placeholders in a template, the built-in default
+or --template, and applies --param KEY=VALUE overrides to top-level
+'key: value' settings. Adjust DEFAULT_CONFIG and apply_param for your
+application's configuration format.
+"""
+
+import argparse
+import os
+import re
+import sys
+
+DEFAULT_CONFIG = """\
+input_dir: {{INPUT_DIR}}
+output_dir: {{OUTPUT_DIR}}
+num_threads: {{NUM_THREADS}}
+"""
+
+REQUIRED = ("input_dir", "output_dir")
+
+
+def substitute(template, variables):
+    """Replaces {{VARIABLE}} placeholders and fails on unknown ones"""
+    def lookup(m):
+        name = m.group(1)
+        if name not in variables:
+            sys.exit(f"Error: template placeholder {{{{{name}}}}} has no value")
+        return variables[name]
+    return re.sub(r"\{\{(\w+)\}\}", lookup, template)
+
+
+def apply_param(lines, key, value):
+    """Sets a top-level 'key: value' setting, adding it if missing"""
+    for i, line in enumerate(lines):
+        if re.match(rf"{re.escape(key)}:", line):
+            lines[i] = f"{key}: {value}"
+            return
+    lines.append(f"{key}: {value}")
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("--output", required=True, help="configuration file to write")
+    parser.add_argument("--template", help="template to use instead of the built-in default")
+    parser.add_argument("--input-dir", default="/data/input", help="input data directory")
+    parser.add_argument("--output-dir", default="/data/output", help="output data directory")
+    parser.add_argument("--param", action="append", default=[], help="KEY=VALUE")
+    args = parser.parse_args()
+
+    template = DEFAULT_CONFIG
+    if args.template:
+        with open(args.template) as f:
+            template = f.read()
+
+    variables = {
+        "INPUT_DIR": args.input_dir,
+        "OUTPUT_DIR": args.output_dir,
+        "NUM_THREADS": os.environ.get("OMP_NUM_THREADS", str(os.cpu_count())),
+    }
+    lines = substitute(template, variables).splitlines()
+
+    for param in args.param:
+        key, sep, value = param.partition("=")
+        if not sep:
+            sys.exit(f"Error: --param {param}: expected KEY=VALUE")
+        print(f"Overriding parameter: {key}={value}")
+        apply_param(lines, key, value)
+
+    keys = {line.split(":", 1)[0] for line in lines if ":" in line}
+    for key in REQUIRED:
+        if key not in keys:
+            sys.exit(f"Error: configuration has no {key} setting")
+
+    os.makedirs(os.path.dirname(args.output) or ".", exist_ok=True)
+    with open(args.output, "w") as f:
+        f.write("\n".join(lines) + "\n")
+    print(f"Configuration generated: {args.output}")
+
+
+if __name__ == "__main__":
+    main()
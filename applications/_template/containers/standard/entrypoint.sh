@@ -4,17 +4,12 @@
 
 set -euo pipefail
 
-# Source HPC environment
-source /etc/profile.d/hpc-base.sh
-
-# Print environment information
-echo "=========================================="
-echo "Application: your-app"
-echo "Version: ${APP_VERSION}"
-echo "Architecture: $(uname -m)"
-echo "Date: $(date)"
-echo "=========================================="
-echo ""
+# The generated Dockerfile sets APP_NAME, APP_ROOT and CONFIG_GENERATOR and
+# runs this script with the application's environment loaded
+APP_DATA=${APP_DATA:-/data/input}
+APP_OUTPUT=${APP_OUTPUT:-/data/output}
+RUN_DIR=/opt/run-dir
+CONFIG_GENERATOR=${CONFIG_GENERATOR:-/app/config-generator.py}
 
 # Function: Show usage
 show_usage() {
@@ -24,10 +19,10 @@ Usage: docker run your-app [OPTIONS]
 Options:
     --help                  Show this help message
     --version               Show application version
-    --input S3_PATH         S3 path to input data (required)
-    --output S3_PATH        S3 path for output data (required)
+    --input S3_PATH         S3 path to input data, or /data/input if mounted (required)
+    --output S3_PATH        S3 path for output data, or /data/output if mounted (required)
     --config FILE           Configuration file (optional)
-    --param KEY=VALUE       Override configuration parameter
+    --param KEY=VALUE       Override configuration parameter (repeatable)
 
 Examples:
     # Run with S3 input/output
@@ -51,7 +46,8 @@ EOF
 
 # Function: Show version
 show_version() {
-    echo "your-app version ${APP_VERSION}"
+    echo "${APP_NAME} $(uname -m)"
+    echo "Install: ${APP_ROOT}"
     echo "Platform: $(uname -s) $(uname -m)"
     echo "Compiler: $(gcc --version | head -n1)"
 }
@@ -60,7 +56,7 @@ show_version() {
 INPUT_S3=""
 OUTPUT_S3=""
 CONFIG_FILE=""
-declare -A PARAMS
+PARAMS=()
 
 while [[ $# -gt 0 ]]; do
     case $1 in
@@ -85,8 +81,7 @@ while [[ $# -gt 0 ]]; do
             shift 2
             ;;
         --param)
-            IFS='=' read -r key value <<< "$2"
-            PARAMS[$key]="$value"
+            PARAMS+=(--param "$2")
             shift 2
             ;;
         *)
@@ -110,6 +105,14 @@ if [[ -z "$OUTPUT_S3" ]]; then
     exit 1
 fi
 
+# Print environment information
+echo "=========================================="
+echo "Application: ${APP_NAME}"
+echo "Architecture: $(uname -m)"
+echo "Date: $(date)"
+echo "=========================================="
+echo ""
+
 # Set OpenMP threads if not already set
 if [[ -z "${OMP_NUM_THREADS:-}" ]]; then
     export OMP_NUM_THREADS=$(nproc)
@@ -121,10 +124,14 @@ echo "Downloading input data from ${INPUT_S3}..."
 START_TIME=$(date +%s)
 
 if [[ "$INPUT_S3" == s3://* ]]; then
+    mkdir -p "$APP_DATA"
     aws s3 sync "$INPUT_S3" "$APP_DATA/" --quiet
-    echo "Downloaded input data ($(du -sh $APP_DATA | cut -f1))"
+    echo "Downloaded input data ($(du -sh "$APP_DATA" | cut -f1))"
+elif [[ "$INPUT_S3" == "$APP_DATA" ]]; then
+    # Local runs (aws-hpc job submit --backend local) mount input directly
+    echo "Using mounted input data ($(du -sh "$APP_DATA" | cut -f1))"
 else
-    echo "Error: Input path must be S3 URI (s3://...)"
+    echo "Error: Input path must be S3 URI (s3://...) or ${APP_DATA}"
     exit 1
 fi
 
@@ -132,28 +139,16 @@ DOWNLOAD_TIME=$(($(date +%s) - START_TIME))
 echo "Download completed in ${DOWNLOAD_TIME} seconds"
 echo ""
 
-# Generate or copy configuration
+# Generate the configuration, from --config if given, with --param
+# overrides applied
 echo "Setting up configuration..."
+mkdir -p "$RUN_DIR" "$APP_OUTPUT"
 
+GENERATOR_ARGS=(--output "${RUN_DIR}/config.yaml" --input-dir "$APP_DATA" --output-dir "$APP_OUTPUT")
 if [[ -n "$CONFIG_FILE" ]]; then
-    # Use provided config
-    cp "$CONFIG_FILE" /opt/run-dir/config.yaml
-    echo "Using configuration from ${CONFIG_FILE}"
-else
-    # Generate default config from template
-    /app/config-generator \
-        --template /app/templates/config.yaml.template \
-        --output /opt/run-dir/config.yaml
-    echo "Generated default configuration"
+    GENERATOR_ARGS+=(--template "$CONFIG_FILE")
 fi
-
-# Apply parameter overrides
-for key in "${!PARAMS[@]}"; do
-    value="${PARAMS[$key]}"
-    echo "Overriding parameter: ${key}=${value}"
-    # Use sed or yq to modify config.yaml
-    # This is a placeholder - adjust based on your config format
-done
+python3 "$CONFIG_GENERATOR" "${GENERATOR_ARGS[@]}" "${PARAMS[@]}"
 
 echo ""
 
@@ -171,8 +166,8 @@ RUN_START_TIME=$(date +%s)
 ${APP_ROOT}/bin/your-app \
     --input "${APP_DATA}" \
     --output "${APP_OUTPUT}" \
-    --config /opt/run-dir/config.yaml \
-    2>&1 | tee /opt/run-dir/application.log
+    --config ${RUN_DIR}/config.yaml \
+    2>&1 | tee ${RUN_DIR}/application.log
 
 EXIT_CODE=${PIPESTATUS[0]}
 
@@ -182,7 +177,7 @@ echo "Application completed in ${RUN_TIME} seconds"
 
 if [[ $EXIT_CODE -ne 0 ]]; then
     echo "Error: Application exited with code ${EXIT_CODE}"
-    echo "Check /opt/run-dir/application.log for details"
+    echo "Check ${RUN_DIR}/application.log for details"
     exit $EXIT_CODE
 fi
 
@@ -193,12 +188,15 @@ UPLOAD_START_TIME=$(date +%s)
 
 if [[ "$OUTPUT_S3" == s3://* ]]; then
     # Also upload the log file
-    cp /opt/run-dir/application.log "${APP_OUTPUT}/"
+    cp ${RUN_DIR}/application.log "${APP_OUTPUT}/"
 
     aws s3 sync "${APP_OUTPUT}/" "$OUTPUT_S3" --quiet
-    echo "Uploaded results ($(du -sh $APP_OUTPUT | cut -f1))"
+    echo "Uploaded results ($(du -sh "$APP_OUTPUT" | cut -f1))"
+elif [[ "$OUTPUT_S3" == "$APP_OUTPUT" ]]; then
+    cp ${RUN_DIR}/application.log "${APP_OUTPUT}/"
+    echo "Results written to mounted output directory"
 else
-    echo "Error: Output path must be S3 URI (s3://...)"
+    echo "Error: Output path must be S3 URI (s3://...) or ${APP_OUTPUT}"
     exit 1
 fi
 
@@ -0,0 +1,92 @@
+#!/usr/bin/env python3
+"""Configure a GEOS-Chem Classic run directory for a job.
+
+Sets the simulation period and the data directory in geoschem_config.yml
+from --param values, and checks that the run directory was created for the
+requested simulation and resolution, which createRunDir.sh fixes.
+"""
+
+import argparse
+import re
+import sys
+
+# --param resolution values and how geoschem_config.yml writes them
+RESOLUTIONS = {
+    "4x5": "4.0x5.0",
+    "2x25": "2.0x2.5",
+    "05x0625": "0.5x0.625",
+    "025x03125": "0.25x0.3125",
+}
+
+PARAMS = ("simulation", "resolution", "start_date", "end_date")
+
+
+def parse_params(values):
+    params = {}
+    for value in values:
+        key, sep, val = value.partition("=")
+        if not sep:
+            sys.exit(f"Error: --param {value}: expected KEY=VALUE")
+        if key not in PARAMS:
+            sys.exit(f"Error: unknown parameter {key}; expected one of {', '.join(PARAMS)}")
+        params[key] = val
+    for key in ("start_date", "end_date"):
+        if key in params and not re.fullmatch(r"\d{8}", params[key]):
+            sys.exit(f"Error: {key} {params[key]}: expected YYYYMMDD")
+    return params
+
+
+def setting(lines, key):
+    """Returns the value of the first 'key:' line, without quotes"""
+    for line in lines:
+        m = re.match(rf"\s*{key}:\s*(.*?)\s*(#.*)?$", line)
+        if m:
+            return m.group(1).strip("'\"")
+    return None
+
+
+def replace(lines, key, value):
+    """Replaces the value of the first 'key:' line"""
+    for i, line in enumerate(lines):
+        m = re.match(rf"(\s*{key}:\s*)", line)
+        if m:
+            lines[i] = f"{m.group(1)}{value}\n"
+            return
+    sys.exit(f"Error: geoschem_config.yml has no {key} setting")
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("--rundir", required=True, help="run directory to configure")
+    parser.add_argument("--data-dir", required=True, help="GEOS-Chem data directory (ExtData)")
+    parser.add_argument("--param", action="append", default=[], help="KEY=VALUE")
+    args = parser.parse_args()
+
+    params = parse_params(args.param)
+    path = f"{args.rundir}/geoschem_config.yml"
+    with open(path) as f:
+        lines = f.readlines()
+
+    if "simulation" in params and setting(lines, "name") != params["simulation"]:
+        sys.exit(f"Error: run directory is for simulation {setting(lines, 'name')}, not {params['simulation']}")
+    if "resolution" in params:
+        want = RESOLUTIONS.get(params["resolution"], params["resolution"])
+        if setting(lines, "resolution") != want:
+            sys.exit(f"Error: run directory is for resolution {setting(lines, 'resolution')}, not {want}")
+
+    replace(lines, "root_data_dir", args.data_dir)
+    if "start_date" in params:
+        replace(lines, "start_date", f"[{params['start_date']}, 000000]")
+    if "end_date" in params:
+        replace(lines, "end_date", f"[{params['end_date']}, 000000]")
+
+    with open(path, "w") as f:
+        f.writelines(lines)
+
+    print(f"Configured {path}:")
+    for key in ("name", "start_date", "end_date", "root_data_dir", "resolution"):
+        print(f"  {key}: {setting(lines, key)}")
+
+
+if __name__ == "__main__":
+    main()
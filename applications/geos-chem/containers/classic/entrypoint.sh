@@ -0,0 +1,165 @@
+#!/bin/bash
+# GEOS-Chem Classic entrypoint
+# Stages input data and a run directory, runs gcclassic and uploads the
+# run's output
+
+set -euo pipefail
+
+MOUNTED_INPUT=/data/input
+MOUNTED_OUTPUT=/data/output
+SCRATCH_DIR=${SCRATCH_DIR:-/scratch}
+RUN_DIR=${SCRATCH_DIR}/rundir
+
+show_usage() {
+    cat <<EOF
+Usage: geos-chem [OPTIONS]
+
+Options:
+    --help                  Show this help message
+    --version               Show GEOS-Chem build information
+    --input PATH            S3 prefix of the input data, or ${MOUNTED_INPUT} if mounted (required)
+    --output PATH           S3 prefix for the output, or ${MOUNTED_OUTPUT} if mounted (required)
+    --param KEY=VALUE       Simulation setting (repeatable):
+                              simulation  run directory to use, e.g. fullchem
+                              resolution  grid resolution, e.g. 4x5
+                              start_date  YYYYMMDD
+                              end_date    YYYYMMDD
+
+The input holds the GEOS-Chem data directory (ExtData) and, under
+rundirs/<simulation>/, run directories created with createRunDir.sh.
+
+Environment Variables:
+    OMP_NUM_THREADS  OpenMP threads (default: all CPUs)
+    SCRATCH_DIR      Working storage for input data and the run directory (default: /scratch)
+
+EOF
+}
+
+show_version() {
+    echo "GEOS-Chem Classic ($(uname -m))"
+    echo "Install: ${APP_ROOT}"
+    echo "Compiler: $(gcc --version | head -n1)"
+}
+
+INPUT=""
+OUTPUT=""
+PARAMS=()
+SIMULATION="fullchem"
+
+while [[ $# -gt 0 ]]; do
+    case $1 in
+        --help)
+            show_usage
+            exit 0
+            ;;
+        --version)
+            show_version
+            exit 0
+            ;;
+        --input)
+            INPUT="$2"
+            shift 2
+            ;;
+        --output)
+            OUTPUT="$2"
+            shift 2
+            ;;
+        --param)
+            PARAMS+=("$2")
+            if [[ "$2" == simulation=* ]]; then
+                SIMULATION="${2#simulation=}"
+            fi
+            shift 2
+            ;;
+        *)
+            echo "Error: Unknown option $1"
+            show_usage
+            exit 1
+            ;;
+    esac
+done
+
+if [[ -z "$INPUT" || -z "$OUTPUT" ]]; then
+    echo "Error: --input and --output are required"
+    show_usage
+    exit 1
+fi
+
+if [[ -z "${OMP_NUM_THREADS:-}" ]]; then
+    export OMP_NUM_THREADS=$(nproc)
+fi
+# GEOS-Chem needs a large stack for its OpenMP threads
+export OMP_STACKSIZE=500m
+ulimit -s unlimited || true
+
+echo "=========================================="
+echo "GEOS-Chem Classic"
+echo "Simulation: ${SIMULATION}"
+echo "Threads: ${OMP_NUM_THREADS}"
+echo "Date: $(date)"
+echo "=========================================="
+
+START_TIME=$(date +%s)
+
+# Stage the input
+if [[ "$INPUT" == s3://* ]]; then
+    DATA_DIR=${SCRATCH_DIR}/input
+    echo "Downloading input data from ${INPUT}..."
+    mkdir -p "$DATA_DIR"
+    aws s3 sync "$INPUT" "$DATA_DIR/" --quiet
+elif [[ "$INPUT" == "$MOUNTED_INPUT" ]]; then
+    DATA_DIR=$MOUNTED_INPUT
+else
+    echo "Error: Input must be an S3 prefix (s3://...) or ${MOUNTED_INPUT}"
+    exit 1
+fi
+echo "Input data: ${DATA_DIR} ($(du -sh "$DATA_DIR" | cut -f1))"
+
+# Set up the run directory
+TEMPLATE_DIR=${DATA_DIR}/rundirs/${SIMULATION}
+if [[ ! -f "${TEMPLATE_DIR}/geoschem_config.yml" ]]; then
+    echo "Error: No run directory for simulation ${SIMULATION} at ${TEMPLATE_DIR}"
+    exit 1
+fi
+rm -rf "$RUN_DIR"
+cp -r "$TEMPLATE_DIR" "$RUN_DIR"
+mkdir -p "${RUN_DIR}/OutputDir"
+
+GENERATOR_ARGS=(--rundir "$RUN_DIR" --data-dir "$DATA_DIR")
+for param in "${PARAMS[@]}"; do
+    GENERATOR_ARGS+=(--param "$param")
+done
+python3 "${CONFIG_GENERATOR:-/app/config-generator.py}" "${GENERATOR_ARGS[@]}"
+
+# Run the simulation
+echo ""
+echo "Starting gcclassic in ${RUN_DIR}..."
+RUN_START_TIME=$(date +%s)
+cd "$RUN_DIR"
+set +e
+"${APP_ROOT}/bin/gcclassic" 2>&1 | tee "${RUN_DIR}/OutputDir/gcclassic.log"
+EXIT_CODE=${PIPESTATUS[0]}
+set -e
+RUN_TIME=$(($(date +%s) - RUN_START_TIME))
+echo "gcclassic finished in ${RUN_TIME} seconds with exit code ${EXIT_CODE}"
+
+# Upload the output, including the log of a failed run
+cp geoschem_config.yml HEMCO_Config.rc HISTORY.rc OutputDir/ 2>/dev/null || true
+if [[ "$OUTPUT" == s3://* ]]; then
+    echo "Uploading output to ${OUTPUT}..."
+    aws s3 sync "${RUN_DIR}/OutputDir/" "$OUTPUT" --quiet
+elif [[ "$OUTPUT" == "$MOUNTED_OUTPUT" ]]; then
+    cp -r "${RUN_DIR}/OutputDir/." "$MOUNTED_OUTPUT/"
+else
+    echo "Error: Output must be an S3 prefix (s3://...) or ${MOUNTED_OUTPUT}"
+    exit 1
+fi
+
+echo ""
+echo "=========================================="
+echo "Simulation time: ${RUN_TIME}s"
+echo "Total time:      $(($(date +%s) - START_TIME))s"
+echo "Output:          ${OUTPUT}"
+echo "=========================================="
+
+exit "$EXIT_CODE"
@@ -0,0 +1,245 @@
+#!/bin/bash
+# GCHP entrypoint
+# Runs on every node of an AWS Batch multi-node parallel job. Worker nodes
+# register with the main node over SSH and wait; the main node stages the
+# input and run directory, runs gchp with MPI across all nodes and uploads
+# the run's output. Without AWS Batch node variables it runs on one node.
+
+set -euo pipefail
+
+MOUNTED_INPUT=/data/input
+MOUNTED_OUTPUT=/data/output
+SCRATCH_DIR=${SCRATCH_DIR:-/scratch}
+RUN_DIR=${SCRATCH_DIR}/rundir
+HOSTFILE=/tmp/hostfile
+REGISTER_TIMEOUT=600
+
+NUM_NODES=${AWS_BATCH_JOB_NUM_NODES:-1}
+NODE_INDEX=${AWS_BATCH_JOB_NODE_INDEX:-0}
+MAIN_NODE_INDEX=${AWS_BATCH_JOB_MAIN_NODE_INDEX:-0}
+
+show_usage() {
+    cat <<EOF
+Usage: geos-chem [OPTIONS]
+
+Options:
+    --help                  Show this help message
+    --version               Show GCHP build information
+    --input PATH            S3 prefix of the input data, or ${MOUNTED_INPUT} if mounted (required)
+    --output PATH           S3 prefix for the output, or ${MOUNTED_OUTPUT} if mounted (required)
+    --param KEY=VALUE       Simulation setting (repeatable):
+                              simulation  run directory to use, e.g. fullchem
+                              resolution  cubed-sphere resolution, e.g. c48
+                              start_date  YYYYMMDD
+                              end_date    YYYYMMDD
+
+The input holds the GEOS-Chem data directory (ExtData) and, under
+rundirs/<simulation>/, GCHP run directories created with createRunDir.sh.
+
+EOF
+}
+
+show_version() {
+    echo "GCHP ($(uname -m))"
+    echo "Install: ${APP_ROOT}"
+    echo "Compiler: $(gcc --version | head -n1)"
+    mpirun --version | head -n1
+}
+
+# node_ip prints this node's private IPv4 address
+node_ip() {
+    hostname -I | awk '{print $1}'
+}
+
+# duration prints the GCHP run duration between two YYYYMMDD dates as
+# "YYYYMMDD hhmmss" years, months and days
+duration() {
+    local start=$1 end=$2
+    local y=$((10#${end:0:4} - 10#${start:0:4}))
+    local m=$((10#${end:4:2} - 10#${start:4:2}))
+    local d=$((10#${end:6:2} - 10#${start:6:2}))
+    if ((d < 0)); then
+        m=$((m - 1))
+        d=$((d + 10#$(date -d "${start:0:4}-${start:4:2}-01 +1 month -1 day" +%d)))
+    fi
+    if ((m < 0)); then
+        y=$((y - 1))
+        m=$((m + 12))
+    fi
+    printf "%04d%02d%02d 000000" "$y" "$m" "$d"
+}
+
+INPUT=""
+OUTPUT=""
+SIMULATION="fullchem"
+RESOLUTION=""
+START_DATE=""
+END_DATE=""
+
+while [[ $# -gt 0 ]]; do
+    case $1 in
+        --help)
+            show_usage
+            exit 0
+            ;;
+        --version)
+            show_version
+            exit 0
+            ;;
+        --input)
+            INPUT="$2"
+            shift 2
+            ;;
+        --output)
+            OUTPUT="$2"
+            shift 2
+            ;;
+        --param)
+            IFS='=' read -r key value <<< "$2"
+            case $key in
+                simulation) SIMULATION="$value" ;;
+                resolution) RESOLUTION="${value#c}" ;;
+                start_date) START_DATE="$value" ;;
+                end_date) END_DATE="$value" ;;
+                *)
+                    echo "Error: Unknown parameter ${key}"
+                    exit 1
+                    ;;
+            esac
+            shift 2
+            ;;
+        *)
+            echo "Error: Unknown option $1"
+            show_usage
+            exit 1
+            ;;
+    esac
+done
+
+if [[ -z "$INPUT" || -z "$OUTPUT" ]]; then
+    echo "Error: --input and --output are required"
+    show_usage
+    exit 1
+fi
+
+# Nodes reach each other with the SSH key built into the image
+if ((NUM_NODES > 1)); then
+    /usr/sbin/sshd
+fi
+
+if ((NODE_INDEX != MAIN_NODE_INDEX)); then
+    MAIN_IP=${AWS_BATCH_JOB_MAIN_NODE_PRIVATE_IPV4_ADDRESS}
+    echo "Worker node ${NODE_INDEX}: registering $(node_ip) with ${MAIN_IP}"
+    deadline=$(($(date +%s) + REGISTER_TIMEOUT))
+    until ssh -o StrictHostKeyChecking=no -o ConnectTimeout=5 "$MAIN_IP" "echo $(node_ip) >> ${HOSTFILE}"; do
+        if (($(date +%s) > deadline)); then
+            echo "Error: Could not reach the main node at ${MAIN_IP}"
+            exit 1
+        fi
+        sleep 5
+    done
+    # AWS Batch stops the worker nodes when the main node exits
+    sleep infinity
+fi
+
+echo "=========================================="
+echo "GCHP"
+echo "Simulation: ${SIMULATION}"
+echo "Nodes: ${NUM_NODES}"
+echo "Date: $(date)"
+echo "=========================================="
+
+START_TIME=$(date +%s)
+
+# Stage the input
+if [[ "$INPUT" == s3://* ]]; then
+    DATA_DIR=${SCRATCH_DIR}/input
+    echo "Downloading input data from ${INPUT}..."
+    mkdir -p "$DATA_DIR"
+    aws s3 sync "$INPUT" "$DATA_DIR/" --quiet
+elif [[ "$INPUT" == "$MOUNTED_INPUT" ]]; then
+    DATA_DIR=$MOUNTED_INPUT
+else
+    echo "Error: Input must be an S3 prefix (s3://...) or ${MOUNTED_INPUT}"
+    exit 1
+fi
+
+# Set up the run directory
+TEMPLATE_DIR=${DATA_DIR}/rundirs/${SIMULATION}
+if [[ ! -f "${TEMPLATE_DIR}/setCommonRunSettings.sh" ]]; then
+    echo "Error: No GCHP run directory for simulation ${SIMULATION} at ${TEMPLATE_DIR}"
+    exit 1
+fi
+rm -rf "$RUN_DIR"
+cp -r "$TEMPLATE_DIR" "$RUN_DIR"
+cd "$RUN_DIR"
+mkdir -p OutputDir Restarts
+ln -sfn "$DATA_DIR" ExtData
+
+# GCHP runs on a multiple of 6 cores, one cubed-sphere face per 1/6
+CORES_PER_NODE=$(nproc)
+TOTAL_CORES=$(((NUM_NODES * CORES_PER_NODE) / 6 * 6))
+sed -i \
+    -e "s/^TOTAL_CORES=.*/TOTAL_CORES=${TOTAL_CORES}/" \
+    -e "s/^NUM_NODES=.*/NUM_NODES=${NUM_NODES}/" \
+    -e "s/^NUM_CORES_PER_NODE=.*/NUM_CORES_PER_NODE=${CORES_PER_NODE}/" \
+    setCommonRunSettings.sh
+if [[ -n "$RESOLUTION" ]]; then
+    sed -i "s/^CS_RES=.*/CS_RES=${RESOLUTION}/" setCommonRunSettings.sh
+fi
+if [[ -n "$START_DATE" ]]; then
+    echo "${START_DATE} 000000" > cap_restart
+fi
+if [[ -n "$START_DATE" && -n "$END_DATE" ]]; then
+    sed -i "s/^Run_Duration=.*/Run_Duration=\"$(duration "$START_DATE" "$END_DATE")\"/" setCommonRunSettings.sh
+fi
+./setCommonRunSettings.sh
+
+# Wait for the worker nodes
+echo "$(node_ip) slots=${CORES_PER_NODE}" > "${HOSTFILE}.main"
+if ((NUM_NODES > 1)); then
+    touch "$HOSTFILE"
+    deadline=$(($(date +%s) + REGISTER_TIMEOUT))
+    while (($(wc -l < "$HOSTFILE") < NUM_NODES - 1)); do
+        if (($(date +%s) > deadline)); then
+            echo "Error: Only $(wc -l < "$HOSTFILE") of $((NUM_NODES - 1)) worker nodes registered"
+            exit 1
+        fi
+        sleep 5
+    done
+    sed "s/\$/ slots=${CORES_PER_NODE}/" "$HOSTFILE" >> "${HOSTFILE}.main"
+fi
+
+# Run the simulation
+echo ""
+echo "Starting gchp on ${TOTAL_CORES} cores..."
+RUN_START_TIME=$(date +%s)
+set +e
+mpirun --allow-run-as-root -np "$TOTAL_CORES" --hostfile "${HOSTFILE}.main" \
+    --mca plm_rsh_args "-o StrictHostKeyChecking=no" \
+    "${APP_ROOT}/bin/gchp" 2>&1 | tee OutputDir/gchp.log
+EXIT_CODE=${PIPESTATUS[0]}
+set -e
+RUN_TIME=$(($(date +%s) - RUN_START_TIME))
+echo "gchp finished in ${RUN_TIME} seconds with exit code ${EXIT_CODE}"
+
+# Upload the output, including the log of a failed run
+cp setCommonRunSettings.sh cap_restart OutputDir/ 2>/dev/null || true
+if [[ "$OUTPUT" == s3://* ]]; then
+    echo "Uploading output to ${OUTPUT}..."
+    aws s3 sync OutputDir/ "$OUTPUT" --quiet
+elif [[ "$OUTPUT" == "$MOUNTED_OUTPUT" ]]; then
+    cp -r OutputDir/. "$MOUNTED_OUTPUT/"
+else
+    echo "Error: Output must be an S3 prefix (s3://...) or ${MOUNTED_OUTPUT}"
+    exit 1
+fi
+
+echo ""
+echo "=========================================="
+echo "Simulation time: ${RUN_TIME}s"
+echo "Total time:      $(($(date +%s) - START_TIME))s"
+echo "Output:          ${OUTPUT}"
+echo "=========================================="
+
+exit "$EXIT_CODE"
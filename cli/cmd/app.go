@@ -15,11 +15,27 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/aws-hpc/pkg/config"
+	"github.com/aws-hpc/platform/pkg"
+	"github.com/aws-hpc/platform/pkg/baseimage"
+	"github.com/aws-hpc/platform/pkg/command"
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/container"
+	"github.com/aws-hpc/platform/pkg/deploy"
+	"github.com/aws-hpc/platform/pkg/deploy/batch"
+	"github.com/aws-hpc/platform/pkg/deploy/cloudformation"
+	"github.com/aws-hpc/platform/pkg/deploy/terraform"
 )
 
 var appCmd = &cobra.Command{
@@ -29,20 +45,58 @@ var appCmd = &cobra.Command{
 }
 
 var appValidateCmd = &cobra.Command{
-	Use:   "validate [path]",
+	Use:   "validate [name|path]",
 	Short: "Validate application specification",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		appPath := args[0]
+	Long: `Validate an application specification, reporting every problem at once
+with its YAML path, line and column.
+
+The file is checked against the app.yaml JSON Schema (see app schema) for
+unknown fields, value types, required fields and enum values. Validation
+also checks for duplicate keys and names, queues that reference undeclared
+architectures, cost scaling factors and container variants that match
+nothing, and multi-node variants without EFA networking.
 
+Each architecture's base_image is resolved against the base images defined
+under --base-images. A base image that is not defined is an error; compiler
+flags or a math library that differ from what the base image declares are
+warnings.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appPath, err := appDir(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
+			os.Exit(1)
+		}
+		baseDir := baseImagesDir(cmd, appPath)
+		overlays, err := appOverlays(appPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Printf("Validating application at %s...\n", appPath)
+		for _, o := range overlays {
+			fmt.Printf("Overlay: %s\n", o)
+		}
 
-		app, err := config.LoadApplication(appPath)
+		catalog, err := baseimage.LoadCatalog(baseDir)
+		if err != nil {
+			fmt.Printf("⚠ Skipping base image checks: %v\n", err)
+			catalog = nil
+		}
+		app, report, err := checkApp(appPath, catalog)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
 			os.Exit(1)
 		}
 
+		for _, f := range report.Findings {
+			fmt.Printf("%s: %s\n", report.Location(f), f)
+		}
+		if n := report.Errors(); n > 0 {
+			fmt.Fprintf(os.Stderr, "\nValidation failed: %d errors, %d warnings\n", n, len(report.Findings)-n)
+			os.Exit(1)
+		}
+
 		fmt.Println("✓ Validation passed")
 		fmt.Printf("\nApplication: %s v%s\n", app.DisplayName, app.Version)
 		fmt.Printf("Platform version: %s\n", app.PlatformVersion)
@@ -58,13 +112,231 @@ var appValidateCmd = &cobra.Command{
 	},
 }
 
+var appSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of app.yaml",
+	Long: `Print the JSON Schema of app.yaml, generated from the platform's
+application types. It documents every field and its allowed values, and is
+the same schema app validate checks against.
+
+Point an editor at it for validation and completion, for example with the
+YAML language server, by adding to the top of app.yaml:
+
+  # yaml-language-server: $schema=app.schema.json
+
+Examples:
+  aws-hpc app schema
+  aws-hpc app schema --output applications/myapp/app.schema.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		data, err := json.MarshalIndent(config.AppSchema(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		data = append(data, '\n')
+
+		if output == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote app.yaml schema to %s\n", output)
+	},
+}
+
+var appMigrateCmd = &cobra.Command{
+	Use:   "migrate [name|path]",
+	Short: "Upgrade app.yaml to the current spec version",
+	Long: `Upgrade an application's app.yaml to the spec version this platform
+writes, applying each migration in turn, and rewrite the file in place.
+
+Comments and blank lines are kept. Files without a spec_version are treated
+as spec 0.1.0. Older files still load, migrated in memory, but app validate
+warns until they are migrated.
+
+Examples:
+  aws-hpc app migrate applications/myapp
+  aws-hpc app migrate applications/myapp --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		appPath, err := appDir(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		file := filepath.Join(appPath, "app.yaml")
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		migrated, applied, err := config.MigrateDocument(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			os.Stdout.Write(migrated)
+			return
+		}
+		if len(applied) == 0 {
+			fmt.Printf("%s is already at spec %s\n", file, pkg.AppSpecVersion)
+			return
+		}
+
+		if err := os.WriteFile(file, migrated, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range applied {
+			fmt.Printf("  → %s: %s\n", m.To, m.Description)
+		}
+		fmt.Printf("✓ Migrated %s to spec %s\n", file, pkg.AppSpecVersion)
+	},
+}
+
+var appRenderConfigCmd = &cobra.Command{
+	Use:   "render-config [name|path]",
+	Short: "Print the effective app.yaml with overlays merged",
+	Long: `Print the specification other commands use for an application: its
+app.yaml migrated to the current spec version, with the site overlay
+(app.<site>.yaml, for --site or AWS_HPC_SITE) and any --overlay files
+merged over it, in that order.
+
+Overlays merge mappings key by key, and lists of named items, such as
+variants, architectures and queues, item by item: an item named like one in
+app.yaml merges into it and other items are appended. Other lists and
+scalars replace the base value. Tag a value !replace to replace it instead
+of merging, or a key or list item !delete to remove it:
+
+  # applications/geos-chem/app.geos-lab.yaml
+  compute:
+    architectures:
+      - !delete {name: "c5a"}
+    batch:
+      max_vcpus: 256
+  storage:
+    output:
+      bucket: "geos-lab-results"
+
+Problems in the result are printed to stderr.
+
+Examples:
+  aws-hpc app render-config geos-chem --site geos-lab
+  aws-hpc app render-config geos-chem --overlay ~/geos-chem.dev.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := appDir(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		overlays, err := appOverlays(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		_, report, err := config.CheckApplication(dir, overlays...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		data, err := report.Effective()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("# Effective specification: %s\n", strings.Join(append([]string{report.File}, overlays...), " + "))
+		os.Stdout.Write(data)
+		for _, f := range report.Findings {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", report.Location(f), f)
+		}
+		if report.Errors() > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
 var appListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available applications",
+	Long: `List the applications in the application search paths, in order:
+
+  1. the directories in AWS_HPC_APP_PATH (separated like PATH)
+  2. ~/.aws-hpc/apps (or $AWS_HPC_HOME/apps)
+  3. applications/ in the repository checkout containing the working
+     directory or the aws-hpc executable
+
+Each directory with an app.yaml is an application, named after the
+directory; directories starting with _ or ., such as _template, are
+skipped. When two search paths have an application of the same name, the
+first wins. Every application is validated as app validate does, with
+its overlays and base images; see app validate for the full findings.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// TODO: Implement listing applications
-		fmt.Println("Available applications:")
-		fmt.Println("  geos-chem - Global 3-D atmospheric chemistry transport model")
+		// Applications from different search paths are checked against
+		// the base images beside them; each catalog is loaded once
+		catalogs := map[string]*baseimage.Catalog{}
+		registry := config.DefaultRegistry()
+		registry.Check = func(dir string) (*config.Application, *config.Report, error) {
+			baseDir := baseImagesDir(cmd, dir)
+			catalog, ok := catalogs[baseDir]
+			if !ok {
+				var err error
+				if catalog, err = baseimage.LoadCatalog(baseDir); err != nil {
+					fmt.Printf("⚠ Skipping base image checks: %v\n\n", err)
+					catalog = nil
+				}
+				catalogs[baseDir] = catalog
+			}
+			return checkApp(dir, catalog)
+		}
+		entries := registry.Discover()
+		if Verbose {
+			fmt.Println("Search paths:")
+			for _, path := range registry.Paths {
+				fmt.Printf("  %s\n", path)
+			}
+			fmt.Println()
+		}
+		if len(entries) == 0 {
+			fmt.Printf("No applications found. Set %s or add applications to ~/.aws-hpc/apps\n", config.AppPathEnv)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tVARIANTS\tARCHITECTURES\tSTATUS\tPATH")
+		for _, e := range entries {
+			if e.Err != nil {
+				fmt.Fprintf(w, "%s\t-\t-\t-\t✗ %v\t%s\n", e.Name, e.Err, e.Dir)
+				continue
+			}
+			var variants []string
+			for _, v := range e.App.Variants {
+				variants = append(variants, v.Name)
+			}
+			errors := e.Report.Errors()
+			warnings := len(e.Report.Findings) - errors
+			status := "✓ valid"
+			if errors > 0 {
+				status = "✗ " + count(errors, "error")
+			}
+			if warnings > 0 {
+				status += ", " + count(warnings, "warning")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", e.Name, e.App.Version,
+				strings.Join(variants, ","), len(e.App.Compute.Architectures), status, e.Dir)
+		}
+		w.Flush()
 		fmt.Println("\nUse 'aws-hpc app info <name>' for details")
 	},
 }
@@ -76,34 +348,50 @@ var appInfoCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
 
-		// Load application from applications directory
-		appPath := fmt.Sprintf("applications/%s", appName)
-		app, err := config.LoadApplication(appPath)
+		app, err := loadApp(appName)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Application: %s\n", app.DisplayName)
-		fmt.Printf("Version: %s\n", app.Version)
-		fmt.Printf("Description: %s\n", app.Metadata.Description)
-		fmt.Printf("Homepage: %s\n", app.Metadata.Homepage)
-		fmt.Printf("License: %s\n", app.Metadata.License)
+		// Values resolved from ${secret:name} references are not shown
+		out := redactWriter{app: app, w: os.Stdout}
+		fmt.Fprintf(out, "Application: %s\n", app.DisplayName)
+		fmt.Fprintf(out, "Version: %s\n", app.Version)
+		fmt.Fprintf(out, "Description: %s\n", app.Metadata.Description)
+		fmt.Fprintf(out, "Homepage: %s\n", app.Metadata.Homepage)
+		fmt.Fprintf(out, "License: %s\n", app.Metadata.License)
 
-		fmt.Println("\nVariants:")
+		fmt.Fprintln(out, "\nVariants:")
 		for _, variant := range app.Variants {
-			fmt.Printf("  %s - %s\n", variant.Name, variant.Description)
+			fmt.Fprintf(out, "  %s - %s\n", variant.Name, variant.Description)
 		}
 
-		fmt.Println("\nSupported Architectures:")
+		fmt.Fprintln(out, "\nSupported Architectures:")
 		for _, arch := range app.Compute.Architectures {
-			fmt.Printf("  %s (%s %s) - %s\n",
+			fmt.Fprintf(out, "  %s (%s %s) - %s\n",
 				arch.Name, arch.Family, arch.Generation, arch.BaseImage)
 		}
 
-		fmt.Println("\nEnvironments:")
+		fmt.Fprintln(out, "\nStorage:")
+		for _, loc := range []struct {
+			name string
+			config.StorageLocation
+		}{{"input", app.Storage.Input}, {"output", app.Storage.Output}} {
+			if loc.Type == "s3" && loc.Bucket != "" {
+				fmt.Fprintf(out, "  %s - s3://%s/%s\n", loc.name, loc.Bucket, loc.Prefix)
+			} else if loc.Type != "" {
+				fmt.Fprintf(out, "  %s - %s\n", loc.name, loc.Type)
+			}
+		}
+
+		if l := app.Licensing; l.Type != "" && l.Type != "none" {
+			fmt.Fprintf(out, "\nLicensing: %s %s\n", l.Type, l.Server)
+		}
+
+		fmt.Fprintln(out, "\nEnvironments:")
 		for _, env := range app.Environments {
-			fmt.Printf("  %s - %s\n", env.Name, env.Description)
+			fmt.Fprintf(out, "  %s - %s\n", env.Name, env.Description)
 		}
 	},
 }
@@ -113,6 +401,13 @@ var appBuildCmd = &cobra.Command{
 	Short: "Build application containers",
 	Long: `Build container images for specified application and architecture.
 
+Every variant is built for each selected architecture with docker buildx,
+using the architecture's base image, compiler flags and math library as
+build args. Pushing to ECR requires a prior docker login to the registry.
+
+Builds run in parallel up to --concurrency. A failed build does not stop
+the others; a summary of every build is printed at the end.
+
 Examples:
   # Build for specific architecture
   aws-hpc app build geos-chem --arch c7a
@@ -121,7 +416,10 @@ Examples:
   aws-hpc app build geos-chem --all-arch
 
   # Build and push to registry
-  aws-hpc app build geos-chem --arch c7a --push`,
+  aws-hpc app build geos-chem --arch c7a --push
+
+  # Build the classic variant for every AMD architecture, four at a time
+  aws-hpc app build geos-chem --all-arch --variant classic --family amd --concurrency 4`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
@@ -129,20 +427,247 @@ Examples:
 		allArch, _ := cmd.Flags().GetBool("all-arch")
 		push, _ := cmd.Flags().GetBool("push")
 		noPush, _ := cmd.Flags().GetBool("no-push")
+		registry, _ := cmd.Flags().GetString("registry")
+		account, _ := cmd.Flags().GetString("account")
+		region, _ := cmd.Flags().GetString("region")
+		variants, _ := cmd.Flags().GetStringSlice("variant")
+		families, _ := cmd.Flags().GetStringSlice("family")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
 
 		if !allArch && arch == "" {
 			fmt.Fprintln(os.Stderr, "Error: Either --arch or --all-arch must be specified")
 			os.Exit(1)
 		}
+		push = push && !noPush
 
-		fmt.Printf("Building application: %s\n", appName)
-		fmt.Printf("Architecture: %s\n", arch)
-		if push && !noPush {
+		dir, err := appDir(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+		app, err := loadApp(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		if registry == "" && push {
+			if app.Containers.Registry == "ecr" && account == "" {
+				account, err = container.AccountID(ctx, command.Exec{})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			registry = container.Registry(app.Containers.Registry, account, region)
+		}
+
+		filter := container.Filter{Variants: variants, Families: families}
+		if !allArch {
+			filter.Architectures = []string{arch}
+		}
+		builder := container.NewBuilder(dir, registry, push)
+		builds, err := builder.Builds(app, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Building application: %s (%d images, %d at a time)\n", app.Name, len(builds), concurrency)
+		if push {
 			fmt.Println("Will push to registry after build")
 		}
+		for _, build := range builds {
+			fmt.Printf("  %s (%s)\n", build.Tag, build.Platform)
+			if Verbose {
+				fmt.Printf("    docker %s\n", strings.Join(build.Args(), " "))
+			}
+		}
+		fmt.Println()
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		results := builder.RunMatrix(ctx, builds, concurrency, redactWriter{app: app, w: os.Stdout})
+
+		failed := printBuildSummary(results, [2]string{"VARIANT", "ARCH"})
+		manifestsFailed := 0
+		if push {
+			manifestsFailed = publishManifests(ctx, builder, app, results)
+		}
+		stop()
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "\nError: %d of %d builds failed\n", failed, len(results))
+			os.Exit(1)
+		}
+		if manifestsFailed > 0 {
+			fmt.Fprintf(os.Stderr, "\nError: %d manifest lists failed\n", manifestsFailed)
+			os.Exit(1)
+		}
+		fmt.Printf("\n✓ Built %d images\n", len(results))
+	},
+}
+
+// publishManifests creates the manifest list of each variant whose images
+// were all pushed by this build, and returns the number that failed
+func publishManifests(ctx context.Context, builder *container.Builder, app *config.Application, results []container.Result) int {
+	pushed := map[string]bool{}
+	built := map[string]bool{}
+	for _, r := range results {
+		built[r.Build.Variant] = true
+		if r.Err == nil {
+			pushed[r.Build.Tag] = true
+		}
+	}
+
+	failed := 0
+	for _, list := range container.ManifestLists(app, builder.Registry) {
+		if !built[list.Variant] {
+			continue
+		}
+		var missing []string
+		for _, img := range list.Images {
+			if !pushed[img.Ref] {
+				missing = append(missing, img.Architecture)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Printf("\nSkipping manifest list %s: not built for %s\n", list.Ref, strings.Join(missing, ", "))
+			continue
+		}
+		fmt.Printf("\nCreating manifest list %s\n", list.Ref)
+		if err := builder.CreateManifest(ctx, list, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "  %v\n", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+var appImagesCmd = &cobra.Command{
+	Use:   "images [name]",
+	Short: "List application image tags",
+	Long: `List the image tags app build produces for every variant and architecture.
+
+Each image has an immutable tag that pins the application version,
+architecture and base image, and a moving alias:
+
+  <repository>:<variant>-<version>-<arch>[-<base tag>]
+  <repository>:<variant>-<arch>
+
+When pushing, app build also publishes a manifest list per variant,
+<repository>:<variant>-<version> and <repository>:<variant>, so a job can
+reference one tag and the runtime pulls the image for its platform. Each
+platform is served by the first of its architectures in app.yaml; the
+manifest list is only published once all of those images are pushed.
+
+Examples:
+  aws-hpc app images geos-chem
+  aws-hpc app images geos-chem --account 123456789012 --region us-west-2`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appName := args[0]
+		registry, _ := cmd.Flags().GetString("registry")
+		account, _ := cmd.Flags().GetString("account")
+		region, _ := cmd.Flags().GetString("region")
+
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+		if registry == "" && account != "" {
+			registry = container.Registry(app.Containers.Registry, account, region)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VARIANT\tARCH\tPLATFORM\tTAG\tALIAS")
+		for _, img := range container.Images(app, registry) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", img.Variant, img.Architecture, img.Platform, img.Ref, img.Alias)
+		}
+		w.Flush()
 
-		// TODO: Implement container build
-		fmt.Println("\n[NOT IMPLEMENTED] Container build functionality coming soon")
+		fmt.Println("\nManifest lists:")
+		for _, list := range container.ManifestLists(app, registry) {
+			fmt.Printf("  %s (%s)\n", list.Ref, list.Alias)
+			for _, img := range list.Images {
+				fmt.Printf("    %-13s %s\n", img.Platform, img.Ref)
+			}
+		}
+	},
+}
+
+// printBuildSummary prints one row per build and returns the failure count.
+// The first two columns are the build's Variant and Architecture, titled
+// by columns.
+func printBuildSummary(results []container.Result, columns [2]string) int {
+	fmt.Println("\nBuild summary:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\tSTATUS\tDURATION\tDIGEST\n", columns[0], columns[1])
+
+	failed := 0
+	for _, r := range results {
+		status, digest := "ok", orDash(r.Digest)
+		if r.Err != nil {
+			status, digest = "FAILED", "-"
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			r.Build.Variant, r.Build.Architecture, status, r.Duration.Round(time.Second), digest)
+	}
+	w.Flush()
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "  %s-%s: %v\n", r.Build.Variant, r.Build.Architecture, r.Err)
+		}
+	}
+	return failed
+}
+
+var appDockerfileCmd = &cobra.Command{
+	Use:   "dockerfile [name]",
+	Short: "Generate an application Dockerfile",
+	Long: `Generate the Dockerfile of a variant for an architecture from app.yaml.
+
+Variants without a dockerfile in app.yaml are built from a generated one:
+FROM the architecture's base image, Spack installs of the container
+dependencies, the architecture's compiler flags, the variant's install
+commands, and the entrypoint and config generator scripts from
+containers/<variant>/. Use this command to review what app build runs.
+
+Examples:
+  aws-hpc app dockerfile geos-chem --variant classic --arch c7a
+  aws-hpc app dockerfile geos-chem --variant gchp --arch graviton4 --output Dockerfile`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appName := args[0]
+		variant, _ := cmd.Flags().GetString("variant")
+		arch, _ := cmd.Flags().GetString("arch")
+		output, _ := cmd.Flags().GetString("output")
+
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+
+		data, err := container.Dockerfile(app, variant, arch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// app build uses the secrets' values; the reviewed copy does not
+		data = []byte(app.Redact(string(data)))
+
+		if output == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote Dockerfile for %s %s on %s to %s\n", app.Name, variant, arch, output)
 	},
 }
 
@@ -151,45 +676,478 @@ var appDeployCmd = &cobra.Command{
 	Short: "Deploy application infrastructure",
 	Long: `Deploy AWS infrastructure for the specified application.
 
-This creates:
+This creates a CloudFormation stack with:
   - AWS Batch compute environments
   - Job queues
+  - Job definitions for every variant and architecture
   - IAM roles and policies
   - S3 buckets (if needed)
+  - Shared file systems and placement groups (if configured)
+
+Use --dry-run to write the template to disk without deploying it, or
+--plan to compare the infrastructure with the last successful deploy of
+the same app, environment and region, with destructive changes flagged.
+
+With --format terraform, a Terraform JSON module is written instead, for
+applying with terraform alongside existing state. The app name and
+environment are module variables. Writing the module records it as the
+last deploy for --plan, unless --dry-run is given.
 
 Examples:
-  aws-hpc app deploy geos-chem --env production
-  aws-hpc app deploy gaussian --env test --region us-west-2`,
+  aws-hpc app deploy geos-chem --env production --subnets subnet-1,subnet-2 --security-groups sg-1
+  aws-hpc app deploy geos-chem --env production --dry-run
+  aws-hpc app deploy geos-chem --env production --plan
+  aws-hpc app deploy gaussian --env test --region us-west-2 --dry-run --output gaussian.json
+  aws-hpc app deploy geos-chem --env production --format terraform`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
 		env, _ := cmd.Flags().GetString("env")
 		region, _ := cmd.Flags().GetString("region")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		output, _ := cmd.Flags().GetString("output")
+		stackName, _ := cmd.Flags().GetString("stack-name")
+		subnets, _ := cmd.Flags().GetStringSlice("subnets")
+		securityGroups, _ := cmd.Flags().GetStringSlice("security-groups")
+		format, _ := cmd.Flags().GetString("format")
+		plan, _ := cmd.Flags().GetBool("plan")
+
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := app.GetEnvironment(env); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if plan {
+			home, err := config.HomeDir()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			desired, err := deploy.NewSnapshot(app, env, region)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			deployed, err := deploy.LoadSnapshot(deploy.SnapshotPath(home, app.Name, env, region))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			printPlan(app.Name, env, region, deployed, deploy.Diff(deployed, desired))
+			return
+		}
+
+		switch format {
+		case "cloudformation":
+		case "terraform":
+			m, err := terraform.Render(app, env)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			data, err := m.JSON()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if output == "" {
+				output = terraform.FileName(app.Name, env)
+			}
+			if err := os.WriteFile(output, []byte(app.Redact(string(data))), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Wrote Terraform module for %s (%s) to %s\n", app.Name, env, output)
+			fmt.Println("Set subnet_ids and security_group_ids, then run terraform plan and apply")
+			if !dryRun {
+				saveDeploySnapshot(app, env, region)
+			}
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown format %q (expected cloudformation or terraform)\n", format)
+			os.Exit(1)
+		}
+
+		tmpl, err := cloudformation.Render(app, env, cloudformation.Options{Subnets: len(subnets)})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		data, err := tmpl.JSON()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			if output == "" {
+				output = fmt.Sprintf("%s-%s.template.json", app.Name, env)
+			}
+			if err := os.WriteFile(output, []byte(app.Redact(string(data))), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Wrote CloudFormation template for %s (%s) to %s\n", app.Name, env, output)
+			fmt.Printf("Resources: %d\n", len(tmpl.Resources))
+			return
+		}
+
+		if len(subnets) == 0 || len(securityGroups) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: --subnets and --security-groups are required to deploy (or use --dry-run)")
+			os.Exit(1)
+		}
+		if stackName == "" {
+			stackName = cloudformation.StackName(app.Name, env)
+		}
 
-		fmt.Printf("Deploying application: %s\n", appName)
+		dir, err := os.MkdirTemp("", "aws-hpc-deploy-")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+		// The stack is deployed from the template with secrets resolved;
+		// --output gets the redacted copy
+		templatePath := filepath.Join(dir, "template.json")
+		if err := os.WriteFile(templatePath, data, 0600); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if output != "" {
+			if err := os.WriteFile(output, []byte(app.Redact(string(data))), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		fmt.Printf("Deploying application: %s\n", app.Name)
 		fmt.Printf("Environment: %s\n", env)
 		fmt.Printf("Region: %s\n", region)
+		fmt.Printf("Stack: %s\n\n", stackName)
 
-		// TODO: Implement deployment
-		fmt.Println("\n[NOT IMPLEMENTED] Deployment functionality coming soon")
+		params := map[string]string{
+			cloudformation.ParamSubnets:          strings.Join(subnets, ","),
+			cloudformation.ParamSecurityGroupIDs: strings.Join(securityGroups, ","),
+			cloudformation.ParamEnvironment:      env,
+		}
+		stack := cloudformation.NewStack(stackName, region)
+		if err := stack.Deploy(context.Background(), templatePath, params, batch.Tags(app), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\n✓ Deployment complete")
+		saveDeploySnapshot(app, env, region)
 	},
 }
 
+// saveDeploySnapshot records what was deployed so the next deploy --plan
+// can diff against it. Failing to record it only warns.
+func saveDeploySnapshot(app *config.Application, env, region string) {
+	home, err := config.HomeDir()
+	if err == nil {
+		var snap *deploy.Snapshot
+		snap, err = deploy.NewSnapshot(app, env, region)
+		if err == nil {
+			snap.DeployedAt = time.Now().UTC()
+			err = snap.Save(deploy.SnapshotPath(home, app.Name, env, region))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record deploy snapshot: %v\n", err)
+	}
+}
+
+var appRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render infrastructure documents",
+	Long:  "Render the infrastructure an application needs, for review before deploying",
+}
+
+var appRenderBatchCmd = &cobra.Command{
+	Use:   "batch [name]",
+	Short: "Render AWS Batch documents",
+	Long: `Render AWS Batch compute environments, job queues and job definitions
+from the application's compute and container specs.
+
+The output is the JSON request payloads for the Batch APIs. Account-specific
+values not known from app.yaml, such as subnets and roles, are taken from
+flags and omitted when unset.
+
+Examples:
+  aws-hpc app render batch geos-chem
+  aws-hpc app render batch geos-chem --account 123456789012 --region us-west-2
+  aws-hpc app render batch geos-chem --output batch.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appName := args[0]
+		output, _ := cmd.Flags().GetString("output")
+
+		var opts batch.Options
+		opts.AccountID, _ = cmd.Flags().GetString("account")
+		opts.Region, _ = cmd.Flags().GetString("region")
+		opts.Subnets, _ = cmd.Flags().GetStringSlice("subnets")
+		opts.SecurityGroupIDs, _ = cmd.Flags().GetStringSlice("security-groups")
+		opts.InstanceRole, _ = cmd.Flags().GetString("instance-role")
+		opts.ServiceRole, _ = cmd.Flags().GetString("service-role")
+		opts.JobRoleArn, _ = cmd.Flags().GetString("job-role")
+
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+
+		docs, err := batch.Render(app, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		data, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		data = append([]byte(app.Redact(string(data))), '\n')
+
+		if output == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d compute environments, %d job queues and %d job definitions to %s\n",
+			len(docs.ComputeEnvironments), len(docs.JobQueues), len(docs.JobDefinitions), output)
+	},
+}
+
+// printPlan prints the changes a deploy would make, marking destructive ones
+func printPlan(app, env, region string, deployed *deploy.Snapshot, plan *deploy.Plan) {
+	fmt.Printf("Deploy plan for %s (%s) in %s\n", app, env, region)
+	if deployed == nil {
+		fmt.Println("No previous deploy recorded; everything will be added.")
+	} else {
+		fmt.Printf("Comparing with deploy of version %s at %s\n",
+			deployed.Version, deployed.DeployedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Println()
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("No changes. Infrastructure matches the last deploy.")
+		return
+	}
+
+	symbols := map[deploy.Action]string{deploy.Add: "+", deploy.Change: "~", deploy.Remove: "-"}
+	destructive := 0
+	for _, c := range plan.Changes {
+		marker := ""
+		if c.Destructive {
+			marker = "  [DESTRUCTIVE]"
+			destructive++
+		}
+		fmt.Printf("  %s %s %s%s\n", symbols[c.Action], c.Kind, c.Name, marker)
+		for _, d := range c.Details {
+			fmt.Printf("      %s\n", d)
+		}
+	}
+
+	fmt.Printf("\nPlan: %d to add, %d to change, %d to remove.\n",
+		plan.Count(deploy.Add), plan.Count(deploy.Change), plan.Count(deploy.Remove))
+	if destructive > 0 {
+		fmt.Printf("⚠ %d destructive change(s): running jobs or stored data may be affected.\n", destructive)
+	}
+}
+
+// checkApp runs the app validate checks on the application in dir: the
+// app.yaml with its overlays, and its base images against catalog unless
+// catalog is nil
+func checkApp(dir string, catalog *baseimage.Catalog) (*config.Application, *config.Report, error) {
+	overlays, err := appOverlays(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	app, report, err := config.CheckApplication(dir, overlays...)
+	if err != nil || catalog == nil {
+		return app, report, err
+	}
+	for _, issue := range catalog.Check(app) {
+		report.Add(archPath(app, issue.Architecture, issue.Field), issue.Severity, "%s", issue.Message)
+	}
+	report.Sort()
+	return app, report, nil
+}
+
+// archPath returns the YAML path of a field of the named architecture
+func archPath(app *config.Application, arch, field string) string {
+	for i, a := range app.Compute.Architectures {
+		if a.Name == arch {
+			return fmt.Sprintf("compute.architectures[%d].%s", i, field)
+		}
+	}
+	return "compute.architectures"
+}
+
+// count returns "1 noun" or "n nouns"
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// appDir returns the directory of an application by name, searching the
+// application search paths
+func appDir(name string) (string, error) {
+	return config.DefaultRegistry().Find(name)
+}
+
+// baseImagesDir returns the --base-images directory. By default it is the
+// base-images directory beside the applications directory holding
+// appPath, else the repository checkout's, so checks do not depend on the
+// working directory.
+func baseImagesDir(cmd *cobra.Command, appPath string) string {
+	if dir, _ := cmd.Flags().GetString("base-images"); dir != "" {
+		return dir
+	}
+	var candidates []string
+	if appPath != "" {
+		candidates = append(candidates, filepath.Join(filepath.Dir(filepath.Dir(appPath)), baseimage.DefaultDir))
+	}
+	if root := config.RepoRoot(); root != "" {
+		candidates = append(candidates, filepath.Join(root, baseimage.DefaultDir))
+	}
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return baseimage.DefaultDir
+}
+
+// appOverlays returns the overlays merged over the app.yaml in dir: the
+// site overlay, then the --overlay files. A site without an overlay is an
+// error for --site, but not for AWS_HPC_SITE, which applies to every
+// application.
+func appOverlays(dir string) ([]string, error) {
+	var overlays []string
+	site := Site
+	if site == "" {
+		site = os.Getenv(config.SiteEnv)
+	}
+	if site != "" {
+		file := config.SiteOverlay(dir, site)
+		if _, err := os.Stat(file); err == nil {
+			overlays = append(overlays, file)
+		} else if Site != "" {
+			return nil, fmt.Errorf("site %s has no overlay: %w", site, err)
+		}
+	}
+	return append(overlays, Overlays...), nil
+}
+
+// redactWriter writes output with an application's secrets redacted. Each
+// write must hold whole values, as fmt.Fprintf calls and the line-prefixed
+// output of builds do.
+type redactWriter struct {
+	app *config.Application
+	w   io.Writer
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, r.app.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// loadApp loads an application by name from the application search paths,
+// with its overlays merged
+func loadApp(name string) (*config.Application, error) {
+	dir, err := appDir(name)
+	if err != nil {
+		return nil, err
+	}
+	overlays, err := appOverlays(dir)
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadApplication(dir, overlays...)
+}
+
 func init() {
 	// app build flags
 	appBuildCmd.Flags().String("arch", "", "Target architecture (c7a, c7i, graviton4, etc.)")
 	appBuildCmd.Flags().Bool("all-arch", false, "Build for all supported architectures")
 	appBuildCmd.Flags().Bool("push", false, "Push to container registry after build")
 	appBuildCmd.Flags().Bool("no-push", false, "Do not push to registry")
+	appBuildCmd.Flags().StringSlice("variant", nil, "Only build these variants")
+	appBuildCmd.Flags().StringSlice("family", nil, "Only build architectures in these families (amd, intel, arm)")
+	appBuildCmd.Flags().Int("concurrency", 2, "Maximum number of builds to run at once")
+	appBuildCmd.Flags().String("registry", "", "Registry host to tag and push images for (default from app.yaml)")
+	appBuildCmd.Flags().String("account", "", "AWS account ID for the ECR registry (default from current credentials)")
+	appBuildCmd.Flags().String("region", "us-east-1", "AWS region for the ECR registry")
+
+	// app validate flags
+	appValidateCmd.Flags().String("base-images", "", "Directory of base image Dockerfiles to check base_image against (default: base-images beside the applications directory, or in the repository checkout)")
+	appListCmd.Flags().String("base-images", "", "Directory of base image Dockerfiles to check base_image against (default: base-images beside each applications directory, or in the repository checkout)")
+
+	// app schema flags
+	appSchemaCmd.Flags().StringP("output", "o", "", "Write the schema to a file instead of stdout")
+
+	// app migrate flags
+	appMigrateCmd.Flags().Bool("dry-run", false, "Print the migrated app.yaml instead of rewriting it")
+
+	// app images flags
+	appImagesCmd.Flags().String("registry", "", "Registry host images are tagged for (default local)")
+	appImagesCmd.Flags().String("account", "", "AWS account ID for the ECR registry")
+	appImagesCmd.Flags().String("region", "us-east-1", "AWS region for the ECR registry")
+
+	// app dockerfile flags
+	appDockerfileCmd.Flags().String("variant", "", "Application variant")
+	appDockerfileCmd.Flags().String("arch", "", "Target architecture")
+	appDockerfileCmd.Flags().StringP("output", "o", "", "Write the Dockerfile to a file instead of stdout")
+	appDockerfileCmd.MarkFlagRequired("variant")
+	appDockerfileCmd.MarkFlagRequired("arch")
 
 	// app deploy flags
 	appDeployCmd.Flags().String("env", "production", "Environment name")
 	appDeployCmd.Flags().String("region", "us-east-1", "AWS region")
+	appDeployCmd.Flags().Bool("dry-run", false, "Write the CloudFormation template to disk without deploying")
+	appDeployCmd.Flags().String("format", "cloudformation", "Output format: cloudformation, terraform")
+	appDeployCmd.Flags().Bool("plan", false, "Show changes from the last deploy without deploying")
+	appDeployCmd.Flags().StringP("output", "o", "", "Template file to write (default <app>-<env>.template.json or .tf.json)")
+	appDeployCmd.Flags().String("stack-name", "", "CloudFormation stack name (default aws-hpc-<app>-<env>)")
+	appDeployCmd.Flags().StringSlice("subnets", nil, "Subnet IDs for compute environments and shared storage, in different availability zones with EFS shared storage; --dry-run renders an EFS mount target for each")
+	appDeployCmd.Flags().StringSlice("security-groups", nil, "Security group IDs for compute environments and shared storage")
+
+	// app render batch flags
+	appRenderBatchCmd.Flags().String("account", "", "AWS account ID for ECR image URIs")
+	appRenderBatchCmd.Flags().String("region", "us-east-1", "AWS region")
+	appRenderBatchCmd.Flags().StringSlice("subnets", nil, "Subnet IDs for compute environments")
+	appRenderBatchCmd.Flags().StringSlice("security-groups", nil, "Security group IDs for compute environments")
+	appRenderBatchCmd.Flags().String("instance-role", "", "ECS instance profile for compute environments")
+	appRenderBatchCmd.Flags().String("service-role", "", "Batch service role ARN")
+	appRenderBatchCmd.Flags().String("job-role", "", "IAM role ARN assumed by job containers")
+	appRenderBatchCmd.Flags().StringP("output", "o", "", "Write the documents to a file instead of stdout")
+	appRenderCmd.AddCommand(appRenderBatchCmd)
 
 	// Add subcommands
 	appCmd.AddCommand(appValidateCmd)
+	appCmd.AddCommand(appSchemaCmd)
+	appCmd.AddCommand(appMigrateCmd)
+	appCmd.AddCommand(appRenderConfigCmd)
 	appCmd.AddCommand(appListCmd)
 	appCmd.AddCommand(appInfoCmd)
 	appCmd.AddCommand(appBuildCmd)
+	appCmd.AddCommand(appImagesCmd)
+	appCmd.AddCommand(appDockerfileCmd)
 	appCmd.AddCommand(appDeployCmd)
+	appCmd.AddCommand(appRenderCmd)
 }
@@ -15,9 +15,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"github.com/aws-hpc/platform/pkg/baseimage"
+	"github.com/aws-hpc/platform/pkg/command"
+	"github.com/aws-hpc/platform/pkg/container"
 )
 
 var baseCmd = &cobra.Command{
@@ -29,23 +37,29 @@ var baseCmd = &cobra.Command{
 var baseListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available base images",
+	Long: `List the base images defined under base-images/, from the labels and
+build args of their Dockerfiles. The BUILT column is the date label of the
+image in the local image store, if it has been built.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Available base images:")
-		fmt.Println("\nAMD:")
-		fmt.Println("  hpc-base-amd-zen4:20251018    - AMD EPYC Genoa (Zen 4)")
-		fmt.Println("  hpc-base-amd-zen3:20251018    - AMD EPYC Milan (Zen 3)")
-		fmt.Println("  hpc-base-amd-zen2:20251018    - AMD EPYC Rome (Zen 2)")
-		fmt.Println("\nIntel:")
-		fmt.Println("  hpc-base-intel-spr:20251018   - Intel Sapphire Rapids")
-		fmt.Println("  hpc-base-intel-icl:20251018   - Intel Ice Lake")
-		fmt.Println("  hpc-base-intel-clk:20251018   - Intel Cascade Lake")
-		fmt.Println("\nARM:")
-		fmt.Println("  hpc-base-arm-graviton4:20251018 - AWS Graviton 4 (Neoverse V2)")
-		fmt.Println("  hpc-base-arm-graviton3:20251018 - AWS Graviton 3 (Neoverse V1)")
-		fmt.Println("  hpc-base-arm-graviton2:20251018 - AWS Graviton 2 (Neoverse N1)")
-
-		// TODO: Query actual images from registry
-		fmt.Println("\n[NOT IMPLEMENTED] Registry query functionality coming soon")
+		catalog, err := baseimage.LoadCatalog(baseimage.DefaultDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		fmt.Printf("Available base images:\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "IMAGE\tTARGET\tARCH\tDATE\tMATH LIBRARY\tBUILT\tDESCRIPTION")
+		for _, img := range catalog.Images {
+			built := "-"
+			if b, err := baseimage.Inspect(ctx, command.Exec{}, img.Name()+":latest"); err == nil {
+				built = orDash(b.Labels["org.hpc.base.date"])
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", img.Name(), img.Target(), orDash(img.Architecture()),
+				orDash(img.Date()), orDash(img.MathLibrary), built, img.Description)
+		}
+		w.Flush()
 	},
 }
 
@@ -54,6 +68,14 @@ var baseBuildCmd = &cobra.Command{
 	Short: "Build a base image",
 	Long: `Build a base container image with optimized compilers and libraries.
 
+Base images are defined by base-images/<family>/Dockerfile.<generation> and
+built with docker buildx for the family's platform. SPACK_VERSION,
+AOCL_VERSION, OPENMPI_VERSION and GCC_VERSION are passed from the
+Dockerfile's ARG defaults unless overridden with --build-arg.
+
+Images are tagged hpc-base-<family>-<generation>:<date>, where the date is
+the build date unless set with --date, and hpc-base-<family>-<generation>:latest.
+
 Examples:
   # Build specific architecture
   aws-hpc base build amd/zen4
@@ -62,54 +84,155 @@ Examples:
   aws-hpc base build amd/all
 
   # Build all base images
-  aws-hpc base build all`,
+  aws-hpc base build all
+
+  # Build with a newer OpenMPI and push to ECR
+  aws-hpc base build amd/zen4 --build-arg OPENMPI_VERSION=5.0.5 --push`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		target := args[0]
 		push, _ := cmd.Flags().GetBool("push")
+		registry, _ := cmd.Flags().GetString("registry")
+		account, _ := cmd.Flags().GetString("account")
+		region, _ := cmd.Flags().GetString("region")
+		date, _ := cmd.Flags().GetString("date")
+		buildArgs, _ := cmd.Flags().GetStringToString("build-arg")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		images, err := baseimage.Discover(baseimage.DefaultDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		selected, err := baseimage.Select(images, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
-		fmt.Printf("Building base image: %s\n", target)
+		ctx := context.Background()
+		if registry == "" && push {
+			if account == "" {
+				account, err = container.AccountID(ctx, command.Exec{})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			registry = container.Registry("ecr", account, region)
+		}
+
+		opts := baseimage.BuildOptions{Registry: registry, Date: date, Push: push, BuildArgs: buildArgs}
+		var builds []*container.Build
+		for _, img := range selected {
+			builds = append(builds, img.Build(opts))
+		}
+
+		fmt.Printf("Building base image: %s (%d images)\n", target, len(builds))
 		if push {
-			fmt.Println("Will push to registry after build")
+			fmt.Printf("Will push to %s after build\n", registry)
 		}
+		for _, build := range builds {
+			fmt.Printf("  %s (%s)\n", build.Tag, build.Platform)
+			if Verbose {
+				fmt.Printf("    docker %s\n", strings.Join(build.Args(), " "))
+			}
+		}
+		fmt.Println()
+
+		builder := container.NewBuilder(".", registry, push)
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		results := builder.RunMatrix(ctx, builds, concurrency, os.Stdout)
+		stop()
 
-		// TODO: Implement base image building
-		fmt.Println("\n[NOT IMPLEMENTED] Base image build functionality coming soon")
+		failed := printBuildSummary(results, [2]string{"FAMILY", "GENERATION"})
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "\nError: %d of %d builds failed\n", failed, len(results))
+			os.Exit(1)
+		}
+		fmt.Printf("\n✓ Built %d base images\n", len(results))
 	},
 }
 
 var baseInfoCmd = &cobra.Command{
 	Use:   "info [image]",
 	Short: "Show base image information",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show a base image's architecture, libraries and compiler flags, from its
+Dockerfile. The image is named by target (amd/zen4) or reference
+(hpc-base-amd-zen4:latest). If the image is in the local image store, its
+labels are compared with the Dockerfile's.
+
+Examples:
+  aws-hpc base info amd/zen4
+  aws-hpc base info hpc-base-amd-zen4:20251018`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		image := args[0]
+		ref := args[0]
+
+		catalog, err := baseimage.LoadCatalog(baseimage.DefaultDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		img, err := catalog.Lookup(ref)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Base Image: %s (%s)\n", img.Name(), img.Target())
+		if img.Description != "" {
+			fmt.Printf("Description:  %s\n", img.Description)
+		}
+		fmt.Printf("Dockerfile:   %s\n", img.Dockerfile)
+		fmt.Printf("Architecture: %s\n", orDash(img.Architecture()))
+		fmt.Printf("Date:         %s\n", orDash(img.Date()))
+		fmt.Printf("Math library: %s\n", orDash(img.MathLibrary))
+		if v := img.Labels["org.hpc.platform.version"]; v != "" {
+			fmt.Printf("Platform:     %s\n", v)
+		}
 
-		fmt.Printf("Base Image: %s\n", image)
 		fmt.Println("\nIncluded libraries:")
-		fmt.Println("  - GCC 11.5.0")
-		fmt.Println("  - Spack v0.23.1")
-		fmt.Println("  - AMD AOCL 4.2 (BLIS + libFLAME)")
-		fmt.Println("  - OpenMPI 4.1.6")
-		fmt.Println("  - HDF5 1.14.3")
-		fmt.Println("  - NetCDF-C 4.9.2")
-		fmt.Println("  - NetCDF-Fortran 4.6.1")
+		for _, lib := range img.Libraries {
+			fmt.Printf("  - %s %s\n", lib.Name, lib.Version)
+		}
 
 		fmt.Println("\nCompiler flags:")
-		fmt.Println("  CFLAGS:   -march=znver4 -mavx512f -O3")
-		fmt.Println("  FCFLAGS:  -march=znver4 -mavx512f -O3 -fopenmp")
-
-		fmt.Println("\nTarget instances:")
-		fmt.Println("  - c7a.xlarge, c7a.2xlarge, c7a.4xlarge, c7a.8xlarge")
+		for _, name := range baseimage.CompilerFlagVars {
+			if flags, ok := img.Env[name]; ok {
+				fmt.Printf("  %-9s %s\n", name+":", flags)
+			}
+		}
 
-		// TODO: Query actual image metadata
-		fmt.Println("\n[NOT IMPLEMENTED] Image metadata query coming soon")
+		builtRef := img.Name() + ":latest"
+		if strings.HasPrefix(ref, "hpc-base-") && strings.Contains(ref, ":") {
+			builtRef = ref
+		}
+		built, err := baseimage.Inspect(context.Background(), command.Exec{}, builtRef)
+		if err != nil {
+			fmt.Printf("\nBuilt image: %s is not in the local image store\n", builtRef)
+			return
+		}
+		id := strings.TrimPrefix(built.ID, "sha256:")
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		fmt.Printf("\nBuilt image: %s (%s, created %s)\n", builtRef, id, built.Created)
+		if date := built.Labels["org.hpc.base.date"]; date != img.Date() {
+			fmt.Printf("  ⚠ built from a Dockerfile dated %s; rebuild with: aws-hpc base build %s\n", orDash(date), img.Target())
+		}
 	},
 }
 
 func init() {
 	// base build flags
 	baseBuildCmd.Flags().Bool("push", false, "Push to registry after build")
+	baseBuildCmd.Flags().String("registry", "", "Registry host to tag and push images for (default ECR)")
+	baseBuildCmd.Flags().String("account", "", "AWS account ID for the ECR registry (default from current credentials)")
+	baseBuildCmd.Flags().String("region", "us-east-1", "AWS region for the ECR registry")
+	baseBuildCmd.Flags().String("date", "", "Image tag (default the build date, YYYYMMDD)")
+	baseBuildCmd.Flags().StringToString("build-arg", nil, "Override a Dockerfile build arg (NAME=VALUE)")
+	baseBuildCmd.Flags().Int("concurrency", 1, "Maximum number of builds to run at once")
 
 	// Add subcommands
 	baseCmd.AddCommand(baseListCmd)
@@ -15,9 +15,22 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/aws-hpc/platform/pkg/command"
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/cost"
+	"github.com/aws-hpc/platform/pkg/job"
+	"github.com/aws-hpc/platform/pkg/pricing"
 )
 
 var costCmd = &cobra.Command{
@@ -31,6 +44,14 @@ var costEstimateCmd = &cobra.Command{
 	Short: "Estimate job cost",
 	Long: `Estimate the cost of running a job for the specified application.
 
+The estimate scales the baseline run in the application's cost section:
+the runtime by the architecture's scaling factor and inversely with vCPUs.
+The job runs on the smallest instance type of the architecture with at
+least --vcpus vCPUs, priced from the pricing catalog (see cost pricing
+update) in --region. Instance types the catalog does not price are priced
+at the baseline's cost per vCPU-hour. Scratch EBS volumes are charged for
+the runtime, and data moved to and from S3 storage per GB.
+
 Examples:
   # Estimate for specific configuration
   aws-hpc cost estimate geos-chem \
@@ -38,48 +59,134 @@ Examples:
     --vcpus 16 \
     --runtime 4h
 
-  # Compare across architectures
+  # Compare across architectures at spot prices
   aws-hpc cost estimate geos-chem \
     --compare \
-    --runtime 4h`,
+    --spot \
+    --output-gb 50`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
 		arch, _ := cmd.Flags().GetString("arch")
 		vcpus, _ := cmd.Flags().GetInt("vcpus")
-		runtime, _ := cmd.Flags().GetString("runtime")
+		runtime, _ := cmd.Flags().GetDuration("runtime")
 		compare, _ := cmd.Flags().GetBool("compare")
+		inputGB, _ := cmd.Flags().GetFloat64("input-gb")
+		outputGB, _ := cmd.Flags().GetFloat64("output-gb")
+		region, _ := cmd.Flags().GetString("region")
+		spot, _ := cmd.Flags().GetBool("spot")
+
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		calc, err := newCalculator(app, region, spot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		req := cost.Request{
+			Architecture: arch,
+			VCPUs:        vcpus,
+			RuntimeHours: runtime.Hours(),
+			InputGB:      inputGB,
+			OutputGB:     outputGB,
+		}
 
-		fmt.Printf("Cost estimate for application: %s\n", appName)
+		fmt.Printf("Cost estimate for application: %s\n", app.Name)
 
 		if compare {
+			estimates, errs := calc.Compare(req)
+			if len(estimates) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", errors.Join(errs...))
+				os.Exit(1)
+			}
+			sort.SliceStable(estimates, func(i, j int) bool { return estimates[i].Total() < estimates[j].Total() })
+
 			fmt.Println("\nCost comparison across architectures:")
-			fmt.Println("Architecture    | Instance     | Runtime | Cost   | Cost/Hour")
-			fmt.Println("----------------|--------------|---------|--------|----------")
-			fmt.Println("c7a (AMD Zen4) | c7a.4xlarge  | 4.0h    | $1.39  | $0.3468")
-			fmt.Println("c7i (Intel SPR)| c7i.4xlarge  | 4.5h    | $1.22  | $0.2720")
-			fmt.Println("graviton4      | c8g.4xlarge  | 4.8h    | $1.00  | $0.2076")
-			fmt.Println("\nRecommendation: graviton4 (lowest total cost)")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ARCH\tINSTANCE\tRUNTIME\tCOST/HOUR\tCOST")
+			for _, e := range estimates {
+				fmt.Fprintf(w, "%s\t%s\t%.1fh\t$%.4f\t$%.2f\n",
+					e.Architecture, e.InstanceType, e.RuntimeHours, e.HourlyPrice, e.Total())
+			}
+			w.Flush()
+			for _, err := range errs {
+				fmt.Printf("Skipped: %v\n", err)
+			}
+			fmt.Printf("\n%s\n", pricingNote(calc))
+			fmt.Printf("\nRecommendation: %s (lowest total cost)\n", estimates[0].Architecture)
+			return
+		}
+
+		e, err := calc.Estimate(req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nArchitecture: %s\n", e.Architecture)
+		fmt.Printf("Instance: %s (%d vCPUs, $%.4f/hour %s)\n", e.InstanceType, e.VCPUs, e.HourlyPrice, e.PriceSource)
+		if runtime > 0 {
+			fmt.Printf("Runtime: %.1fh\n", e.RuntimeHours)
 		} else {
-			fmt.Printf("\nArchitecture: %s\n", arch)
-			fmt.Printf("vCPUs: %d\n", vcpus)
-			fmt.Printf("Runtime: %s\n", runtime)
-			fmt.Println("\nEstimated cost: $1.39")
-			fmt.Println("Cost breakdown:")
-			fmt.Println("  Compute: $1.32")
-			fmt.Println("  Storage: $0.05")
-			fmt.Println("  Network: $0.02")
-		}
-
-		// TODO: Implement actual cost estimation
-		fmt.Println("\n[NOT IMPLEMENTED] Detailed cost estimation coming soon")
+			fmt.Printf("Runtime: %.1fh (baseline %s, scaling factor %.2f)\n",
+				e.RuntimeHours, app.Cost.Baseline.Architecture, e.ScalingFactor)
+		}
+		fmt.Printf("\nEstimated cost: $%.2f\n", e.Total())
+		fmt.Println("Cost breakdown:")
+		fmt.Printf("  Compute:  $%.2f\n", e.Compute)
+		fmt.Printf("  Scratch:  $%.2f\n", e.Scratch)
+		fmt.Printf("  Transfer: $%.2f\n", e.Transfer)
+		fmt.Printf("\n%s\n", pricingNote(calc))
 	},
 }
 
+// newCalculator creates a cost calculator for an application that prices
+// instances in a region from the pricing catalog, by default in the region
+// of AWS_REGION or AWS_DEFAULT_REGION, else us-east-1
+func newCalculator(app *config.Application, region string, spot bool) (*cost.Calculator, error) {
+	catalog, err := pricing.Load()
+	if err != nil {
+		return nil, err
+	}
+	calc := cost.NewCalculator(app)
+	calc.Catalog = catalog
+	calc.Region = region
+	if calc.Region == "" {
+		calc.Region = pricing.Region()
+	}
+	calc.Spot = spot
+	if _, ok := catalog.Regions[calc.Region]; !ok {
+		fmt.Fprintf(os.Stderr, "Warning: the pricing catalog has no prices for %s; run aws-hpc cost pricing update --region %s\n",
+			calc.Region, calc.Region)
+		calc.Catalog = nil
+	}
+	return calc, nil
+}
+
+// pricingNote says where a calculator's prices come from
+func pricingNote(calc *cost.Calculator) string {
+	if calc.Catalog == nil {
+		return "Prices scaled from the cost baseline"
+	}
+	kind := "On-demand"
+	if calc.Spot {
+		kind = "Spot"
+	}
+	return fmt.Sprintf("%s prices in %s from the pricing catalog (%s, %s)",
+		kind, calc.Region, calc.Catalog.Source, calc.Catalog.Updated.Format("2006-01-02"))
+}
+
 var costAnalyzeCmd = &cobra.Command{
 	Use:   "analyze",
 	Short: "Analyze historical costs",
-	Long: `Analyze historical costs for completed jobs.
+	Long: `Analyze historical costs for jobs recorded in the local job database.
+
+Compute cost prices each job's runtime on the smallest instance of its
+architecture with the job's vCPUs, as cost estimate does: from the pricing
+catalog in the region the job ran in, at spot prices for jobs on spot
+queues, or else scaled from the application's cost baseline.
 
 Examples:
   # Analyze costs for last 30 days
@@ -89,54 +196,389 @@ Examples:
   aws-hpc cost analyze --app geos-chem --days 90`,
 	Run: func(cmd *cobra.Command, args []string) {
 		days, _ := cmd.Flags().GetInt("days")
-		app, _ := cmd.Flags().GetString("app")
+		appName, _ := cmd.Flags().GetString("app")
+
+		store, err := openJobStore()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		records, err := store.List(job.Filter{
+			App:   appName,
+			Since: time.Now().AddDate(0, 0, -days),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
 		fmt.Printf("Cost analysis for last %d days\n", days)
-		if app != "" {
-			fmt.Printf("Application: %s\n", app)
+		if appName != "" {
+			fmt.Printf("Application: %s\n", appName)
 		}
 
-		fmt.Println("\nTotal costs: $1,234.56")
-		fmt.Println("\nBreakdown by resource:")
-		fmt.Println("  Compute: $1,000.00 (81%)")
-		fmt.Println("  Storage: $200.00 (16%)")
-		fmt.Println("  Network: $34.56 (3%)")
+		pricer := newUsagePricer()
+		usage := summarizeUsage(records, pricer)
+		if len(usage) == 0 {
+			fmt.Println("\nNo jobs with recorded runtimes")
+			return
+		}
 
-		fmt.Println("\nTop instances by cost:")
-		fmt.Println("  c7a.4xlarge: $500.00")
-		fmt.Println("  c8g.4xlarge: $300.00")
-		fmt.Println("  c7i.4xlarge: $200.00")
+		var total jobUsage
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nAPP\tARCH\tJOBS\tFAILED\tHOURS\tVCPU-HOURS\tEST. COST")
+		for _, u := range usage {
+			est := "-"
+			if u.cost > 0 {
+				est = fmt.Sprintf("$%.2f", u.cost)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.1f\t%.1f\t%s\n",
+				u.app, u.arch, u.jobs, u.failed, u.hours, u.vcpuHours, est)
+			total.jobs += u.jobs
+			total.failed += u.failed
+			total.hours += u.hours
+			total.vcpuHours += u.vcpuHours
+			total.cost += u.cost
+		}
+		w.Flush()
 
-		// TODO: Implement actual cost analysis
-		fmt.Println("\n[NOT IMPLEMENTED] Detailed cost analysis coming soon")
+		fmt.Printf("\nTotal jobs: %d (%d failed)\n", total.jobs, total.failed)
+		fmt.Printf("Total runtime: %.1f hours (%.1f vCPU-hours)\n", total.hours, total.vcpuHours)
+		fmt.Printf("Estimated compute cost: $%.2f\n", total.cost)
+		if notes := pricer.notes(); len(notes) > 0 {
+			fmt.Printf("\n%s\n", strings.Join(notes, "\n"))
+		}
 	},
 }
 
+// jobUsage aggregates recorded jobs for one application and architecture
+type jobUsage struct {
+	app, arch string
+	jobs      int
+	failed    int
+	hours     float64
+	vcpuHours float64
+	cost      float64
+}
+
+// summarizeUsage groups jobs that have started by application and
+// architecture, costing each job with pricer
+func summarizeUsage(records []*job.Record, pricer *usagePricer) []*jobUsage {
+	byKey := make(map[string]*jobUsage)
+
+	for _, r := range records {
+		if r.Job.StartedAt == nil {
+			continue
+		}
+		key := r.Spec.App + "/" + r.Spec.Architecture
+		u, ok := byKey[key]
+		if !ok {
+			u = &jobUsage{app: r.Spec.App, arch: r.Spec.Architecture}
+			byKey[key] = u
+		}
+
+		hours := r.Job.Runtime().Hours()
+		u.jobs++
+		if r.Job.Status == job.StatusFailed {
+			u.failed++
+		}
+		u.hours += hours
+		u.vcpuHours += hours * float64(r.Spec.VCPUs)
+		u.cost += pricer.cost(r, hours)
+	}
+
+	usage := make([]*jobUsage, 0, len(byKey))
+	for _, u := range byKey {
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, k int) bool {
+		if usage[i].app != usage[k].app {
+			return usage[i].app < usage[k].app
+		}
+		return usage[i].arch < usage[k].arch
+	})
+	return usage
+}
+
+// usagePricer prices recorded jobs with a cost calculator per application,
+// region and spot pricing
+type usagePricer struct {
+	apps  map[string]*config.Application
+	calcs map[string]*cost.Calculator
+}
+
+func newUsagePricer() *usagePricer {
+	return &usagePricer{
+		apps:  make(map[string]*config.Application),
+		calcs: make(map[string]*cost.Calculator),
+	}
+}
+
+// cost returns the compute cost of a job that ran for hours, or zero if the
+// application or its cost baseline cannot be loaded
+func (p *usagePricer) cost(r *job.Record, hours float64) float64 {
+	calc := p.calculator(r)
+	if calc == nil || hours <= 0 {
+		return 0
+	}
+	e, err := calc.Estimate(cost.Request{
+		Architecture: r.Spec.Architecture,
+		VCPUs:        r.Spec.VCPUs,
+		RuntimeHours: hours,
+	})
+	if err != nil {
+		return 0
+	}
+	return e.Compute
+}
+
+// calculator returns the calculator for a job's application, the region it
+// ran in and whether its queue runs on spot instances
+func (p *usagePricer) calculator(r *job.Record) *cost.Calculator {
+	app, ok := p.apps[r.Spec.App]
+	if !ok {
+		app, _ = loadApp(r.Spec.App)
+		p.apps[r.Spec.App] = app
+	}
+	if app == nil {
+		return nil
+	}
+	spot := spotQueue(app, &r.Spec)
+	key := fmt.Sprintf("%s/%s/%t", r.Spec.App, r.Region, spot)
+	if calc, ok := p.calcs[key]; ok {
+		return calc
+	}
+	calc, err := newCalculator(app, r.Region, spot)
+	if err != nil {
+		calc = nil
+	}
+	p.calcs[key] = calc
+	return calc
+}
+
+// notes says where the prices of the jobs came from
+func (p *usagePricer) notes() []string {
+	seen := make(map[string]bool)
+	var notes []string
+	for _, calc := range p.calcs {
+		if calc == nil {
+			continue
+		}
+		if note := pricingNote(calc); !seen[note] {
+			seen[note] = true
+			notes = append(notes, note)
+		}
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// spotQueue reports whether a job's queue first runs it on spot instances.
+// Queues of a deployed environment carry the environment's suffix.
+func spotQueue(app *config.Application, spec *job.Spec) bool {
+	for _, q := range app.Compute.Batch.Queues {
+		if q.Name != spec.Queue && job.DeployedName(q.Name, spec.Environment) != spec.Queue {
+			continue
+		}
+		for _, ce := range q.ComputeEnvironments {
+			for _, arch := range ce.Architectures {
+				if arch == spec.Architecture {
+					return ce.Type == "spot"
+				}
+			}
+		}
+	}
+	return false
+}
+
 var costOptimizeCmd = &cobra.Command{
 	Use:   "optimize [app]",
 	Short: "Get cost optimization recommendations",
-	Args:  cobra.ExactArgs(1),
+	Long: `Recommend ways to lower the cost of an application's jobs, priced with
+the same estimates as cost estimate: the cheapest architecture, spot
+instances and S3 lifecycle policies. Savings add up, each on top of the
+ones before it.
+
+Examples:
+  # Recommendations for the cost baseline's job
+  aws-hpc cost optimize geos-chem
+
+  # For 16 vCPU jobs, with monthly savings for 200 jobs a month
+  aws-hpc cost optimize geos-chem --vcpus 16 --jobs-per-month 200`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
+		arch, _ := cmd.Flags().GetString("arch")
+		vcpus, _ := cmd.Flags().GetInt("vcpus")
+		jobs, _ := cmd.Flags().GetInt("jobs-per-month")
+		region, _ := cmd.Flags().GetString("region")
 
-		fmt.Printf("Cost optimization recommendations for: %s\n", appName)
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		calc, err := newCalculator(app, region, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		recs, err := calc.Recommend(cost.Request{Architecture: arch, VCPUs: vcpus})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Cost optimization recommendations for: %s\n", app.Name)
+		if len(recs) == 0 {
+			fmt.Println("\nNo recommendations: jobs already run at the lowest estimated cost")
+			return
+		}
+
+		var savings float64
 		fmt.Println("\nRecommendations:")
-		fmt.Println("  1. Switch to Graviton instances (30% cost savings)")
-		fmt.Println("  2. Use Spot instances for non-urgent jobs (70% savings)")
-		fmt.Println("  3. Enable S3 lifecycle policies (15% storage savings)")
-		fmt.Println("\nEstimated monthly savings: $425.00")
+		for i, r := range recs {
+			fmt.Printf("  %d. %s\n", i+1, r.Title)
+			fmt.Printf("     %s\n", r.Detail)
+			savings += r.Savings
+		}
+		if savings > 0 {
+			fmt.Printf("\nEstimated savings: $%.2f per job", savings)
+			if jobs > 0 {
+				fmt.Printf(", $%.2f per month", savings*float64(jobs))
+			}
+			fmt.Println()
+		}
+		fmt.Printf("\n%s\n", pricingNote(calc))
+	},
+}
+
+var costPricingCmd = &cobra.Command{
+	Use:   "pricing",
+	Short: "Manage the instance pricing catalog",
+	Long: `Manage the catalog of instance types and their on-demand and spot
+prices that cost estimates use. A catalog is bundled with the platform;
+cost pricing update writes a current one to ~/.aws-hpc/pricing.json, which
+is used instead.`,
+}
+
+var costPricingUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Refresh the pricing catalog from the AWS Price List",
+	Long: `Refresh the pricing catalog: on-demand prices and instance metadata
+from the AWS Price List bulk file of each region, and current spot prices
+from the spot price history through the aws CLI.
+
+The catalog covers the instance types of every application found (see app
+list) and those already in the catalog. Regions default to those already
+in the catalog. Each region's bulk file is several hundred megabytes;
+--file reads a downloaded one instead, for a single region.
 
-		// TODO: Implement optimization recommendations
-		fmt.Println("\n[NOT IMPLEMENTED] Detailed recommendations coming soon")
+Examples:
+  # Refresh every region in the catalog
+  aws-hpc cost pricing update
+
+  # Add a region
+  aws-hpc cost pricing update --region eu-west-1
+
+  # Use a downloaded bulk file, without spot prices
+  aws-hpc cost pricing update --region us-east-1 --file index.json --no-spot`,
+	Run: func(cmd *cobra.Command, args []string) {
+		regions, _ := cmd.Flags().GetStringSlice("region")
+		file, _ := cmd.Flags().GetString("file")
+		noSpot, _ := cmd.Flags().GetBool("no-spot")
+
+		old, err := pricing.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := pricing.CachePath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(regions) == 0 {
+			regions = old.RegionNames()
+		}
+		if file != "" && len(regions) != 1 {
+			fmt.Fprintf(os.Stderr, "Error: --file is the bulk file of one region; name it with --region\n")
+			os.Exit(1)
+		}
+
+		seen := map[string]bool{}
+		var types []string
+		addType := func(t string) {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+		for _, t := range old.InstanceTypes() {
+			addType(t)
+		}
+		for _, entry := range config.DefaultRegistry().Discover() {
+			if entry.App == nil {
+				continue
+			}
+			for _, arch := range entry.App.Compute.Architectures {
+				for _, t := range arch.InstanceTypes {
+					addType(t)
+				}
+			}
+		}
+
+		u := &pricing.Updater{
+			Source: pricing.HTTPSource,
+			Runner: command.Exec{},
+			Warn: func(err error) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			},
+		}
+		if file != "" {
+			u.Source = func(ctx context.Context, region string) (io.ReadCloser, error) {
+				return os.Open(file)
+			}
+		}
+		if noSpot {
+			u.Runner = nil
+		}
+
+		fmt.Printf("Updating %d instance types in %s\n", len(types), strings.Join(regions, ", "))
+		catalog, err := u.Update(cmd.Context(), old, regions, types)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := catalog.Save(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s\n", path)
 	},
 }
 
 func init() {
 	// cost estimate flags
-	costEstimateCmd.Flags().String("arch", "c7a", "Target architecture")
-	costEstimateCmd.Flags().Int("vcpus", 8, "Number of vCPUs")
-	costEstimateCmd.Flags().String("runtime", "1h", "Estimated runtime (e.g., 2h, 30m)")
+	costEstimateCmd.Flags().String("arch", "", "Target architecture (default: the cost baseline's)")
+	costEstimateCmd.Flags().Int("vcpus", 0, "Number of vCPUs (default: the cost baseline's)")
+	costEstimateCmd.Flags().Duration("runtime", 0, "Expected runtime, e.g. 2h or 30m (default: scaled from the cost baseline)")
 	costEstimateCmd.Flags().Bool("compare", false, "Compare costs across architectures")
+	costEstimateCmd.Flags().Float64("input-gb", 0, "Input data read from S3, in GB")
+	costEstimateCmd.Flags().Float64("output-gb", 0, "Output data written to S3, in GB")
+	costEstimateCmd.Flags().String("region", "", "Region to price instances in (default: $AWS_REGION or us-east-1)")
+	costEstimateCmd.Flags().Bool("spot", false, "Use spot prices")
+
+	// cost optimize flags
+	costOptimizeCmd.Flags().String("arch", "", "Current architecture (default: the cost baseline's)")
+	costOptimizeCmd.Flags().Int("vcpus", 0, "Number of vCPUs (default: the cost baseline's)")
+	costOptimizeCmd.Flags().String("region", "", "Region to price instances in (default: $AWS_REGION or us-east-1)")
+	costOptimizeCmd.Flags().Int("jobs-per-month", 0, "Jobs run each month, to estimate monthly savings")
+
+	// cost pricing update flags
+	costPricingUpdateCmd.Flags().StringSlice("region", nil, "Region to update (repeatable; default: the catalog's regions)")
+	costPricingUpdateCmd.Flags().String("file", "", "Downloaded Price List bulk file to read instead of fetching it")
+	costPricingUpdateCmd.Flags().Bool("no-spot", false, "Keep the catalog's spot prices instead of looking them up")
 
 	// cost analyze flags
 	costAnalyzeCmd.Flags().Int("days", 30, "Number of days to analyze")
@@ -146,4 +588,6 @@ func init() {
 	costCmd.AddCommand(costEstimateCmd)
 	costCmd.AddCommand(costAnalyzeCmd)
 	costCmd.AddCommand(costOptimizeCmd)
+	costCmd.AddCommand(costPricingCmd)
+	costPricingCmd.AddCommand(costPricingUpdateCmd)
 }
@@ -15,9 +15,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/job"
 )
 
 var jobCmd = &cobra.Command{
@@ -29,45 +39,134 @@ var jobCmd = &cobra.Command{
 var jobSubmitCmd = &cobra.Command{
 	Use:   "submit [app]",
 	Short: "Submit a job",
-	Long: `Submit a job to AWS Batch for the specified application.
+	Long: `Submit a job to AWS Batch, or run it locally with Docker or Podman.
+
+Settings are layered: the application defaults, then the environment
+file named by --env, then flags, each overriding only what it sets.
 
 Examples:
-  # Submit with S3 input/output
+  # Submit with the benchmark environment's settings
+  aws-hpc job submit geos-chem --env benchmark
+
+  # Override the environment's output and a simulation parameter
   aws-hpc job submit geos-chem \
     --env benchmark \
-    --input s3://bucket/input/ \
-    --output s3://bucket/output/
+    --output s3://bucket/output/ \
+    --param end_date=20190702
 
   # Submit with custom architecture
   aws-hpc job submit geos-chem \
+    --env production \
     --arch graviton4 \
     --vcpus 16 \
     --memory 32768 \
     --input s3://bucket/input/ \
-    --output s3://bucket/output/`,
+    --output s3://bucket/output/
+
+  # Run on this workstation with local directories
+  aws-hpc job submit geos-chem \
+    --backend local \
+    --vcpus 4 \
+    --input ./input \
+    --output ./output`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
-		env, _ := cmd.Flags().GetString("env")
+		envName, _ := cmd.Flags().GetString("env")
+		variant, _ := cmd.Flags().GetString("variant")
 		arch, _ := cmd.Flags().GetString("arch")
+		queue, _ := cmd.Flags().GetString("queue")
 		input, _ := cmd.Flags().GetString("input")
 		output, _ := cmd.Flags().GetString("output")
 		vcpus, _ := cmd.Flags().GetInt("vcpus")
 		memory, _ := cmd.Flags().GetInt("memory")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		retries, _ := cmd.Flags().GetInt("retries")
+		params, _ := cmd.Flags().GetStringToString("param")
+		image, _ := cmd.Flags().GetString("image")
+		s3Endpoint, _ := cmd.Flags().GetString("s3-endpoint")
+
+		app, err := loadApp(appName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading application: %v\n", err)
+			os.Exit(1)
+		}
+		// Each environment's deployment names its own queues and job
+		// definitions, so Batch jobs must say which one they run on
+		if backendName, _ := cmd.Flags().GetString("backend"); backendName == "batch" && envName == "" {
+			fmt.Fprintf(os.Stderr, "Error: --env is required with the batch backend to select the deployed queues and job definitions\n")
+			os.Exit(1)
+		}
+		var env *config.Environment
+		if envName != "" {
+			if env, err = app.GetEnvironment(envName); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Flags override only the environment settings they name
+		spec, err := job.Resolve(app, env, job.Options{
+			Variant:       variant,
+			Architecture:  arch,
+			Queue:         queue,
+			VCPUs:         vcpus,
+			MemoryMB:      memory,
+			Input:         input,
+			Output:        output,
+			Timeout:       timeout,
+			RetryAttempts: retries,
+			Parameters:    params,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if spec.Input == "" || spec.Output == "" {
+			fmt.Fprintf(os.Stderr, "Error: --input and --output are required unless the environment sets storage.input and storage.output\n")
+			os.Exit(1)
+		}
+		if image != "" {
+			spec.Image = image
+		}
+		if s3Endpoint != "" {
+			spec.Env = map[string]string{"AWS_ENDPOINT_URL_S3": s3Endpoint}
+		}
+
+		backend := mustJobBackend(cmd, "")
 
 		fmt.Printf("Submitting job for application: %s\n", appName)
-		fmt.Printf("Environment: %s\n", env)
-		if arch != "" {
-			fmt.Printf("Architecture: %s\n", arch)
-		}
-		fmt.Printf("vCPUs: %d\n", vcpus)
-		fmt.Printf("Memory: %d MB\n", memory)
-		fmt.Printf("Input: %s\n", input)
-		fmt.Printf("Output: %s\n", output)
-
-		// TODO: Implement job submission
-		fmt.Println("\n[NOT IMPLEMENTED] Job submission functionality coming soon")
-		fmt.Println("Job ID: job-12345678-abcd-1234-5678-abcdef123456")
+		if spec.Environment != "" {
+			fmt.Printf("Environment: %s\n", spec.Environment)
+		}
+		fmt.Printf("Variant: %s\n", spec.Variant)
+		fmt.Printf("Architecture: %s\n", spec.Architecture)
+		fmt.Printf("vCPUs: %d\n", spec.VCPUs)
+		fmt.Printf("Memory: %d MB\n", spec.MemoryMB)
+		fmt.Printf("Input: %s\n", spec.Input)
+		fmt.Printf("Output: %s\n", spec.Output)
+		if spec.TimeoutSeconds > 0 {
+			fmt.Printf("Timeout: %s\n", time.Duration(spec.TimeoutSeconds)*time.Second)
+		}
+		names := make([]string, 0, len(spec.Parameters))
+		for name := range spec.Parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("Parameter: %s=%s\n", name, spec.Parameters[name])
+		}
+
+		j, err := backend.Submit(cmd.Context(), spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\nJob ID: %s\n", j.ID)
+		if j.Queue != "" {
+			fmt.Printf("Queue: %s\n", j.Queue)
+		}
 	},
 }
 
@@ -77,14 +176,52 @@ var jobStatusCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		jobID := args[0]
+		backend := mustJobBackend(cmd, jobID)
+
+		j, err := backend.Describe(cmd.Context(), jobID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
-		fmt.Printf("Job ID: %s\n", jobID)
-		fmt.Println("Status: RUNNING")
-		fmt.Println("Started: 2025-10-18 14:30:00")
-		fmt.Println("Runtime: 45 minutes")
+		fmt.Printf("Job ID: %s\n", j.ID)
+		fmt.Printf("Name: %s\n", j.Name)
+		fmt.Printf("Backend: %s\n", backend.Name())
+		fmt.Printf("Status: %s\n", j.Status)
+		if j.StatusReason != "" {
+			fmt.Printf("Reason: %s\n", j.StatusReason)
+		}
+		if j.Queue != "" {
+			fmt.Printf("Queue: %s\n", j.Queue)
+		}
+		fmt.Printf("Submitted: %s\n", j.CreatedAt.Local().Format(timeFormat))
+		if j.StartedAt != nil {
+			fmt.Printf("Started: %s\n", j.StartedAt.Local().Format(timeFormat))
+			fmt.Printf("Runtime: %s\n", j.Runtime().Round(time.Second))
+		}
+		if j.StoppedAt != nil {
+			fmt.Printf("Ended: %s\n", j.StoppedAt.Local().Format(timeFormat))
+		}
+		if j.ExitCode != nil {
+			fmt.Printf("Exit code: %d\n", *j.ExitCode)
+		}
 
-		// TODO: Implement status checking
-		fmt.Println("\n[NOT IMPLEMENTED] Job status functionality coming soon")
+		r, err := backend.Store.Get(jobID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if r != nil {
+			fmt.Printf("\nApplication: %s (%s)\n", r.Spec.App, r.Spec.Variant)
+			fmt.Printf("Architecture: %s\n", r.Spec.Architecture)
+			if r.Spec.Environment != "" {
+				fmt.Printf("Environment: %s\n", r.Spec.Environment)
+			}
+			fmt.Printf("vCPUs: %d\n", r.Spec.VCPUs)
+			fmt.Printf("Memory: %d MB\n", r.Spec.MemoryMB)
+			fmt.Printf("Input: %s\n", r.Spec.Input)
+			fmt.Printf("Output: %s\n", r.Spec.Output)
+		}
 	},
 }
 
@@ -95,28 +232,88 @@ var jobLogsCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		jobID := args[0]
 		follow, _ := cmd.Flags().GetBool("follow")
+		backend := mustJobBackend(cmd, jobID)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
 
-		fmt.Printf("Logs for job: %s\n", jobID)
 		if follow {
-			fmt.Println("Following logs (Ctrl+C to stop)...")
+			fmt.Fprintln(os.Stderr, "Following logs (Ctrl+C to stop)...")
 		}
 
-		// TODO: Implement log viewing
-		fmt.Println("\n[NOT IMPLEMENTED] Job logs functionality coming soon")
+		err := backend.Logs(ctx, jobID, follow, os.Stdout)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	},
 }
 
 var jobListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List jobs",
+	Long: `List jobs recorded in the local job database (~/.aws-hpc/jobs.db).
+
+Naming a queue lists jobs from AWS Batch directly instead, including
+jobs submitted by other tools.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		status, _ := cmd.Flags().GetString("status")
+		statusName, _ := cmd.Flags().GetString("status")
 		limit, _ := cmd.Flags().GetInt("limit")
+		queue, _ := cmd.Flags().GetString("queue")
+		appName, _ := cmd.Flags().GetString("app")
+
+		var status job.Status
+		if statusName != "all" {
+			var err error
+			if status, err = job.ParseStatus(statusName); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		fmt.Printf("Listing jobs (status: %s, limit: %d)\n", status, limit)
+		backend := mustJobBackend(cmd, "")
 
-		// TODO: Implement job listing
-		fmt.Println("\n[NOT IMPLEMENTED] Job listing functionality coming soon")
+		var records []*job.Record
+		if queue != "" {
+			jobs, err := backend.List(cmd.Context(), job.ListOptions{Queue: queue, Status: status, Limit: limit})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			for _, j := range jobs {
+				records = append(records, &job.Record{Backend: backend.Name(), Job: *j})
+			}
+		} else {
+			// Show every backend's history unless one was asked for
+			filter := job.Filter{App: appName, Status: status, Limit: limit}
+			if cmd.Flags().Changed("backend") {
+				filter.Backend = backend.Name()
+			}
+			var err error
+			records, err = backend.History(cmd.Context(), filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if len(records) == 0 {
+			fmt.Println("No jobs found")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "JOB ID\tAPP\tVARIANT\tARCH\tSTATUS\tSUBMITTED\tRUNTIME")
+		for _, r := range records {
+			runtime := "-"
+			if r.Job.StartedAt != nil {
+				runtime = r.Job.Runtime().Round(time.Second).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Job.ID, orDash(r.Spec.App), orDash(r.Spec.Variant), orDash(r.Spec.Architecture),
+				r.Job.Status, r.Job.CreatedAt.Local().Format(timeFormat), runtime)
+		}
+		w.Flush()
 	},
 }
 
@@ -126,24 +323,116 @@ var jobCancelCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		jobID := args[0]
+		reason, _ := cmd.Flags().GetString("reason")
+		backend := mustJobBackend(cmd, jobID)
 
 		fmt.Printf("Canceling job: %s\n", jobID)
 
-		// TODO: Implement job cancellation
-		fmt.Println("\n[NOT IMPLEMENTED] Job cancellation functionality coming soon")
+		if err := backend.Cancel(cmd.Context(), jobID, reason); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✓ Job canceled")
 	},
 }
 
+// timeFormat is the layout used when printing job timestamps
+const timeFormat = "2006-01-02 15:04:05"
+
+// newJobBackend creates a job backend by name
+func newJobBackend(name, region, engine string) (job.Backend, error) {
+	switch name {
+	case "batch":
+		return job.NewBatch(region), nil
+	case "local":
+		home, err := config.HomeDir()
+		if err != nil {
+			return nil, err
+		}
+		return job.NewLocal(filepath.Join(home, "local"), engine), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q (supported: batch, local)", name)
+	}
+}
+
+// openJobStore opens the local job database
+func openJobStore() (*job.Store, error) {
+	home, err := config.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return job.OpenStore(job.DefaultStorePath(home))
+}
+
+// mustJobBackend returns the backend selected by --backend, wrapped so
+// jobs are recorded in the local job database, or exits. When jobID is
+// recorded and --backend was not given, the job's own backend and region
+// are used.
+func mustJobBackend(cmd *cobra.Command, jobID string) *job.Tracked {
+	name, _ := cmd.Flags().GetString("backend")
+	region, _ := cmd.Flags().GetString("region")
+	engine, _ := cmd.Flags().GetString("engine")
+
+	store, err := openJobStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jobID != "" && !cmd.Flags().Changed("backend") {
+		r, err := store.Get(jobID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if r != nil {
+			name = r.Backend
+			if r.Region != "" && !cmd.Flags().Changed("region") {
+				region = r.Region
+			}
+		}
+	}
+
+	backend, err := newJobBackend(name, region, engine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	tracked := job.NewTracked(backend, store, region)
+	tracked.Open = func(name, region string) (job.Backend, error) {
+		return newJobBackend(name, region, engine)
+	}
+	return tracked
+}
+
+// orDash returns s, or "-" if it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func init() {
+	// backend selection, shared by all job subcommands
+	jobCmd.PersistentFlags().String("backend", "batch", "Job backend (batch, local)")
+	jobCmd.PersistentFlags().String("region", "us-east-1", "AWS region")
+	jobCmd.PersistentFlags().String("engine", "", "Container engine for the local backend (docker, podman; default: auto-detect)")
+
 	// job submit flags
-	jobSubmitCmd.Flags().String("env", "", "Environment name (benchmark, production, etc.)")
-	jobSubmitCmd.Flags().String("arch", "", "Target architecture")
-	jobSubmitCmd.Flags().String("input", "", "S3 input path (required)")
-	jobSubmitCmd.Flags().String("output", "", "S3 output path (required)")
-	jobSubmitCmd.Flags().Int("vcpus", 8, "Number of vCPUs")
-	jobSubmitCmd.Flags().Int("memory", 16384, "Memory in MB")
-	jobSubmitCmd.MarkFlagRequired("input")
-	jobSubmitCmd.MarkFlagRequired("output")
+	jobSubmitCmd.Flags().String("env", "", "Environment name (benchmark, production, etc.), whose settings the other flags override; required with --backend batch")
+	jobSubmitCmd.Flags().String("variant", "", "Application variant (default: the environment's, or the first variant)")
+	jobSubmitCmd.Flags().String("arch", "", "Target architecture (default: the environment's, or the first architecture)")
+	jobSubmitCmd.Flags().String("queue", "", "Job queue (default: the environment's, or the highest priority queue for the architecture)")
+	jobSubmitCmd.Flags().String("input", "", "S3 input path, or local directory with --backend local (default: the environment's)")
+	jobSubmitCmd.Flags().String("output", "", "S3 output path, or local directory with --backend local (default: the environment's)")
+	jobSubmitCmd.Flags().String("image", "", "Container image (default: <repository>:<variant>-<arch>)")
+	jobSubmitCmd.Flags().String("s3-endpoint", "", "S3-compatible endpoint for s3:// paths (e.g. a local MinIO)")
+	jobSubmitCmd.Flags().Int("vcpus", 0, fmt.Sprintf("Number of vCPUs (default: the environment's, or %d capped to the architecture's smallest instance type)", job.DefaultVCPUs))
+	jobSubmitCmd.Flags().Int("memory", 0, fmt.Sprintf("Memory in MB (default: the environment's, or %d capped to the architecture's smallest instance type)", job.DefaultMemoryMB))
+	jobSubmitCmd.Flags().Duration("timeout", 0, "Walltime of each attempt on AWS Batch, e.g. 6h (default: the environment's)")
+	jobSubmitCmd.Flags().Int("retries", 0, "Attempts on AWS Batch (default: the environment's)")
+	jobSubmitCmd.Flags().StringToString("param", nil, "Simulation parameter passed to the entrypoint, overriding the environment's (name=value, repeatable)")
 
 	// job logs flags
 	jobLogsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
@@ -151,6 +440,11 @@ func init() {
 	// job list flags
 	jobListCmd.Flags().String("status", "all", "Filter by status (RUNNING, SUCCEEDED, FAILED, all)")
 	jobListCmd.Flags().Int("limit", 10, "Maximum number of jobs to list")
+	jobListCmd.Flags().String("queue", "", "List jobs in an AWS Batch queue, by its deployed name (e.g. geos-chem-spot-x86-production), instead of local history")
+	jobListCmd.Flags().String("app", "", "Filter by application")
+
+	// job cancel flags
+	jobCancelCmd.Flags().String("reason", "Canceled by aws-hpc", "Reason recorded with the cancellation")
 
 	// Add subcommands
 	jobCmd.AddCommand(jobSubmitCmd)
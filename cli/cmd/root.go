@@ -17,14 +17,26 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
-	"github.com/aws-hpc/pkg"
+	"github.com/aws-hpc/platform/pkg"
+	"github.com/aws-hpc/platform/pkg/command"
+	"github.com/aws-hpc/platform/pkg/config"
 )
 
 var (
 	// Verbose enables verbose output
 	Verbose bool
+
+	// Site selects the app.<site>.yaml overlay merged over each app.yaml
+	Site string
+
+	// Overlays are overlay files merged over app.yaml after the site's
+	Overlays []string
 )
 
 // rootCmd represents the base command
@@ -52,6 +64,13 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&Site, "site", "", "merge the app.<site>.yaml overlay over app.yaml (default: $AWS_HPC_SITE)")
+	rootCmd.PersistentFlags().StringArrayVar(&Overlays, "overlay", nil, "overlay file merged over app.yaml, after the site overlay (repeatable)")
+
+	// Resolve ${account_id}, ${region} and ${secret:...} through the aws CLI
+	config.DefaultResolver = config.AWSResolver(command.Exec{})
+
+	versionCmd.Flags().String("check", "", "Check the applications in a directory (or one application) against this platform version")
 
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
@@ -65,7 +84,24 @@ func init() {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
+	Long: `Show version information.
+
+With --check, report whether each application's platform_version constraint
+is satisfied by this platform version, and whether its spec_version can be
+read. Applications that require a newer platform are refused when loaded;
+older specs are migrated (see app migrate).
+
+Examples:
+  aws-hpc version
+  aws-hpc version --check applications/`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if dir, _ := cmd.Flags().GetString("check"); dir != "" {
+			if !checkVersions(dir) {
+				os.Exit(1)
+			}
+			return
+		}
+
 		info := pkg.GetVersionInfo()
 		fmt.Printf("aws-hpc version %s\n", info.Version)
 		fmt.Printf("API version: %s\n", info.APIVersion)
@@ -80,3 +116,55 @@ var versionCmd = &cobra.Command{
 		}
 	},
 }
+
+// checkVersions prints the compatibility of the applications under dir, or
+// of dir itself if it is an application, and reports whether all are
+// compatible
+func checkVersions(dir string) bool {
+	dirs := []string{dir}
+	if _, err := os.Stat(filepath.Join(dir, "app.yaml")); err != nil {
+		matches, err := filepath.Glob(filepath.Join(dir, "*", "app.yaml"))
+		if err != nil || len(matches) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no applications found in %s\n", dir)
+			return false
+		}
+		dirs = dirs[:0]
+		for _, m := range matches {
+			dirs = append(dirs, filepath.Dir(m))
+		}
+	}
+
+	fmt.Printf("Platform version: %s (app spec %s, migrates from %s)\n\n", pkg.Version, pkg.AppSpecVersion, pkg.MinAppSpecVersion)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLICATION\tVERSION\tPLATFORM\tSTATUS")
+	ok := true
+	for _, d := range dirs {
+		app, report, err := config.CheckApplication(d)
+		if err != nil {
+			ok = false
+			fmt.Fprintf(w, "%s\t-\t-\t✗ %v\n", filepath.Base(d), err)
+			continue
+		}
+
+		status := "✓ compatible"
+		var problems []string
+		for _, f := range report.Findings {
+			if f.Path != "platform_version" && f.Path != "spec_version" {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s: %s", f.Path, f.Message))
+			if f.Severity == config.SeverityError {
+				ok = false
+				status = "✗ incompatible"
+			} else if status == "✓ compatible" {
+				status = "⚠ warning"
+			}
+		}
+		if len(problems) > 0 {
+			status += " (" + strings.Join(problems, "; ") + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", app.Name, app.Version, app.PlatformVersion, status)
+	}
+	w.Flush()
+	return ok
+}
@@ -20,7 +20,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/aws-hpc/cli/cmd"
+	"github.com/aws-hpc/platform/cli/cmd"
 )
 
 func main() {
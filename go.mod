@@ -4,10 +4,12 @@ go 1.21
 
 require (
 	github.com/spf13/cobra v1.8.0
+	go.etcd.io/bbolt v1.3.8
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.10.0 // indirect
 )
@@ -0,0 +1,352 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package baseimage discovers and builds the HPC base images
+package baseimage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/container"
+)
+
+// DefaultDir is the base image directory, relative to the repository root
+const DefaultDir = "base-images"
+
+// BuildArgs are the versions a base image build passes to its Dockerfile,
+// when the Dockerfile declares them
+var BuildArgs = []string{"SPACK_VERSION", "AOCL_VERSION", "OPENMPI_VERSION", "GCC_VERSION"}
+
+// Image is a base image defined by base-images/<family>/Dockerfile.<generation>
+type Image struct {
+	Family     string
+	Generation string
+	Dockerfile string
+	Labels     map[string]string // LABEL values
+	Args       map[string]string // ARG defaults
+	Env        map[string]string // ENV values, with ARG references expanded
+
+	// From the Dockerfile's header comment
+	Description string
+	MathLibrary string
+
+	// Libraries are the versioned ARGs (GCC_VERSION is gcc) followed by
+	// the packages installed with Spack
+	Libraries []Library
+}
+
+// Library is a compiler, tool or library version in a base image
+type Library struct {
+	Name    string
+	Version string
+}
+
+// CompilerFlagVars are the compiler flag variables base images set
+var CompilerFlagVars = []string{"CFLAGS", "CXXFLAGS", "FCFLAGS", "FFLAGS"}
+
+// Name returns the image repository name, hpc-base-<family>-<generation>
+func (i *Image) Name() string {
+	return fmt.Sprintf("hpc-base-%s-%s", i.Family, i.Generation)
+}
+
+// Target returns the image as a build target, <family>/<generation>
+func (i *Image) Target() string {
+	return i.Family + "/" + i.Generation
+}
+
+// Date returns the org.hpc.base.date label, or "" if the Dockerfile has none
+func (i *Image) Date() string {
+	return i.Labels["org.hpc.base.date"]
+}
+
+// Architecture returns the org.hpc.base.architecture label, the ISA level
+// the image is built for, such as x86-64-v4
+func (i *Image) Architecture() string {
+	return i.Labels["org.hpc.base.architecture"]
+}
+
+// Library returns the version of a library, or "" if the image does not
+// declare it
+func (i *Image) Library(name string) string {
+	for _, lib := range i.Libraries {
+		if lib.Name == name {
+			return lib.Version
+		}
+	}
+	return ""
+}
+
+// Discover finds the base images under dir, sorted by family and generation
+func Discover(dir string) ([]*Image, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "Dockerfile.*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", dir, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no base image Dockerfiles found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	var images []*Image
+	for _, path := range paths {
+		img := &Image{
+			Family:     filepath.Base(filepath.Dir(path)),
+			Generation: strings.TrimPrefix(filepath.Base(path), "Dockerfile."),
+			Dockerfile: path,
+		}
+		if err := img.parse(); err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// Select resolves a target expression: <family>/<generation>,
+// <family>/all or all
+func Select(images []*Image, target string) ([]*Image, error) {
+	if target == "all" {
+		return images, nil
+	}
+	family, generation, ok := strings.Cut(target, "/")
+	if !ok || family == "" || generation == "" {
+		return nil, fmt.Errorf("invalid target %q: use <family>/<generation>, <family>/all or all", target)
+	}
+
+	var selected []*Image
+	for _, img := range images {
+		if img.Family == family && (generation == "all" || img.Generation == generation) {
+			selected = append(selected, img)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no base image matches %s", target)
+	}
+	return selected, nil
+}
+
+// parse reads the header comment, ARG defaults, LABELs, ENVs and Spack
+// installs of the image's Dockerfile
+func (i *Image) parse() error {
+	f, err := os.Open(i.Dockerfile)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", i.Dockerfile, err)
+	}
+	defer f.Close()
+
+	i.Labels = map[string]string{}
+	i.Args = map[string]string{}
+	i.Env = map[string]string{}
+	var argNames []string
+	var spack []Library
+
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string {
+			if v, ok := i.Env[name]; ok {
+				return v
+			}
+			return i.Args[name]
+		})
+	}
+
+	header := true
+	var line string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "#") {
+			if header {
+				i.parseHeader(strings.TrimSpace(strings.TrimPrefix(text, "#")))
+			}
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		header = false
+		if strings.HasSuffix(text, "\\") {
+			line += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		line += text
+
+		instruction, rest, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(instruction) {
+		case "ARG":
+			name, value, _ := strings.Cut(strings.TrimSpace(rest), "=")
+			if _, ok := i.Args[name]; !ok {
+				argNames = append(argNames, name)
+			}
+			i.Args[name] = unquote(value)
+		case "LABEL":
+			for k, v := range keyValues(rest) {
+				i.Labels[k] = v
+			}
+		case "ENV":
+			for k, v := range keyValues(rest) {
+				i.Env[k] = expand(v)
+			}
+		case "RUN":
+			spack = append(spack, spackInstalls(expand(rest))...)
+		}
+		line = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", i.Dockerfile, err)
+	}
+
+	seen := map[string]bool{}
+	for _, name := range argNames {
+		lib, ok := strings.CutSuffix(name, "_VERSION")
+		if !ok || i.Args[name] == "" {
+			continue
+		}
+		lib = strings.ToLower(lib)
+		seen[lib] = true
+		i.Libraries = append(i.Libraries, Library{Name: lib, Version: i.Args[name]})
+	}
+	for _, lib := range spack {
+		if !seen[lib.Name] {
+			seen[lib.Name] = true
+			i.Libraries = append(i.Libraries, lib)
+		}
+	}
+	return nil
+}
+
+// parseHeader reads a line of the Dockerfile's leading comment, such as
+//
+//	# HPC Base Image - AMD EPYC Genoa (Zen 4)
+//	# Math Library: AMD AOCL (BLIS + libFLAME)
+func (i *Image) parseHeader(text string) {
+	if desc, ok := strings.CutPrefix(text, "HPC Base Image - "); ok {
+		i.Description = desc
+		return
+	}
+	if key, value, ok := strings.Cut(text, ":"); ok && strings.TrimSpace(key) == "Math Library" {
+		i.MathLibrary = strings.TrimSpace(value)
+	}
+}
+
+// spackInstalls returns the versioned packages installed by the spack
+// install commands of a RUN instruction
+func spackInstalls(run string) []Library {
+	var libs []Library
+	for _, command := range strings.Split(run, "&&") {
+		fields := strings.Fields(command)
+		if len(fields) < 2 || fields[0] != "spack" || fields[1] != "install" {
+			continue
+		}
+		for _, spec := range fields[2:] {
+			if strings.HasPrefix(spec, "-") {
+				continue
+			}
+			name, version, ok := strings.Cut(spec, "@")
+			if !ok {
+				continue
+			}
+			if j := strings.IndexAny(version, "%+~ ^"); j >= 0 {
+				version = version[:j]
+			}
+			libs = append(libs, Library{Name: name, Version: version})
+		}
+	}
+	return libs
+}
+
+// keyValues splits key=value pairs, which may be quoted
+func keyValues(s string) map[string]string {
+	pairs := map[string]string{}
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, s, _ = strings.Cut(rest, " ")
+		}
+		pairs[unquote(strings.TrimSpace(key))] = value
+	}
+	return pairs
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// BuildOptions controls how base images are built
+type BuildOptions struct {
+	Registry  string // registry host to tag for; empty for the local image store
+	Date      string // image tag; default the build date
+	Push      bool
+	BuildArgs map[string]string // overrides of the Dockerfile's ARG defaults
+}
+
+// Build returns the docker buildx build of a base image. The image is
+// tagged <name>:<date> and <name>:latest, which app.yaml base images
+// usually reference. The date defaults to the build date rather than the
+// Dockerfile's date label, so a rebuild never replaces an earlier dated
+// image.
+func (i *Image) Build(opts BuildOptions) *container.Build {
+	date := opts.Date
+	if date == "" {
+		date = time.Now().UTC().Format("20060102")
+	}
+	ref := func(tag string) string {
+		if opts.Registry == "" {
+			return i.Name() + ":" + tag
+		}
+		return opts.Registry + "/" + i.Name() + ":" + tag
+	}
+
+	args := map[string]string{}
+	for _, name := range BuildArgs {
+		if value, ok := i.Args[name]; ok {
+			args[name] = value
+		}
+	}
+	for name, value := range opts.BuildArgs {
+		args[name] = value
+	}
+
+	return &container.Build{
+		Variant:      i.Family,
+		Architecture: i.Generation,
+		Platform:     container.Platform(i.Family),
+		Dockerfile:   i.Dockerfile,
+		Context:      filepath.Dir(i.Dockerfile),
+		Tag:          ref(date),
+		Alias:        ref("latest"),
+		BuildArgs:    args,
+		Push:         opts.Push,
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseimage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const baseDir = "../../base-images"
+
+func TestDiscover(t *testing.T) {
+	images, err := Discover(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := Select(images, "amd/zen4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zen4 := img[0]
+
+	if zen4.Name() != "hpc-base-amd-zen4" {
+		t.Errorf("Name() = %s", zen4.Name())
+	}
+	if zen4.Date() != "20251018" {
+		t.Errorf("Date() = %s, want 20251018", zen4.Date())
+	}
+	if zen4.Labels["org.hpc.base.architecture"] != "x86-64-v4" {
+		t.Errorf("architecture label = %q", zen4.Labels["org.hpc.base.architecture"])
+	}
+	if zen4.Args["SPACK_VERSION"] != "v0.23.1" {
+		t.Errorf("SPACK_VERSION = %q", zen4.Args["SPACK_VERSION"])
+	}
+}
+
+func TestSelect(t *testing.T) {
+	var images []*Image
+	for _, target := range []string{"amd/zen3", "amd/zen4", "arm/graviton4", "intel/spr"} {
+		family, generation, _ := strings.Cut(target, "/")
+		images = append(images, &Image{Family: family, Generation: generation})
+	}
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"all", []string{"amd/zen3", "amd/zen4", "arm/graviton4", "intel/spr"}},
+		{"amd/all", []string{"amd/zen3", "amd/zen4"}},
+		{"arm/graviton4", []string{"arm/graviton4"}},
+	}
+	for _, tt := range tests {
+		selected, err := Select(images, tt.target)
+		if err != nil {
+			t.Errorf("Select(%s) = %v", tt.target, err)
+			continue
+		}
+		var got []string
+		for _, img := range selected {
+			got = append(got, img.Target())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Select(%s) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+
+	for _, target := range []string{"amd", "amd/zen5", "power/all", "/zen4"} {
+		if _, err := Select(images, target); err == nil {
+			t.Errorf("Select(%s): expected an error", target)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	images, err := Discover(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := images[0].Build(BuildOptions{
+		Registry:  "registry.example.com",
+		Push:      true,
+		BuildArgs: map[string]string{"OPENMPI_VERSION": "5.0.5"},
+	})
+
+	if want := "registry.example.com/hpc-base-amd-zen4:" + time.Now().UTC().Format("20060102"); build.Tag != want {
+		t.Errorf("Tag = %s, want %s", build.Tag, want)
+	}
+	if build.Alias != "registry.example.com/hpc-base-amd-zen4:latest" {
+		t.Errorf("Alias = %s", build.Alias)
+	}
+	if build.Platform != "linux/amd64" {
+		t.Errorf("Platform = %s", build.Platform)
+	}
+	want := map[string]string{
+		"SPACK_VERSION":   "v0.23.1",
+		"AOCL_VERSION":    "4.2",
+		"OPENMPI_VERSION": "5.0.5",
+		"GCC_VERSION":     "11.5.0",
+	}
+	if !reflect.DeepEqual(build.BuildArgs, want) {
+		t.Errorf("BuildArgs = %v, want %v", build.BuildArgs, want)
+	}
+
+	build = images[0].Build(BuildOptions{Date: "20251018"})
+	if build.Tag != "hpc-base-amd-zen4:20251018" {
+		t.Errorf("Tag = %s, want the --date tag", build.Tag)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseimage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/command"
+)
+
+// Catalog is the set of base images defined under a base image directory
+type Catalog struct {
+	Dir    string
+	Images []*Image
+}
+
+// LoadCatalog discovers the base images under dir
+func LoadCatalog(dir string) (*Catalog, error) {
+	images, err := Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{Dir: dir, Images: images}, nil
+}
+
+// Lookup finds a base image by target (amd/zen4) or image reference
+// (hpc-base-amd-zen4:latest). Registry hosts and tags are ignored.
+func (c *Catalog) Lookup(ref string) (*Image, error) {
+	name := ref
+	if i := strings.LastIndex(name, "/"); i >= 0 && strings.HasPrefix(name[i+1:], "hpc-base-") {
+		name = name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+
+	for _, img := range c.Images {
+		if img.Name() == name || img.Target() == name {
+			return img, nil
+		}
+	}
+	return nil, fmt.Errorf("base image %s not found in %s", ref, c.Dir)
+}
+
+// Built is a base image in the local image store
+type Built struct {
+	Ref     string
+	ID      string
+	Created string
+	Labels  map[string]string
+}
+
+// Inspect returns the labels of a built image from docker image inspect
+func Inspect(ctx context.Context, r command.Runner, ref string) (*Built, error) {
+	out, err := command.Output(ctx, r, "docker", "image", "inspect", "--format", "{{json .}}", ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", ref, err)
+	}
+	var inspect struct {
+		ID      string `json:"Id"`
+		Created string `json:"Created"`
+		Config  struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
+	}
+	if err := json.Unmarshal(out, &inspect); err != nil {
+		return nil, fmt.Errorf("failed to parse image metadata for %s: %w", ref, err)
+	}
+	return &Built{Ref: ref, ID: inspect.ID, Created: inspect.Created, Labels: inspect.Config.Labels}, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseimage
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog, err := LoadCatalog(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{
+		"amd/zen4",
+		"hpc-base-amd-zen4",
+		"hpc-base-amd-zen4:latest",
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com/hpc-base-amd-zen4:20251018",
+	} {
+		if _, err := catalog.Lookup(ref); err != nil {
+			t.Errorf("Lookup(%s) = %v", ref, err)
+		}
+	}
+	if _, err := catalog.Lookup("hpc-base-amd-zen3:latest"); err == nil {
+		t.Error("expected an error for an undefined base image")
+	}
+
+	img, err := catalog.Lookup("amd/zen4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Description != "AMD EPYC Genoa (Zen 4)" {
+		t.Errorf("Description = %q", img.Description)
+	}
+	if img.MathLibrary != "AMD AOCL (BLIS + libFLAME)" {
+		t.Errorf("MathLibrary = %q", img.MathLibrary)
+	}
+	if img.Architecture() != "x86-64-v4" {
+		t.Errorf("Architecture() = %q", img.Architecture())
+	}
+
+	libraries := map[string]string{
+		"gcc":            "11.5.0",
+		"spack":          "v0.23.1",
+		"openmpi":        "4.1.6",
+		"amdblis":        "4.2",
+		"amdlibflame":    "4.2",
+		"hdf5":           "1.14.3",
+		"netcdf-fortran": "4.6.1",
+	}
+	for name, want := range libraries {
+		if got := img.Library(name); got != want {
+			t.Errorf("Library(%s) = %q, want %q", name, got, want)
+		}
+	}
+
+	if got := img.Env["FCFLAGS"]; got != "-march=znver4 -mavx512f -O3 -fopenmp -ffast-math -funroll-loops" {
+		t.Errorf("FCFLAGS = %q", got)
+	}
+	if got := img.Env["AOCL_VERSION"]; got != "4.2" {
+		t.Errorf("AOCL_VERSION = %q, want the ARG default expanded", got)
+	}
+}
+
+type inspectRunner struct{ out string }
+
+func (r inspectRunner) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	_, err := io.WriteString(stdout, r.out)
+	return err
+}
+
+func TestInspect(t *testing.T) {
+	r := inspectRunner{out: `{"Id": "sha256:abc", "Created": "2025-10-18T00:00:00Z", "Config": {"Labels": {"org.hpc.base.date": "20251018"}}}`}
+	built, err := Inspect(context.Background(), r, "hpc-base-amd-zen4:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.ID != "sha256:abc" || built.Labels["org.hpc.base.date"] != "20251018" {
+		t.Errorf("Inspect() = %+v", built)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseimage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// Issue is a disagreement between an application architecture and its base
+// image
+type Issue struct {
+	Architecture string
+	Field        string // base_image, compiler_flags or math_library
+	Severity     config.Severity
+	Message      string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("architecture %s: %s: %s", i.Architecture, i.Field, i.Message)
+}
+
+// targetFlags are the compiler flags that choose the CPU a build targets
+var targetFlags = []string{"-march", "-mcpu", "-mtune"}
+
+// Check resolves each architecture's base image in the catalog. A base
+// image that is not defined is an error; compiler flags or a math library
+// that differ from what the base image declares are warnings.
+func (c *Catalog) Check(app *config.Application) []Issue {
+	var issues []Issue
+	for _, a := range app.Compute.Architectures {
+		issue := func(field string, severity config.Severity, format string, args ...interface{}) {
+			issues = append(issues, Issue{
+				Architecture: a.Name,
+				Field:        field,
+				Severity:     severity,
+				Message:      fmt.Sprintf(format, args...),
+			})
+		}
+
+		img, err := c.Lookup(a.BaseImage)
+		if err != nil {
+			issue("base_image", config.SeverityError, "%s is not defined in %s", a.BaseImage, c.Dir)
+			continue
+		}
+		if img.Family != a.Family {
+			issue("base_image", config.SeverityWarning, "%s is for family %s, not %s", img.Name(), img.Family, a.Family)
+		}
+
+		for _, name := range targetFlags {
+			want := flagValue(a.CompilerFlags, name)
+			if want == "" {
+				continue
+			}
+			for _, v := range CompilerFlagVars {
+				got := flagValue(strings.Fields(img.Env[v]), name)
+				if got != "" && got != want {
+					issue("compiler_flags", config.SeverityWarning, "%s=%s, but %s builds with %s=%s (%s)",
+						name, want, img.Name(), name, got, v)
+					break
+				}
+			}
+		}
+
+		ml := a.MathLibrary
+		for _, spec := range []string{ml.BLAS, ml.LAPACK} {
+			if spec == "" {
+				continue
+			}
+			name, version, _ := strings.Cut(spec, "@")
+			got := img.Library(name)
+			switch {
+			case got == "":
+				issue("math_library", config.SeverityWarning, "%s does not include %s", img.Name(), name)
+			case version != "" && got != version:
+				issue("math_library", config.SeverityWarning, "%s, but %s has %s@%s", spec, img.Name(), name, got)
+			}
+		}
+		if ml.Name != "" {
+			got := ""
+			for _, name := range libraryNames(ml.Name) {
+				if got = img.Library(name); got != "" {
+					break
+				}
+			}
+			switch {
+			case got == "" && ml.BLAS == "" && ml.LAPACK == "":
+				// With BLAS and LAPACK given, those are what the image must have
+				issue("math_library", config.SeverityWarning, "%s does not include %s", img.Name(), ml.Name)
+			case got != "" && ml.Version != "" && got != ml.Version:
+				issue("math_library", config.SeverityWarning, "%s %s, but %s has %s", ml.Name, ml.Version, img.Name(), got)
+			}
+		}
+	}
+	return issues
+}
+
+// flagValue returns the value of a -name=value flag, or ""
+func flagValue(flags []string, name string) string {
+	for _, f := range flags {
+		if v, ok := strings.CutPrefix(f, name+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// libraryNames returns the names a math library may be declared under in a
+// base image: amd-aocl is declared as AOCL_VERSION, so as aocl
+func libraryNames(name string) []string {
+	names := []string{name}
+	if _, short, ok := strings.Cut(name, "-"); ok {
+		names = append(names, short)
+	}
+	return names
+}
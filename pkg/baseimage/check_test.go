@@ -0,0 +1,58 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseimage
+
+import (
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+func TestCheck(t *testing.T) {
+	catalog, err := LoadCatalog(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := config.LoadApplication("../../applications/geos-chem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the zen4 base image is defined; c7a matches it
+	for _, issue := range catalog.Check(app) {
+		if issue.Architecture == "c7a" {
+			t.Errorf("unexpected issue: %s", issue)
+		} else if issue.Severity != config.SeverityError || issue.Field != "base_image" {
+			t.Errorf("expected a missing base image error, got %s (%s)", issue, issue.Severity)
+		}
+	}
+
+	app.Compute.Architectures = app.Compute.Architectures[:1]
+	c7a := &app.Compute.Architectures[0]
+	c7a.CompilerFlags = []string{"-march=znver3", "-O3"}
+	c7a.MathLibrary.Version = "5.0"
+	c7a.MathLibrary.LAPACK = "amdlibflame@5.0"
+
+	issues := catalog.Check(app)
+	want := map[string]bool{"compiler_flags": true, "math_library": true}
+	if len(issues) != 3 {
+		t.Errorf("got %d issues, want 3: %v", len(issues), issues)
+	}
+	for _, issue := range issues {
+		if !want[issue.Field] || issue.Severity != config.SeverityWarning {
+			t.Errorf("unexpected issue %s (%s)", issue, issue.Severity)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package command runs external tools (aws, docker, podman) behind an
+// interface so callers can be tested without them installed
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Runner executes an external command, writing its standard output to
+// stdout and its standard error to stderr. A nil stderr means the runner
+// captures it and reports it in the returned error.
+type Runner interface {
+	Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error
+}
+
+// Exec runs commands on the local machine using os/exec
+type Exec struct{}
+
+// Run executes the command and returns an error if it exits with a
+// non-zero status
+func (Exec) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	var captured bytes.Buffer
+	c := exec.CommandContext(ctx, name, args...)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if stderr == nil {
+		c.Stderr = &captured
+	}
+
+	if err := c.Run(); err != nil {
+		msg := strings.TrimSpace(captured.String())
+		if msg == "" {
+			return fmt.Errorf("%s failed: %w", name, err)
+		}
+		return fmt.Errorf("%s failed: %w: %s", name, err, msg)
+	}
+	return nil
+}
+
+// Output runs the command and returns its standard output
+func Output(ctx context.Context, r Runner, name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := r.Run(ctx, &stdout, nil, name, args...); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -16,11 +16,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Application represents a complete application specification
@@ -29,6 +28,7 @@ type Application struct {
 	DisplayName     string              `yaml:"display_name"`
 	Version         string              `yaml:"version"`
 	PlatformVersion string              `yaml:"platform_version"`
+	SpecVersion     string              `yaml:"spec_version,omitempty"`
 	Metadata        ApplicationMetadata `yaml:"metadata"`
 	Variants        []Variant           `yaml:"variants"`
 	Compute         ComputeSpec         `yaml:"compute"`
@@ -39,6 +39,12 @@ type Application struct {
 	Licensing       LicensingSpec       `yaml:"licensing,omitempty"`
 	GPU             GPUSpec             `yaml:"gpu,omitempty"`
 	Networking      NetworkingSpec      `yaml:"networking,omitempty"`
+
+	// Dir is the directory app.yaml was loaded from, which environment
+	// files are relative to
+	Dir string `yaml:"-"`
+
+	secrets redactor `yaml:"-"` // resolved ${secret:name} references
 }
 
 // ApplicationMetadata contains application metadata
@@ -124,11 +130,17 @@ type ContainerSpec struct {
 	Dependencies []string                      `yaml:"dependencies"`
 }
 
-// ContainerVariant defines a container variant build configuration
+// ContainerVariant defines a container variant build configuration. When
+// Dockerfile is empty, one is generated from the application spec.
 type ContainerVariant struct {
 	Dockerfile string            `yaml:"dockerfile"`
 	Context    string            `yaml:"context"`
 	BuildArgs  map[string]string `yaml:"build_args"`
+
+	// Generated Dockerfile settings
+	Install         []string `yaml:"install,omitempty"`          // shell commands that build the application
+	Entrypoint      string   `yaml:"entrypoint,omitempty"`       // relative to the context, default entrypoint.sh
+	ConfigGenerator string   `yaml:"config_generator,omitempty"` // relative to the context
 }
 
 // StorageSpec defines storage requirements
@@ -154,12 +166,13 @@ type LifecyclePolicy struct {
 	Expiration        int `yaml:"expiration,omitempty"`
 }
 
-// ScratchStorage defines local scratch storage
+// ScratchStorage defines local scratch storage. Type is the kind of
+// storage; VolumeType and IOPS only apply to EBS volumes.
 type ScratchStorage struct {
-	Type   string `yaml:"type"` // ebs, instance-store
-	SizeGB int    `yaml:"size_gb"`
-	VolumeType string `yaml:"type"` // gp3, io2
-	IOPS   int    `yaml:"iops,omitempty"`
+	Type       string `yaml:"type"` // ebs, instance-store
+	SizeGB     int    `yaml:"size_gb"`
+	VolumeType string `yaml:"volume_type,omitempty"` // gp3, gp2, io1, io2
+	IOPS       int    `yaml:"iops,omitempty"`
 }
 
 // SharedStorage defines shared filesystem
@@ -169,11 +182,14 @@ type SharedStorage struct {
 	ThroughputMode string `yaml:"throughput_mode,omitempty"` // bursting, provisioned
 }
 
-// Environment defines a runtime environment configuration
+// Environment defines a runtime environment configuration. Settings is
+// loaded from the Config file by GetEnvironment.
 type Environment struct {
 	Name        string `yaml:"name"`
 	Config      string `yaml:"config"`
 	Description string `yaml:"description"`
+
+	Settings *EnvironmentConfig `yaml:"-"`
 }
 
 // CostSpec defines cost estimation parameters
@@ -212,8 +228,9 @@ type NetworkingSpec struct {
 	PlacementGroup bool `yaml:"placement_group"`
 }
 
-// LoadApplication loads an application specification from app.yaml
-func LoadApplication(path string) (*Application, error) {
+// LoadApplication loads an application specification from app.yaml, with
+// any overlay files merged over it in order
+func LoadApplication(path string, overlays ...string) (*Application, error) {
 	appYAMLPath := filepath.Join(path, "app.yaml")
 
 	data, err := os.ReadFile(appYAMLPath)
@@ -221,45 +238,43 @@ func LoadApplication(path string) (*Application, error) {
 		return nil, fmt.Errorf("failed to read app.yaml: %w", err)
 	}
 
-	var app Application
-	if err := yaml.Unmarshal(data, &app); err != nil {
+	// The document is checked against AppSchema, so unknown fields, wrong
+	// types and enum values are errors rather than silently ignored
+	app, _, findings, err := check(data, path, overlays)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse app.yaml: %w", err)
 	}
-
-	// Validate application
-	if err := app.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid application specification: %w", err)
+	var errs []error
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			loc := fmt.Sprintf("line %d", f.Line)
+			if f.File != "" {
+				loc = filepath.Base(f.File) + " " + loc
+			}
+			if f.Path != "" {
+				loc += ": " + f.Path
+			}
+			errs = append(errs, fmt.Errorf("%s: %s", loc, f.Message))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid application specification: %w", errors.Join(errs...))
 	}
 
-	return &app, nil
+	return app, nil
 }
 
-// Validate validates the application specification
+// Validate validates the application specification, returning every
+// error-severity finding of Check. Field types and enum values are checked
+// against the schema when app.yaml is loaded.
 func (a *Application) Validate() error {
-	if a.Name == "" {
-		return fmt.Errorf("name is required")
-	}
-	if a.Version == "" {
-		return fmt.Errorf("version is required")
-	}
-	if a.PlatformVersion == "" {
-		return fmt.Errorf("platform_version is required")
-	}
-	if len(a.Variants) == 0 {
-		return fmt.Errorf("at least one variant is required")
-	}
-	if len(a.Compute.Architectures) == 0 {
-		return fmt.Errorf("at least one architecture is required")
-	}
-
-	// Validate each architecture
-	for _, arch := range a.Compute.Architectures {
-		if err := arch.Validate(); err != nil {
-			return fmt.Errorf("invalid architecture %s: %w", arch.Name, err)
+	var errs []error
+	for _, f := range a.Check() {
+		if f.Severity == SeverityError {
+			errs = append(errs, fmt.Errorf("%s: %s", f.Path, f.Message))
 		}
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
 // Validate validates an architecture configuration
@@ -299,12 +314,23 @@ func (a *Application) GetVariant(name string) (*Variant, error) {
 	return nil, fmt.Errorf("variant %s not found", name)
 }
 
-// GetEnvironment returns the environment configuration by name
+// GetEnvironment returns the environment configuration by name, with its
+// settings loaded from the environment file. An environment without a
+// config file has empty settings.
 func (a *Application) GetEnvironment(name string) (*Environment, error) {
 	for _, env := range a.Environments {
-		if env.Name == name {
-			return &env, nil
+		if env.Name != name {
+			continue
+		}
+		env.Settings = &EnvironmentConfig{Name: env.Name, Description: env.Description}
+		if env.Config != "" {
+			settings, err := LoadEnvironmentConfig(filepath.Join(a.Dir, env.Config))
+			if err != nil {
+				return nil, fmt.Errorf("failed to load environment %s: %w", name, err)
+			}
+			env.Settings = settings
 		}
+		return &env, nil
 	}
 	return nil, fmt.Errorf("environment %s not found", name)
 }
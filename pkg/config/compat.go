@@ -0,0 +1,40 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/aws-hpc/platform/pkg"
+)
+
+// Compatibility checks the application against the running platform. A
+// platform_version that pkg.Version does not satisfy is an error, so the
+// application is refused. The spec version is checked when app.yaml is
+// migrated.
+func (a *Application) Compatibility() []Finding {
+	if a.PlatformVersion == "" {
+		return nil
+	}
+	c, err := pkg.ParseConstraint(a.PlatformVersion)
+	if err != nil {
+		return []Finding{{Path: "platform_version", Severity: SeverityError, Message: err.Error()}}
+	}
+	if platform, _ := pkg.ParseSemver(pkg.Version); !c.Allows(platform) {
+		return []Finding{{Path: "platform_version", Severity: SeverityError,
+			Message: fmt.Sprintf("requires platform %s, but this is %s", c, pkg.Version)}}
+	}
+	return nil
+}
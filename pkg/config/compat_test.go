@@ -0,0 +1,57 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompatibility(t *testing.T) {
+	tests := []struct {
+		platform string
+		version  string
+		path     string
+		severity Severity
+	}{
+		{">=1.0.0-dev", "0.1.0-alpha", "", ""},
+		{"^1.0.0-dev", "1.2.0", "", ""},
+		{">=0.9.0 <2.0.0", "0.1.0", "", ""},
+		{">=1.0.0", "0.1.0", "platform_version", SeverityError},
+		{"^2.0", "0.1.0", "platform_version", SeverityError},
+		{">=1.0.0-", "0.1.0", "platform_version", SeverityError},
+	}
+	for _, tt := range tests {
+		app := &Application{PlatformVersion: tt.platform, Version: tt.version}
+		findings := app.Compatibility()
+		if tt.path == "" {
+			if len(findings) > 0 {
+				t.Errorf("%s %s: unexpected %v", tt.platform, tt.version, findings)
+			}
+			continue
+		}
+		if len(findings) != 1 || findings[0].Path != tt.path || findings[0].Severity != tt.severity {
+			t.Errorf("%s %s: got %v, want a %s at %s", tt.platform, tt.version, findings, tt.severity, tt.path)
+		}
+	}
+}
+
+func TestLoadApplicationRefusesNewerPlatform(t *testing.T) {
+	app := strings.Replace(typoApp, `platform_version: ">=1.0.0-dev"`, `platform_version: ">=9.0.0"`, 1)
+	_, err := LoadApplication(writeApp(t, app))
+	if err == nil || !strings.Contains(err.Error(), "line 3: platform_version: requires platform >=9.0.0") {
+		t.Errorf("LoadApplication error = %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvironmentConfig is an environment file, the document an Environment's
+// Config names. Unset fields fall back to the application defaults: the
+// first variant and architecture, and the highest priority queue that runs
+// the architecture.
+type EnvironmentConfig struct {
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description"`
+	Variant     string             `yaml:"variant,omitempty"`
+	Compute     EnvironmentCompute `yaml:"compute"`
+	Storage     EnvironmentStorage `yaml:"storage"`
+	Runtime     EnvironmentRuntime `yaml:"runtime"`
+	Parameters  map[string]string  `yaml:"parameters"` // simulation parameters, passed to the entrypoint as --param name=value
+}
+
+// EnvironmentCompute defines the resources of an environment's jobs
+type EnvironmentCompute struct {
+	Architecture string `yaml:"architecture"`
+	VCPUs        int    `yaml:"vcpus"`
+	MemoryMB     int    `yaml:"memory_mb"`
+	Queue        string `yaml:"queue"` // preferred job queue
+}
+
+// EnvironmentStorage defines where an environment's jobs read and write data
+type EnvironmentStorage struct {
+	Input  string `yaml:"input"` // S3 prefix of the input data
+	Output string `yaml:"output"`
+}
+
+// EnvironmentRuntime defines limits on an environment's jobs
+type EnvironmentRuntime struct {
+	TimeoutHours  float64 `yaml:"timeout_hours"` // walltime of each attempt
+	RetryAttempts int     `yaml:"retry_attempts"`
+}
+
+// LoadEnvironmentConfig loads an environment file. Unknown fields are
+// errors, as in app.yaml.
+func LoadEnvironmentConfig(path string) (*EnvironmentConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environment file: %w", err)
+	}
+	defer f.Close()
+
+	var env EnvironmentConfig
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&env); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return &env, nil
+}
+
+// CheckEnvironments loads the environment file of each environment and
+// checks it against the application. Problems are reported at the
+// environment's config in app.yaml. Applications not loaded from a
+// directory are not checked.
+func (a *Application) CheckEnvironments() []Finding {
+	if a.Dir == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for i, env := range a.Environments {
+		if env.Config == "" {
+			continue
+		}
+		path := fmt.Sprintf("environments[%d].config", i)
+		add := func(severity Severity, format string, args ...interface{}) {
+			findings = append(findings, Finding{Path: path, Severity: severity,
+				Message: env.Config + ": " + fmt.Sprintf(format, args...)})
+		}
+
+		e, err := LoadEnvironmentConfig(filepath.Join(a.Dir, env.Config))
+		if err != nil {
+			findings = append(findings, Finding{Path: path, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		if e.Name != "" && e.Name != env.Name {
+			add(SeverityWarning, "names environment %s, not %s", e.Name, env.Name)
+		}
+		if e.Variant != "" {
+			if _, err := a.GetVariant(e.Variant); err != nil {
+				add(SeverityError, "variant: %v", err)
+			}
+		}
+
+		arch := e.Compute.Architecture
+		if arch != "" {
+			if _, err := a.GetArchitecture(arch); err != nil {
+				add(SeverityError, "compute.architecture: %v", err)
+			}
+		} else if len(a.Compute.Architectures) > 0 {
+			arch = a.Compute.Architectures[0].Name
+		}
+		if q := e.Compute.Queue; q != "" {
+			if queue := a.GetQueue(q); queue == nil {
+				add(SeverityError, "compute.queue: queue %s not found", q)
+			} else if !queue.Runs(arch) {
+				add(SeverityError, "compute.queue: queue %s does not run architecture %s", q, arch)
+			}
+		}
+
+		if e.Compute.VCPUs < 0 {
+			add(SeverityError, "compute.vcpus: must not be negative")
+		}
+		if e.Compute.MemoryMB < 0 {
+			add(SeverityError, "compute.memory_mb: must not be negative")
+		}
+		if e.Runtime.TimeoutHours < 0 {
+			add(SeverityError, "runtime.timeout_hours: must not be negative")
+		} else if e.Runtime.TimeoutHours > 0 && e.Runtime.TimeoutHours*3600 < 60 {
+			add(SeverityError, "runtime.timeout_hours: AWS Batch timeouts are at least 60 seconds")
+		}
+		if n := e.Runtime.RetryAttempts; n < 0 || n > 10 {
+			add(SeverityError, "runtime.retry_attempts: must be between 1 and 10")
+		}
+	}
+	return findings
+}
+
+// GetQueue returns the job queue by name, or nil
+func (a *Application) GetQueue(name string) *Queue {
+	for i := range a.Compute.Batch.Queues {
+		if a.Compute.Batch.Queues[i].Name == name {
+			return &a.Compute.Batch.Queues[i]
+		}
+	}
+	return nil
+}
+
+// Runs reports whether one of the queue's compute environments runs the
+// architecture
+func (q *Queue) Runs(arch string) bool {
+	for _, ce := range q.ComputeEnvironments {
+		if contains(ce.Architectures, arch) {
+			return true
+		}
+	}
+	return false
+}
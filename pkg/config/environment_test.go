@@ -0,0 +1,119 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetEnvironment(t *testing.T) {
+	app, err := LoadApplication(filepath.Join("..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := app.GetEnvironment("benchmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := env.Settings
+	if s.Variant != "classic" || s.Compute.Architecture != "c7a" || s.Compute.VCPUs != 16 ||
+		s.Storage.Input == "" || s.Runtime.TimeoutHours != 6 || s.Parameters["simulation"] != "fullchem" {
+		t.Errorf("benchmark settings = %+v", s)
+	}
+	if _, err := app.GetEnvironment("nightly"); err == nil {
+		t.Error("expected an error for an environment not in app.yaml")
+	}
+}
+
+func TestCheckEnvironments(t *testing.T) {
+	app := strings.Replace(minimalApp, "%s", "envs", 1) + `    - name: "graviton4"
+      family: "arm"
+      instance_types: ["c8g.xlarge"]
+      base_image: "hpc-base-arm-neoverse-v2:latest"
+  batch:
+    queues:
+      - name: "arm"
+        priority: 1
+        compute_environments:
+          - type: "spot"
+            architectures: ["graviton4"]
+environments:
+  - name: "good"
+    config: "environments/good.yaml"
+  - name: "bad"
+    config: "environments/bad.yaml"
+  - name: "typo"
+    config: "environments/typo.yaml"
+  - name: "missing"
+    config: "environments/missing.yaml"
+  - name: "inline"
+`
+	dir := writeApp(t, app)
+	files := map[string]string{
+		"good.yaml": "name: good\ncompute:\n  vcpus: 4\nparameters:\n  days: 7\n",
+		"bad.yaml": `name: other
+variant: gchp
+compute:
+  architecture: c7a
+  queue: arm
+runtime:
+  retry_attempts: 11
+`,
+		"typo.yaml": "compute:\n  cpus: 4\n",
+	}
+	if err := os.Mkdir(filepath.Join(dir, "environments"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, "environments", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, report, err := CheckApplication(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, string(f.Severity)+" "+f.Path+": "+f.Message)
+	}
+	want := []string{
+		"warning environments[1].config: environments/bad.yaml: names environment other, not bad",
+		"error environments[1].config: environments/bad.yaml: variant: variant gchp not found",
+		"error environments[1].config: environments/bad.yaml: compute.queue: queue arm does not run architecture c7a",
+		"error environments[1].config: environments/bad.yaml: runtime.retry_attempts: must be between 1 and 10",
+		"error environments[2].config: failed to parse typo.yaml: yaml: unmarshal errors:\n  line 2: field cpus not found in type config.EnvironmentCompute",
+		"error environments[3].config: failed to read environment file: open " + filepath.Join(dir, "environments", "missing.yaml") + ": no such file or directory",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	good, err := a.GetEnvironment("good")
+	if err != nil || good.Settings.Compute.VCPUs != 4 || good.Settings.Parameters["days"] != "7" {
+		t.Errorf("good = %+v, %v", good, err)
+	}
+	inline, err := a.GetEnvironment("inline")
+	if err != nil || inline.Settings == nil || inline.Settings.Name != "inline" {
+		t.Errorf("inline = %+v, %v", inline, err)
+	}
+	if _, err := LoadApplication(dir); err == nil {
+		t.Error("expected invalid environment files to fail loading")
+	}
+}
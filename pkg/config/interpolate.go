@@ -0,0 +1,279 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws-hpc/platform/pkg/command"
+	"gopkg.in/yaml.v3"
+)
+
+// Resolver resolves the references in app.yaml values when it is loaded:
+//
+//	${NAME}            environment variable NAME
+//	${NAME:-default}   environment variable NAME, or default if unset or empty
+//	${account_id}      AWS account ID
+//	${region}          AWS region
+//	${secret:name}     secret value, redacted wherever the spec is printed
+//
+// $${ is a literal ${. Install commands are shell, run when the container
+// is built, so references in them are left for the shell. A reference a
+// Resolver cannot resolve is an error.
+type Resolver struct {
+	Env       func(name string) (string, bool)
+	AccountID func() (string, error)
+	Region    func() (string, error)
+	Secret    func(name string) (string, error)
+}
+
+// DefaultResolver resolves the references of every app.yaml loaded.
+// Commands that can reach AWS replace it with AWSResolver.
+var DefaultResolver = EnvResolver()
+
+// EnvResolver resolves references from the environment only: the region
+// from AWS_REGION or AWS_DEFAULT_REGION. Account IDs and secrets cannot be
+// resolved.
+func EnvResolver() *Resolver {
+	return &Resolver{
+		Env: os.LookupEnv,
+		Region: func() (string, error) {
+			for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+				if region := os.Getenv(name); region != "" {
+					return region, nil
+				}
+			}
+			return "", fmt.Errorf("no AWS region; set AWS_REGION")
+		},
+	}
+}
+
+// AWSResolver resolves references from the environment and, through the
+// aws CLI, the account ID of the current credentials, their configured
+// region and secrets in AWS Secrets Manager. Lookups are made once.
+func AWSResolver(r command.Runner) *Resolver {
+	env := EnvResolver()
+	aws := func(args ...string) (string, error) {
+		out, err := command.Output(context.Background(), r, "aws", args...)
+		return strings.TrimSpace(string(out)), err
+	}
+
+	var mu sync.Mutex
+	cache := map[string]string{}
+	cached := func(key string, lookup func() (string, error)) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if v, ok := cache[key]; ok {
+			return v, nil
+		}
+		v, err := lookup()
+		if err != nil {
+			return "", err
+		}
+		cache[key] = v
+		return v, nil
+	}
+
+	return &Resolver{
+		Env: env.Env,
+		AccountID: func() (string, error) {
+			return cached("account_id", func() (string, error) {
+				id, err := aws("sts", "get-caller-identity", "--query", "Account", "--output", "text")
+				if err != nil {
+					return "", fmt.Errorf("failed to look up AWS account: %w", err)
+				}
+				return id, nil
+			})
+		},
+		Region: func() (string, error) {
+			return cached("region", func() (string, error) {
+				if region, err := env.Region(); err == nil {
+					return region, nil
+				}
+				region, err := aws("configure", "get", "region")
+				if err != nil || region == "" {
+					return "", fmt.Errorf("no AWS region; set AWS_REGION or configure one with aws configure")
+				}
+				return region, nil
+			})
+		},
+		Secret: func(name string) (string, error) {
+			return cached("secret:"+name, func() (string, error) {
+				args := []string{"secretsmanager", "get-secret-value", "--secret-id", name, "--query", "SecretString", "--output", "text"}
+				if region, err := env.Region(); err == nil {
+					args = append(args, "--region", region)
+				}
+				value, err := aws(args...)
+				if err != nil {
+					return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+				}
+				return value, nil
+			})
+		},
+	}
+}
+
+var (
+	reference   = regexp.MustCompile(`\$(\$?)\{([^}]*)\}`)
+	envVariable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	installPath = regexp.MustCompile(`^containers\.variants\.[^.]+\.install$`)
+)
+
+// interpolate resolves the references in every value of a document and
+// returns the secrets used. References that cannot be resolved are
+// findings.
+func (r *Resolver) interpolate(root *yaml.Node) (redactor, []Finding) {
+	var secrets redactor
+	var findings []Finding
+
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				walk(c, fmt.Sprintf("%s[%d]", path, i))
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				keyPath := n.Content[i].Value
+				if path != "" {
+					keyPath = path + "." + keyPath
+				}
+				if !installPath.MatchString(keyPath) {
+					walk(n.Content[i+1], keyPath)
+				}
+			}
+		case yaml.ScalarNode:
+			if !strings.Contains(n.Value, "${") {
+				return
+			}
+			n.Value = reference.ReplaceAllStringFunc(n.Value, func(ref string) string {
+				m := reference.FindStringSubmatch(ref)
+				if m[1] != "" {
+					return ref[1:]
+				}
+				value, name, err := r.resolve(m[2])
+				if err != nil {
+					findings = append(findings, Finding{Path: path, Severity: SeverityError,
+						Message: fmt.Sprintf("cannot resolve %s: %v", ref, err)})
+					return ref
+				}
+				if name != "" {
+					secrets = append(secrets, secret{name: name, value: value})
+				}
+				return value
+			})
+			// Let the resolved value decide the type, so that
+			// max_vcpus: ${MAX_VCPUS} is an integer
+			if n.Style == 0 {
+				n.Tag = ""
+			}
+		}
+	}
+	walk(root, "")
+
+	sort.SliceStable(secrets, func(i, j int) bool { return len(secrets[i].value) > len(secrets[j].value) })
+	return secrets, findings
+}
+
+// resolve returns the value of a reference, and the secret's name if it is
+// one
+func (r *Resolver) resolve(expr string) (value, secretName string, err error) {
+	unsupported := func(what string) (string, string, error) {
+		return "", "", fmt.Errorf("%s cannot be resolved here", what)
+	}
+
+	switch {
+	case expr == "account_id":
+		if r.AccountID == nil {
+			return unsupported("the AWS account ID")
+		}
+		value, err = r.AccountID()
+		return value, "", err
+	case expr == "region":
+		if r.Region == nil {
+			return unsupported("the AWS region")
+		}
+		value, err = r.Region()
+		return value, "", err
+	case strings.HasPrefix(expr, "secret:"):
+		name := strings.TrimPrefix(expr, "secret:")
+		if name == "" {
+			return "", "", fmt.Errorf("secret name is empty")
+		}
+		if r.Secret == nil {
+			return unsupported("secrets")
+		}
+		value, err = r.Secret(name)
+		return value, name, err
+	}
+
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	if !envVariable.MatchString(name) {
+		return "", "", fmt.Errorf("unknown reference; use ${NAME}, ${NAME:-default}, ${account_id}, ${region} or ${secret:name}")
+	}
+	if r.Env != nil {
+		if value, ok := r.Env(name); ok && (value != "" || !hasDefault) {
+			return value, "", nil
+		}
+	}
+	if hasDefault {
+		return def, "", nil
+	}
+	return "", "", fmt.Errorf("environment variable %s is not set", name)
+}
+
+// secret is a resolved ${secret:name} reference
+type secret struct {
+	name  string
+	value string
+}
+
+// redactor replaces secret values with their references, longest first so
+// that a secret containing another is replaced whole
+type redactor []secret
+
+func (r redactor) redact(s string) string {
+	for _, sec := range r {
+		if sec.value == "" {
+			continue
+		}
+		ref := "${secret:" + sec.name + "}"
+		s = strings.ReplaceAll(s, sec.value, ref)
+		// Also as a JSON or quoted YAML string, for rendered templates
+		if quoted := strconv.Quote(sec.value); quoted[1:len(quoted)-1] != sec.value {
+			s = strings.ReplaceAll(s, quoted[1:len(quoted)-1], ref)
+		}
+	}
+	return s
+}
+
+// Redact replaces the values of the secrets app.yaml references with their
+// ${secret:name} references. Anything printed or written from the spec
+// goes through it.
+func (a *Application) Redact(s string) string {
+	return a.secrets.redact(s)
+}
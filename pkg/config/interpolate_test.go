@@ -0,0 +1,151 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// useResolver replaces DefaultResolver for a test
+func useResolver(t *testing.T, env map[string]string, secrets map[string]string) {
+	t.Helper()
+	saved := DefaultResolver
+	t.Cleanup(func() { DefaultResolver = saved })
+	DefaultResolver = &Resolver{
+		Env: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+		AccountID: func() (string, error) { return "123456789012", nil },
+		Region:    func() (string, error) { return "us-west-2", nil },
+		Secret: func(name string) (string, error) {
+			if v, ok := secrets[name]; ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("secret %s not found", name)
+		},
+	}
+}
+
+const referenceOverlay = `storage:
+  output:
+    bucket: "${RESULTS_BUCKET:-results}-${account_id}-${region}"
+compute:
+  batch:
+    max_vcpus: ${MAX_VCPUS}
+licensing:
+  server: "${secret:lab/license-server}"
+containers:
+  variants:
+    serial:
+      install:
+        - "make -j${NPROC} install PREFIX=${APP_ROOT}"
+`
+
+func TestInterpolate(t *testing.T) {
+	useResolver(t, map[string]string{"MAX_VCPUS": "64", "RESULTS_BUCKET": ""},
+		map[string]string{"lab/license-server": "27000@flexlm.internal"})
+	dir := writeApp(t, overlayBase)
+	overlay := writeOverlay(t, dir, "app.lab.yaml", referenceOverlay)
+
+	app, report, err := CheckApplication(dir, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Errors() > 0 {
+		t.Fatalf("unexpected findings: %v", report.Findings)
+	}
+	if got := app.Storage.Output.Bucket; got != "results-123456789012-us-west-2" {
+		t.Errorf("bucket = %s", got)
+	}
+	if app.Compute.Batch.MaxVCPUs != 64 {
+		t.Errorf("max_vcpus = %d, want 64", app.Compute.Batch.MaxVCPUs)
+	}
+	if got := app.Licensing.Server; got != "27000@flexlm.internal" {
+		t.Errorf("license server = %s", got)
+	}
+	if got := app.Containers.Variants["serial"].Install[0]; got != "make -j${NPROC} install PREFIX=${APP_ROOT}" {
+		t.Errorf("install command = %s, want it left for the shell", got)
+	}
+
+	if got := app.Redact("server " + app.Licensing.Server); got != "server ${secret:lab/license-server}" {
+		t.Errorf("Redact = %s", got)
+	}
+	data, err := report.Effective()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "flexlm") || !strings.Contains(string(data), "${secret:lab/license-server}") {
+		t.Errorf("effective spec does not redact the secret:\n%s", data)
+	}
+}
+
+func TestInterpolateFindings(t *testing.T) {
+	useResolver(t, nil, map[string]string{"lab/token": "s3cr3t"})
+	dir := writeApp(t, overlayBase)
+	overlay := writeOverlay(t, dir, "app.lab.yaml", `storage:
+  output:
+    bucket: "${RESULTS_BUCKET}"
+    prefix: "$${literal}/${secret:lab/token}"
+compute:
+  batch:
+    max_vcpus: ${MAX_VCPUS:-many}
+licensing:
+  server: "${secret:lab/missing}"
+  feature: "${not a reference}"
+`)
+
+	app, report, err := CheckApplication(dir, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := app.Storage.Output.Prefix; got != "${literal}/s3cr3t" {
+		t.Errorf("prefix = %s", got)
+	}
+
+	var got []string
+	for _, f := range report.Findings {
+		if strings.Contains(f.Message, "s3cr3t") {
+			t.Errorf("finding leaks a secret: %s", f.Message)
+		}
+		got = append(got, report.Location(f)+": "+f.String())
+	}
+	want := []string{
+		overlay + ":3:5: error: storage.output.bucket: cannot resolve ${RESULTS_BUCKET}: environment variable RESULTS_BUCKET is not set",
+		overlay + ":7:5: error: compute.batch.max_vcpus: must be an integer, not \"many\"",
+		overlay + ":9:3: error: licensing.server: cannot resolve ${secret:lab/missing}: secret lab/missing not found",
+		overlay + ":10:3: error: licensing.feature: cannot resolve ${not a reference}: unknown reference; use ${NAME}, ${NAME:-default}, ${account_id}, ${region} or ${secret:name}",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEnvResolver(t *testing.T) {
+	r := EnvResolver()
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	if region, _, err := r.resolve("region"); err != nil || region != "eu-west-1" {
+		t.Errorf("region = %q, %v", region, err)
+	}
+	if _, _, err := r.resolve("account_id"); err == nil {
+		t.Error("expected an error resolving the account ID from the environment")
+	}
+	if _, _, err := r.resolve("secret:x"); err == nil {
+		t.Error("expected an error resolving a secret from the environment")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg"
+	"gopkg.in/yaml.v3"
+)
+
+// LegacySpecVersion is the spec version of app.yaml files written before
+// spec_version existed
+const LegacySpecVersion = "0.1.0"
+
+// Migration upgrades an app.yaml document to the next spec version
+type Migration struct {
+	To          string // spec version of the document after the migration
+	Description string
+	Apply       func(doc *yaml.Node) error // doc is the top-level mapping
+}
+
+// Migrations are the spec version upgrades, oldest first. The last one is
+// to pkg.AppSpecVersion.
+var Migrations = []Migration{
+	{
+		To:          "0.2.0",
+		Description: "storage.scratch: the EBS volume type moves from a second type key to volume_type",
+		Apply:       migrateScratchVolumeType,
+	},
+}
+
+// SpecVersion returns the spec_version of a parsed app.yaml document
+func SpecVersion(root *yaml.Node) string {
+	if v := mappingValue(document(root), "spec_version"); v != nil && v.Value != "" {
+		return v.Value
+	}
+	return LegacySpecVersion
+}
+
+// Migrate upgrades a parsed app.yaml document in place, one spec version
+// at a time, to pkg.AppSpecVersion, and returns the migrations applied.
+// Documents newer than the platform, or older than pkg.MinAppSpecVersion,
+// cannot be migrated.
+func Migrate(root *yaml.Node) ([]Migration, error) {
+	doc := document(root)
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("app.yaml is not a mapping")
+	}
+
+	from := SpecVersion(root)
+	v, err := pkg.ParseSemver(from)
+	if err != nil {
+		return nil, err
+	}
+	current, _ := pkg.ParseSemver(pkg.AppSpecVersion)
+	min, _ := pkg.ParseSemver(pkg.MinAppSpecVersion)
+	switch {
+	case v.Compare(current) > 0:
+		return nil, fmt.Errorf("spec %s is newer than %s, the newest this platform reads; upgrade aws-hpc", from, pkg.AppSpecVersion)
+	case v.Compare(min) < 0:
+		return nil, fmt.Errorf("spec %s is older than %s, the oldest this platform migrates", from, pkg.MinAppSpecVersion)
+	case v.Compare(current) == 0:
+		return nil, nil
+	}
+
+	var applied []Migration
+	for _, m := range Migrations {
+		if to, _ := pkg.ParseSemver(m.To); to.Compare(v) <= 0 {
+			continue
+		}
+		if err := m.Apply(doc); err != nil {
+			return applied, fmt.Errorf("failed to migrate to spec %s: %w", m.To, err)
+		}
+		applied = append(applied, m)
+	}
+	setSpecVersion(doc, pkg.AppSpecVersion)
+	return applied, nil
+}
+
+// MigrateDocument migrates app.yaml content to pkg.AppSpecVersion. Comments
+// and blank lines are kept; the rest of the layout is re-encoded with
+// two-space indentation. The content is returned unchanged if it is
+// already current.
+func MigrateDocument(data []byte) ([]byte, []Migration, error) {
+	root, err := parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse app.yaml: %w", err)
+	}
+	if SpecVersion(root) == pkg.AppSpecVersion {
+		return data, nil, nil
+	}
+	applied, err := Migrate(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := encode(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return restoreBlankLines(data, out), applied, nil
+}
+
+// encode encodes a parsed document with two-space indentation
+func encode(root *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to encode app.yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode app.yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// migrateScratchVolumeType fixes scratch storage written when Type and
+// VolumeType were both decoded from the type key:
+//
+//	scratch:              scratch:
+//	  type: "ebs"    =>     type: "ebs"
+//	  type: "gp3"           volume_type: "gp3"
+func migrateScratchVolumeType(doc *yaml.Node) error {
+	scratch := mappingValue(mappingValue(doc, "storage"), "scratch")
+	if scratch == nil || scratch.Kind != yaml.MappingNode || mappingValue(scratch, "volume_type") != nil {
+		return nil
+	}
+
+	var volume *yaml.Node
+	hasKind := false
+	for i := 0; i+1 < len(scratch.Content); i += 2 {
+		key, value := scratch.Content[i], scratch.Content[i+1]
+		if key.Value != "type" {
+			continue
+		}
+		if volume == nil && contains(VolumeTypes, value.Value) {
+			key.Value = "volume_type"
+			volume = key
+		} else {
+			hasKind = true
+		}
+	}
+	if volume != nil && !hasKind {
+		// Only EBS volumes have a volume type
+		insertMapping(scratch, 0, "type", "ebs")
+	}
+	return nil
+}
+
+// document returns the top-level node of a parsed document
+func document(root *yaml.Node) *yaml.Node {
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		return root.Content[0]
+	}
+	return root
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// insertMapping inserts a quoted string entry as the i-th entry of a mapping
+func insertMapping(m *yaml.Node, i int, key, value string) {
+	entry := []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle},
+	}
+	m.Content = append(m.Content[:2*i], append(entry, m.Content[2*i:]...)...)
+}
+
+// setSpecVersion sets spec_version, adding it after platform_version if the
+// document does not have one
+func setSpecVersion(doc *yaml.Node, version string) {
+	if v := mappingValue(doc, "spec_version"); v != nil {
+		v.Value = version
+		return
+	}
+	at := 0
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		switch doc.Content[i].Value {
+		case "name", "display_name", "version", "platform_version":
+			at = i/2 + 1
+		}
+	}
+	insertMapping(doc, at, "spec_version", version)
+}
+
+// restoreBlankLines puts the blank lines of the original document back
+// into its re-encoded form, which yaml.v3 drops. Lines are aligned by
+// their longest common subsequence, as a diff would; a blank original line
+// between two aligned lines is kept there, after any lines added at that
+// point.
+func restoreBlankLines(original, encoded []byte) []byte {
+	a := strings.Split(strings.TrimSuffix(string(original), "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(string(encoded), "\n"), "\n")
+
+	// lcs[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]):
+			out = append(out, b[j])
+			i, j = i+1, j+1
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j] ||
+			lcs[i][j+1] == lcs[i+1][j] && strings.TrimSpace(a[i]) == ""):
+			out = append(out, b[j])
+			j++
+		default:
+			if strings.TrimSpace(a[i]) == "" {
+				out = append(out, "")
+			}
+			i++
+		}
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
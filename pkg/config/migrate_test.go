@@ -0,0 +1,134 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg"
+)
+
+const legacyApp = `# My application
+name: "myapp"
+version: "0.1.0"
+platform_version: ">=1.0.0-dev"
+
+# Storage requirements
+storage:
+  scratch:
+    type: "ebs"
+    size_gb: 100
+    type: "gp3" # fast
+    iops: 3000
+`
+
+const migratedApp = `# My application
+name: "myapp"
+version: "0.1.0"
+platform_version: ">=1.0.0-dev"
+spec_version: "0.2.0"
+
+# Storage requirements
+storage:
+  scratch:
+    type: "ebs"
+    size_gb: 100
+    volume_type: "gp3" # fast
+    iops: 3000
+`
+
+func TestMigrateDocument(t *testing.T) {
+	out, applied, err := MigrateDocument([]byte(legacyApp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != migratedApp {
+		t.Errorf("migrated app.yaml:\n%s\nwant:\n%s", out, migratedApp)
+	}
+	if len(applied) != 1 || applied[0].To != "0.2.0" {
+		t.Errorf("applied %v", applied)
+	}
+
+	// Migrating again changes nothing
+	again, applied, err := MigrateDocument(out)
+	if err != nil || string(again) != migratedApp || len(applied) != 0 {
+		t.Errorf("second migration: %d migrations, err %v, changed %v", len(applied), err, string(again) != migratedApp)
+	}
+}
+
+func TestMigrateScratchWithoutKind(t *testing.T) {
+	doc := strings.Replace(legacyApp, "    type: \"ebs\"\n", "", 1)
+	out, _, err := MigrateDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "  scratch:\n    type: \"ebs\"\n    size_gb: 100\n    volume_type: \"gp3\"") {
+		t.Errorf("scratch storage not given an ebs type:\n%s", out)
+	}
+}
+
+func TestMigrateRefusesUnknownSpecs(t *testing.T) {
+	for _, version := range []string{"9.0.0", "0.0.1", "two"} {
+		doc := strings.Replace(migratedApp, `spec_version: "0.2.0"`, `spec_version: "`+version+`"`, 1)
+		if _, _, err := MigrateDocument([]byte(doc)); err == nil {
+			t.Errorf("spec %s migrated", version)
+		}
+	}
+}
+
+func TestMigrationsReachCurrentSpec(t *testing.T) {
+	prev, _ := pkg.ParseSemver(LegacySpecVersion)
+	for _, m := range Migrations {
+		to, err := pkg.ParseSemver(m.To)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if to.Compare(prev) <= 0 {
+			t.Errorf("migration to %s is out of order", m.To)
+		}
+		prev = to
+	}
+	if prev.String() != pkg.AppSpecVersion {
+		t.Errorf("migrations end at %s, want %s", prev, pkg.AppSpecVersion)
+	}
+}
+
+func TestCheckMigratesInMemory(t *testing.T) {
+	app, report, err := CheckApplication("../../applications/geos-chem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.SpecVersion != pkg.AppSpecVersion {
+		t.Errorf("geos-chem is at spec %s, want %s", app.SpecVersion, pkg.AppSpecVersion)
+	}
+	for _, f := range report.Findings {
+		if f.Path == "spec_version" {
+			t.Errorf("%s: %s", report.Location(f), f)
+		}
+	}
+
+	_, report, err = CheckApplication(writeApp(t, legacyApp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Findings {
+		switch {
+		case f.Path == "spec_version" && f.Severity == SeverityWarning:
+		case strings.HasPrefix(f.Path, "storage."):
+			t.Errorf("legacy scratch storage not migrated: %s", f)
+		}
+	}
+}
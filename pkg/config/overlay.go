@@ -0,0 +1,214 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SiteEnv names the site whose overlay, app.<site>.yaml in the application
+// directory, is merged over app.yaml
+const SiteEnv = "AWS_HPC_SITE"
+
+// Overlay tags
+const (
+	ReplaceTag = "!replace" // replace a mapping or list instead of merging into it
+	DeleteTag  = "!delete"  // remove a mapping key or a named list item
+)
+
+// SiteOverlay returns the path of a site's overlay in an application
+// directory
+func SiteOverlay(dir, site string) string {
+	return filepath.Join(dir, "app."+site+".yaml")
+}
+
+// Effective returns the specification the report is for as YAML: app.yaml
+// migrated to the current spec version, with any overlays merged over it
+// and references resolved. Secrets are redacted.
+func (r *Report) Effective() ([]byte, error) {
+	data, err := encode(r.root)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(r.secrets.redact(string(data))), nil
+}
+
+// An overlay is a partial app.yaml merged over the base spec, after the
+// base is migrated to the current spec version:
+//
+//   - mappings merge key by key, keeping the base's order and appending
+//     new keys
+//   - lists of mappings with a name, such as variants, architectures and
+//     queues, merge item by item: an item named like a base item merges
+//     into it, and other items are appended
+//   - other lists, such as instance_types, and scalars replace the base
+//     value
+//   - a value tagged !replace replaces the base value instead of merging
+//   - a mapping key or named list item tagged !delete removes it from the
+//     base, as in "- !delete {name: c5a}"
+//
+// Overlays are written against the current spec and cannot set
+// spec_version.
+type overlay struct {
+	file     string
+	origin   map[*yaml.Node]string // file of each node taken from an overlay
+	findings []Finding
+}
+
+// applyOverlay reads an overlay file and merges it over the document. The
+// error is for files that cannot be read or parsed at all; problems with
+// the overlay's content are findings.
+func applyOverlay(root *yaml.Node, file string, origin map[*yaml.Node]string) ([]Finding, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay: %w", err)
+	}
+	over, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+	}
+
+	o := &overlay{file: file, origin: origin}
+	for _, f := range duplicateKeys(over) {
+		f.File = file
+		o.findings = append(o.findings, f)
+	}
+
+	doc := document(over)
+	if doc.Kind != yaml.MappingNode {
+		o.add(doc, "overlay is not a mapping")
+		return o.findings, nil
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if key := doc.Content[i]; key.Value == "spec_version" {
+			o.add(key, "overlays are written against the current spec and cannot set spec_version")
+			doc.Content = append(doc.Content[:i], doc.Content[i+2:]...)
+			break
+		}
+	}
+	if base := document(root); base.Kind == yaml.MappingNode {
+		o.mergeMapping(base, doc)
+	}
+	return o.findings, nil
+}
+
+func (o *overlay) add(n *yaml.Node, format string, args ...interface{}) {
+	o.findings = append(o.findings, Finding{File: o.file, Line: n.Line, Column: n.Column,
+		Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// merge merges an overlay node over a base node and returns the result,
+// which is over itself if it replaces base
+func (o *overlay) merge(base, over *yaml.Node) *yaml.Node {
+	switch {
+	case over.Tag == ReplaceTag:
+		// take drops the tag
+	case base.Kind == yaml.MappingNode && over.Kind == yaml.MappingNode:
+		o.mergeMapping(base, over)
+		return base
+	case base.Kind == yaml.SequenceNode && over.Kind == yaml.SequenceNode && named(base) && named(over):
+		o.mergeList(base, over)
+		return base
+	}
+	o.take(over)
+	return over
+}
+
+func (o *overlay) mergeMapping(base, over *yaml.Node) {
+	for i := 0; i+1 < len(over.Content); i += 2 {
+		key, value := over.Content[i], over.Content[i+1]
+		j := -1
+		for k := 0; k+1 < len(base.Content); k += 2 {
+			if base.Content[k].Value == key.Value {
+				j = k
+				break
+			}
+		}
+
+		switch {
+		case value.Tag == DeleteTag && j < 0:
+			o.add(key, "cannot delete %s, which is not set", key.Value)
+		case value.Tag == DeleteTag:
+			base.Content = append(base.Content[:j], base.Content[j+2:]...)
+		case j < 0:
+			o.take(key)
+			base.Content = append(base.Content, key, o.merge(&yaml.Node{}, value))
+		default:
+			merged := o.merge(base.Content[j+1], value)
+			if merged == value {
+				// Report problems with the new value in the overlay
+				o.take(key)
+				base.Content[j] = key
+			}
+			base.Content[j+1] = merged
+		}
+	}
+}
+
+func (o *overlay) mergeList(base, over *yaml.Node) {
+	for _, item := range over.Content {
+		name := mappingValue(item, "name").Value
+		j := -1
+		for k, b := range base.Content {
+			if mappingValue(b, "name").Value == name {
+				j = k
+				break
+			}
+		}
+
+		switch {
+		case item.Tag == DeleteTag && j < 0:
+			o.add(item, "cannot delete %s, which is not in the list", name)
+		case item.Tag == DeleteTag:
+			base.Content = append(base.Content[:j], base.Content[j+1:]...)
+		case j < 0:
+			base.Content = append(base.Content, o.merge(&yaml.Node{}, item))
+		default:
+			base.Content[j] = o.merge(base.Content[j], item)
+		}
+	}
+}
+
+// take records a node and its children as coming from the overlay. Tags
+// with no meaning where they are, such as !delete in a new mapping, are
+// findings.
+func (o *overlay) take(n *yaml.Node) {
+	switch n.Tag {
+	case ReplaceTag:
+		n.Tag = ""
+	case DeleteTag:
+		o.add(n, "!delete only applies to a key or named list item the base spec has")
+		n.Tag = ""
+	}
+	o.origin[n] = o.file
+	for _, c := range n.Content {
+		o.take(c)
+	}
+}
+
+// named reports whether a list is of mappings with a scalar name, which
+// overlays merge by name
+func named(list *yaml.Node) bool {
+	for _, item := range list.Content {
+		if name := mappingValue(item, "name"); name == nil || name.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const overlayBase = `name: "overlay"
+version: "1.0.0"
+platform_version: ">=1.0.0-dev"
+spec_version: "0.2.0"
+variants:
+  - name: "serial"
+compute:
+  architectures:
+    - name: "c7a"
+      family: "amd"
+      instance_types: ["c7a.xlarge", "c7a.2xlarge"]
+      base_image: "hpc-base-amd-zen4:latest"
+    - name: "c6a"
+      family: "amd"
+      instance_types: ["c6a.xlarge"]
+      base_image: "hpc-base-amd-zen3:latest"
+  batch:
+    max_vcpus: 1000
+    queues:
+      - name: "spot"
+        priority: 1
+        compute_environments:
+          - type: "spot"
+            architectures: ["c7a", "c6a"]
+            max_vcpus: 500
+storage:
+  output:
+    type: "s3"
+    bucket: "results"
+    lifecycle:
+      expiration: 365
+`
+
+func writeOverlay(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestOverlays(t *testing.T) {
+	dir := writeApp(t, overlayBase)
+	site := writeOverlay(t, dir, "app.lab.yaml", `compute:
+  architectures:
+    - !delete {name: "c6a"}
+    - name: "c7a"
+      instance_types: ["c7a.8xlarge"]
+    - name: "graviton4"
+      family: "arm"
+      instance_types: ["c8g.xlarge"]
+      base_image: "hpc-base-arm-neoverse-v2:latest"
+  batch:
+    max_vcpus: 256
+    queues:
+      - name: "spot"
+        compute_environments: !replace
+          - type: "on-demand"
+            architectures: ["c7a"]
+            max_vcpus: 64
+storage:
+  output:
+    bucket: "lab-results"
+    lifecycle: !delete
+`)
+	dev := writeOverlay(t, dir, "dev.yaml", "compute:\n  batch:\n    max_vcpus: 16\n")
+
+	app, err := LoadApplication(dir, site, dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var archs []string
+	for _, a := range app.Compute.Architectures {
+		archs = append(archs, a.Name+"="+strings.Join(a.InstanceTypes, ","))
+	}
+	if got := strings.Join(archs, " "); got != "c7a=c7a.8xlarge graviton4=c8g.xlarge" {
+		t.Errorf("architectures = %s", got)
+	}
+	if app.Compute.Architectures[0].BaseImage != "hpc-base-amd-zen4:latest" {
+		t.Error("merging c7a lost its base image")
+	}
+	q := app.Compute.Batch.Queues[0]
+	if q.Priority != 1 || len(q.ComputeEnvironments) != 1 || q.ComputeEnvironments[0].Type != "on-demand" {
+		t.Errorf("queue = %+v", q)
+	}
+	if app.Compute.Batch.MaxVCPUs != 16 {
+		t.Errorf("max_vcpus = %d, want the last overlay's 16", app.Compute.Batch.MaxVCPUs)
+	}
+	if out := app.Storage.Output; out.Bucket != "lab-results" || out.Type != "s3" || out.Lifecycle != nil {
+		t.Errorf("output = %+v", out)
+	}
+}
+
+func TestOverlayFindings(t *testing.T) {
+	dir := writeApp(t, overlayBase)
+	overlay := writeOverlay(t, dir, "app.lab.yaml", `spec_version: "0.3.0"
+compute:
+  architectures:
+    - !delete {name: "c5a"}
+  batch:
+    max_vcpus: "lots"
+storage:
+  output:
+    bucket: "lab-results"
+    bucket: "lab-results-2"
+  scratch: !delete
+networking:
+  efa: true
+  typo: 1
+`)
+
+	_, report, err := CheckApplication(dir, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, report.Location(f)+": "+f.String())
+	}
+	want := []string{
+		overlay + ":1:1: error: overlays are written against the current spec and cannot set spec_version",
+		overlay + ":4:7: error: cannot delete c5a, which is not in the list",
+		overlay + ":6:5: error: compute.batch.max_vcpus: must be an integer, not \"lots\"",
+		overlay + ":10:5: error: storage.output.bucket: duplicate key bucket, first defined at line 9",
+		overlay + ":11:3: error: cannot delete scratch, which is not set",
+		overlay + ":14:3: error: networking.typo: unknown field typo",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	_, err = LoadApplication(dir, overlay)
+	if err == nil || !strings.Contains(err.Error(), "app.lab.yaml line 14: networking.typo: unknown field typo") {
+		t.Errorf("LoadApplication error = %v", err)
+	}
+	if _, _, err := CheckApplication(dir, filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing overlay")
+	}
+}
+
+func TestEffective(t *testing.T) {
+	dir := writeApp(t, overlayBase)
+	overlay := writeOverlay(t, dir, "app.lab.yaml", "storage:\n  output:\n    bucket: \"lab-results\"\n")
+	_, report, err := CheckApplication(dir, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := report.Effective()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(overlayBase, `bucket: "results"`, `bucket: "lab-results"`, 1)
+	if string(data) != want {
+		t.Errorf("effective spec:\n%s\nwant:\n%s", data, want)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AppPathEnv lists extra application directories, separated like PATH
+const AppPathEnv = "AWS_HPC_APP_PATH"
+
+// Registry discovers applications in a list of search paths. Each search
+// path is a directory of application directories, each with an app.yaml.
+// When two search paths have an application of the same name, the first
+// wins.
+type Registry struct {
+	Paths []string
+
+	// Check loads and validates each discovered application. It defaults
+	// to CheckApplication, which checks app.yaml alone.
+	Check func(dir string) (*Application, *Report, error)
+}
+
+// Entry is an application found by a registry, checked but not
+// necessarily valid
+type Entry struct {
+	Name   string // directory name, which commands refer to it by
+	Dir    string
+	App    *Application // nil if app.yaml could not be parsed
+	Report *Report
+	Err    error
+}
+
+// DefaultRegistry searches, in order, the directories in AWS_HPC_APP_PATH,
+// ~/.aws-hpc/apps and the applications directory of the repository
+// checkout containing the working directory or the aws-hpc executable
+func DefaultRegistry() *Registry {
+	r := &Registry{}
+	for _, dir := range filepath.SplitList(os.Getenv(AppPathEnv)) {
+		if dir != "" {
+			r.Paths = append(r.Paths, dir)
+		}
+	}
+	if home, err := HomeDir(); err == nil {
+		r.Paths = append(r.Paths, filepath.Join(home, "apps"))
+	}
+	if root := RepoRoot(); root != "" {
+		r.Paths = append(r.Paths, filepath.Join(root, "applications"))
+	}
+	return r
+}
+
+// RepoRoot returns the platform repository checkout containing the working
+// directory or the running executable, or "" outside a checkout. A
+// checkout is a directory with both applications and base-images.
+func RepoRoot() string {
+	var starts []string
+	if wd, err := os.Getwd(); err == nil {
+		starts = append(starts, wd)
+	}
+	if exe, err := os.Executable(); err == nil {
+		if exe, err := filepath.EvalSymlinks(exe); err == nil {
+			starts = append(starts, filepath.Dir(exe))
+		}
+	}
+
+	for _, dir := range starts {
+		for {
+			if isDir(filepath.Join(dir, "applications")) && isDir(filepath.Join(dir, "base-images")) {
+				return dir
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return ""
+}
+
+// Discover finds every application in the search paths, sorted by name.
+// Directories starting with _ or ., such as _template, are skipped.
+func (r *Registry) Discover() []*Entry {
+	seen := map[string]bool{}
+	var entries []*Entry
+	for _, path := range r.Paths {
+		matches, _ := filepath.Glob(filepath.Join(path, "*", "app.yaml"))
+		for _, m := range matches {
+			dir := filepath.Dir(m)
+			name := filepath.Base(dir)
+			if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			entry := &Entry{Name: name, Dir: dir}
+			check := r.Check
+			if check == nil {
+				check = func(dir string) (*Application, *Report, error) { return CheckApplication(dir) }
+			}
+			entry.App, entry.Report, entry.Err = check(dir)
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Find returns the directory of an application. The name may also be a
+// path to an application directory: one containing a separator, or a
+// directory in the working directory that no search path has.
+func (r *Registry) Find(name string) (string, error) {
+	if strings.ContainsRune(name, filepath.Separator) || strings.HasPrefix(name, ".") {
+		if _, err := os.Stat(filepath.Join(name, "app.yaml")); err != nil {
+			return "", fmt.Errorf("no app.yaml in %s", name)
+		}
+		return name, nil
+	}
+	for _, path := range r.Paths {
+		dir := filepath.Join(path, name)
+		if _, err := os.Stat(filepath.Join(dir, "app.yaml")); err == nil {
+			return dir, nil
+		}
+	}
+	if _, err := os.Stat(filepath.Join(name, "app.yaml")); err == nil {
+		return name, nil
+	}
+	return "", fmt.Errorf("application %s not found in %s", name, strings.Join(r.Paths, string(filepath.ListSeparator)))
+}
+
+// Load finds an application by name and loads it
+func (r *Registry) Load(name string) (*Application, error) {
+	dir, err := r.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadApplication(dir)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
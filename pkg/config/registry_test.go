@@ -0,0 +1,134 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const minimalApp = `name: "%s"
+version: "1.0.0"
+platform_version: ">=1.0.0-dev"
+spec_version: "0.2.0"
+variants:
+  - name: "serial"
+compute:
+  architectures:
+    - name: "c7a"
+      family: "amd"
+      instance_types: ["c7a.xlarge"]
+      base_image: "hpc-base-amd-zen4:latest"
+`
+
+func addApp(t *testing.T, path, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(path, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRegistry(t *testing.T) {
+	site, repo := t.TempDir(), t.TempDir()
+	siteAlpha := addApp(t, site, "alpha", strings.Replace(minimalApp, "%s", "alpha", 1))
+	addApp(t, repo, "alpha", strings.Replace(minimalApp, "%s", "alpha-repo", 1))
+	addApp(t, repo, "beta", strings.Replace(minimalApp, `family: "amd"`, `family: "power"`, 1))
+	addApp(t, repo, "gamma", "name: [")
+	addApp(t, repo, "_template", strings.Replace(minimalApp, "%s", "template", 1))
+
+	r := &Registry{Paths: []string{site, repo}}
+	entries := r.Discover()
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	if strings.Join(names, ",") != "alpha,beta,gamma" {
+		t.Fatalf("discovered %v, want alpha,beta,gamma", names)
+	}
+	if alpha := entries[0]; alpha.Dir != siteAlpha || alpha.App.Name != "alpha" || alpha.Report.Errors() != 0 {
+		t.Errorf("alpha = %s %+v, want the valid one in the first search path", alpha.Dir, alpha.App)
+	}
+	if beta := entries[1]; beta.Err != nil || beta.Report.Errors() != 1 {
+		t.Errorf("beta has %d errors (err %v), want 1", beta.Report.Errors(), beta.Err)
+	}
+	if gamma := entries[2]; gamma.Err == nil {
+		t.Error("gamma parsed")
+	}
+
+	if dir, err := r.Find("alpha"); err != nil || dir != siteAlpha {
+		t.Errorf("Find(alpha) = %s, %v", dir, err)
+	}
+	if dir, err := r.Find(filepath.Join(repo, "alpha")); err != nil || dir != filepath.Join(repo, "alpha") {
+		t.Errorf("Find by path = %s, %v", dir, err)
+	}
+	if _, err := r.Find("delta"); err == nil {
+		t.Error("found delta")
+	}
+	if app, err := r.Load("alpha"); err != nil || app.Name != "alpha" {
+		t.Errorf("Load(alpha) = %v, %v", app, err)
+	}
+}
+
+func TestRegistryCheck(t *testing.T) {
+	path := t.TempDir()
+	dir := addApp(t, path, "alpha", strings.Replace(minimalApp, "%s", "alpha", 1))
+
+	// Discover reports what Check finds, such as problems outside app.yaml
+	var checked []string
+	r := &Registry{
+		Paths: []string{path},
+		Check: func(dir string) (*Application, *Report, error) {
+			checked = append(checked, dir)
+			app, report, err := CheckApplication(dir)
+			if err == nil {
+				report.Add("compute.architectures[0].base_image", SeverityError, "not defined")
+			}
+			return app, report, err
+		},
+	}
+	entries := r.Discover()
+	if len(checked) != 1 || checked[0] != dir {
+		t.Errorf("checked %v, want %s", checked, dir)
+	}
+	if len(entries) != 1 || entries[0].Report.Errors() != 1 {
+		t.Errorf("entries = %+v, want alpha with the check's error", entries)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AWS_HPC_HOME", home)
+	t.Setenv(AppPathEnv, "/opt/apps"+string(filepath.ListSeparator)+"/srv/apps")
+
+	r := DefaultRegistry()
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/opt/apps", "/srv/apps", filepath.Join(home, "apps"), filepath.Join(root, "applications")}
+	if strings.Join(r.Paths, " ") != strings.Join(want, " ") {
+		t.Errorf("search paths = %v, want %v", r.Paths, want)
+	}
+	if _, err := r.Find("geos-chem"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the JSON Schema dialect of the app.yaml schema
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema used to describe app.yaml.
+// AdditionalProperties is false for structs, whose fields are all known,
+// or the *Schema of the values of a map.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             int                `json:"minItems,omitempty"`
+}
+
+// field is what the Go types cannot say about an app.yaml field
+type field struct {
+	description string
+	enum        []string
+	required    bool
+	minItems    int
+}
+
+// fields documents every app.yaml field, keyed by <Go type>.<Go field>
+var fields = map[string]field{
+	"Application.Name":            {description: "Application identifier, used in image and stack names", required: true},
+	"Application.DisplayName":     {description: "Human-readable application name"},
+	"Application.Version":         {description: "Version of this application specification", required: true},
+	"Application.PlatformVersion": {description: "Platform versions the specification works with, as a constraint such as >=1.0.0", required: true},
+	"Application.SpecVersion":     {description: "Version of the app.yaml format the file is written in; older files are migrated with aws-hpc app migrate"},
+	"Application.Metadata":        {description: "Descriptive information about the application"},
+	"Application.Variants":        {description: "Builds of the application, such as a single-node and an MPI version", required: true, minItems: 1},
+	"Application.Compute":         {description: "CPU architectures and AWS Batch configuration", required: true},
+	"Application.Containers":      {description: "Container image build configuration"},
+	"Application.Storage":         {description: "Input, output and scratch storage"},
+	"Application.Environments":    {description: "Named runtime configurations jobs can be submitted with"},
+	"Application.Cost":            {description: "Cost estimation parameters"},
+	"Application.Licensing":       {description: "License server requirements"},
+	"Application.GPU":             {description: "GPU requirements"},
+	"Application.Networking":      {description: "Networking requirements of multi-node variants"},
+
+	"ApplicationMetadata.Description":   {description: "One-line description of the application"},
+	"ApplicationMetadata.Homepage":      {description: "Project homepage URL"},
+	"ApplicationMetadata.Documentation": {description: "Documentation URL"},
+	"ApplicationMetadata.Repository":    {description: "Source repository URL"},
+	"ApplicationMetadata.License":       {description: "License of the application, such as MIT"},
+	"ApplicationMetadata.Maintainers":   {description: "People responsible for the application specification"},
+	"ApplicationMetadata.Tags":          {description: "Search keywords"},
+
+	"Maintainer.Name":  {description: "Maintainer name"},
+	"Maintainer.Email": {description: "Maintainer email address"},
+
+	"Variant.Name":            {description: "Variant identifier, used in image tags", required: true},
+	"Variant.DisplayName":     {description: "Human-readable variant name"},
+	"Variant.Description":     {description: "One-line description of the variant"},
+	"Variant.Type":            {description: "Whether jobs run on one node or across several", enum: VariantTypes},
+	"Variant.Parallelism":     {description: "How the variant parallelizes", enum: Parallelisms},
+	"Variant.UpstreamVersion": {description: "Version of the upstream software the variant builds"},
+
+	"ComputeSpec.Architectures": {description: "CPU architectures the application is built and run on", required: true, minItems: 1},
+	"ComputeSpec.Batch":         {description: "AWS Batch compute environments and job queues"},
+
+	"Architecture.Name":          {description: "Architecture identifier, usually the instance family such as c7a", required: true},
+	"Architecture.Family":        {description: "CPU vendor family", enum: Families, required: true},
+	"Architecture.Generation":    {description: "CPU generation, such as zen4, sapphirerapids or neoverse-v2"},
+	"Architecture.InstanceTypes": {description: "EC2 instance types of the architecture", required: true, minItems: 1},
+	"Architecture.CompilerFlags": {description: "Compiler flags for builds on this architecture"},
+	"Architecture.MathLibrary":   {description: "Math library the architecture's builds link against"},
+	"Architecture.BaseImage":     {description: "Base image the architecture's images are built from", required: true},
+
+	"MathLibrary.Name":    {description: "Math library name, such as amd-aocl"},
+	"MathLibrary.Version": {description: "Math library version"},
+	"MathLibrary.BLAS":    {description: "Spack spec of the BLAS implementation, such as amdblis@4.2"},
+	"MathLibrary.LAPACK":  {description: "Spack spec of the LAPACK implementation, such as amdlibflame@4.2"},
+
+	"BatchConfig.MinVCPUs":          {description: "Minimum vCPUs of each compute environment"},
+	"BatchConfig.MaxVCPUs":          {description: "Maximum vCPUs of each compute environment"},
+	"BatchConfig.SpotBidPercentage": {description: "Maximum Spot price as a percentage of the On-Demand price"},
+	"BatchConfig.Queues":            {description: "Job queues"},
+
+	"Queue.Name":                {description: "Job queue name", required: true},
+	"Queue.Priority":            {description: "Job queue priority; higher is scheduled first"},
+	"Queue.ComputeEnvironments": {description: "Compute environments of the queue, in order of preference"},
+
+	"ComputeEnvironment.Type":          {description: "Purchase option of the compute environment", enum: ComputeTypes},
+	"ComputeEnvironment.Architectures": {description: "Names of the architectures the compute environment runs"},
+	"ComputeEnvironment.MaxVCPUs":      {description: "Maximum vCPUs of the compute environment"},
+
+	"ContainerSpec.Registry":     {description: "Container registry, such as ecr"},
+	"ContainerSpec.Repository":   {description: "Image repository name"},
+	"ContainerSpec.BuildSystem":  {description: "Image build tool, such as docker-buildx"},
+	"ContainerSpec.Variants":     {description: "Image build configuration of each variant, by variant name"},
+	"ContainerSpec.Dependencies": {description: "Spack specs installed in every image"},
+
+	"ContainerVariant.Dockerfile":      {description: "Dockerfile to build, relative to the application; generated when empty"},
+	"ContainerVariant.Context":         {description: "Build context, relative to the application"},
+	"ContainerVariant.BuildArgs":       {description: "Build arguments passed to the Dockerfile"},
+	"ContainerVariant.Install":         {description: "Shell commands that build and install the application in a generated Dockerfile"},
+	"ContainerVariant.Entrypoint":      {description: "Entrypoint script, relative to the context; default entrypoint.sh"},
+	"ContainerVariant.ConfigGenerator": {description: "Run configuration generator, relative to the context"},
+
+	"StorageSpec.Input":   {description: "Where jobs read input data"},
+	"StorageSpec.Output":  {description: "Where jobs write results"},
+	"StorageSpec.Scratch": {description: "Local scratch storage of each job"},
+	"StorageSpec.Shared":  {description: "Shared filesystem mounted by every job"},
+
+	"StorageLocation.Type":      {description: "Storage service", enum: StorageTypes},
+	"StorageLocation.Bucket":    {description: "S3 bucket name"},
+	"StorageLocation.Prefix":    {description: "S3 key prefix"},
+	"StorageLocation.Lifecycle": {description: "S3 lifecycle rules"},
+
+	"LifecyclePolicy.TransitionIA":      {description: "Days before objects move to S3 Standard-IA"},
+	"LifecyclePolicy.TransitionGlacier": {description: "Days before objects move to S3 Glacier"},
+	"LifecyclePolicy.Expiration":        {description: "Days before objects are deleted"},
+
+	"ScratchStorage.Type":       {description: "Kind of scratch storage", enum: ScratchTypes},
+	"ScratchStorage.SizeGB":     {description: "Scratch size in GiB"},
+	"ScratchStorage.VolumeType": {description: "EBS volume type; only for ebs scratch storage", enum: VolumeTypes},
+	"ScratchStorage.IOPS":       {description: "Provisioned IOPS; only for gp3, io1 and io2 EBS volumes"},
+
+	"SharedStorage.Type":           {description: "Shared filesystem service", enum: SharedTypes},
+	"SharedStorage.SizeGB":         {description: "Filesystem size in GiB"},
+	"SharedStorage.ThroughputMode": {description: "EFS throughput mode, such as bursting or provisioned"},
+
+	"Environment.Name":        {description: "Environment name, as given to job submit --env", required: true},
+	"Environment.Config":      {description: "Environment configuration file, relative to the application"},
+	"Environment.Description": {description: "One-line description of the environment"},
+
+	"CostSpec.EstimateMethod": {description: "How job costs are estimated"},
+	"CostSpec.Baseline":       {description: "Measured run the estimates scale from"},
+	"CostSpec.ScalingFactors": {description: "Runtime of each architecture relative to the baseline, by architecture name"},
+
+	"BaselineCost.Architecture": {description: "Architecture of the baseline run"},
+	"BaselineCost.RuntimeHours": {description: "Runtime of the baseline run in hours"},
+	"BaselineCost.CostPerHour":  {description: "Cost of the baseline run per hour in USD"},
+
+	"LicensingSpec.Type":         {description: "License manager", enum: LicenseTypes},
+	"LicensingSpec.Server":       {description: "License server address, such as 27000@license.example.com"},
+	"LicensingSpec.Feature":      {description: "License feature jobs check out"},
+	"LicensingSpec.TokensPerJob": {description: "License tokens each job uses"},
+
+	"GPUSpec.Required": {description: "Whether jobs need a GPU"},
+	"GPUSpec.Types":    {description: "Acceptable GPU types"},
+	"GPUSpec.Count":    {description: "GPUs per job"},
+	"GPUSpec.MemoryGB": {description: "Minimum GPU memory in GiB"},
+
+	"NetworkingSpec.EFA":            {description: "Attach Elastic Fabric Adapters for MPI traffic"},
+	"NetworkingSpec.PlacementGroup": {description: "Launch nodes in a cluster placement group"},
+}
+
+// AppSchema returns the JSON Schema of app.yaml, generated from the
+// Application type
+func AppSchema() *Schema {
+	s := schemaFor(reflect.TypeOf(Application{}))
+	s.Schema = SchemaVersion
+	s.Title = "app.yaml"
+	s.Description = "AWS HPC application specification"
+	return s
+}
+
+// schemaFor returns the schema of a Go type as YAML decodes it
+func schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			prop := schemaFor(sf.Type)
+			f := fields[t.Name()+"."+sf.Name]
+			prop.Description = f.description
+			prop.Enum = f.enum
+			prop.MinItems = f.minItems
+			if f.required {
+				s.Required = append(s.Required, name)
+			}
+			s.Properties[name] = prop
+		}
+		return s
+	}
+	panic(fmt.Sprintf("config: no schema for %s", t))
+}
+
+// Validate checks a parsed app.yaml document against the schema. Findings
+// have YAML paths but no positions.
+func (s *Schema) Validate(root *yaml.Node) []Finding {
+	var findings []Finding
+	add := func(path, format string, args ...interface{}) {
+		findings = append(findings, Finding{Path: path, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var validate func(s *Schema, n *yaml.Node, path string)
+	validate = func(s *Schema, n *yaml.Node, path string) {
+		if n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+			return
+		}
+
+		switch s.Type {
+		case "object":
+			if n.Kind != yaml.MappingNode {
+				add(path, "must be a mapping")
+				return
+			}
+			present := map[string]bool{}
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i].Value, n.Content[i+1]
+				if present[key] {
+					continue // a duplicate key, reported by duplicateKeys
+				}
+				present[key] = true
+				keyPath := key
+				if path != "" {
+					keyPath = path + "." + key
+				}
+				if prop, ok := s.Properties[key]; ok {
+					validate(prop, value, keyPath)
+				} else if values, ok := s.AdditionalProperties.(*Schema); ok {
+					validate(values, value, keyPath)
+				} else {
+					add(keyPath, "unknown field %s", key)
+				}
+			}
+			for _, name := range s.Required {
+				if !present[name] {
+					if path == "" {
+						add(name, "is required")
+					} else {
+						add(path+"."+name, "is required")
+					}
+				}
+			}
+		case "array":
+			if n.Kind != yaml.SequenceNode {
+				add(path, "must be a list")
+				return
+			}
+			if len(n.Content) < s.MinItems {
+				add(path, "has %d items, needs at least %d", len(n.Content), s.MinItems)
+			}
+			for i, item := range n.Content {
+				validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		default:
+			if n.Kind != yaml.ScalarNode {
+				add(path, "must be %s", article(s.Type))
+				return
+			}
+			tag := n.ShortTag()
+			switch {
+			case s.Type == "integer" && tag != "!!int",
+				s.Type == "number" && tag != "!!int" && tag != "!!float",
+				s.Type == "boolean" && tag != "!!bool":
+				add(path, "must be %s, not %q", article(s.Type), n.Value)
+			case len(s.Enum) > 0 && !contains(s.Enum, n.Value):
+				add(path, "unknown value %q, must be one of %v", n.Value, s.Enum)
+			}
+		}
+	}
+
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	validate(s, node, "")
+	return findings
+}
+
+func article(typ string) string {
+	if typ == "integer" {
+		return "an " + typ
+	}
+	return "a " + typ
+}
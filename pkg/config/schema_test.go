@@ -0,0 +1,150 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Every field of the Go types that app.yaml has is described, and every
+// description is of a field that exists
+func TestSchemaDescribesEveryField(t *testing.T) {
+	seen := map[string]bool{}
+	var walk func(reflect.Type)
+	walk = func(typ reflect.Type) {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			walk(typ.Elem())
+		case reflect.Struct:
+			for i := 0; i < typ.NumField(); i++ {
+				key := typ.Name() + "." + typ.Field(i).Name
+				if seen[key] || typ.Field(i).Tag.Get("yaml") == "-" {
+					continue
+				}
+				seen[key] = true
+				if fields[key].description == "" {
+					t.Errorf("%s has no description", key)
+				}
+				walk(typ.Field(i).Type)
+			}
+		}
+	}
+	walk(reflect.TypeOf(Application{}))
+	for key := range fields {
+		if !seen[key] {
+			t.Errorf("fields has %s, which is not a field of Application", key)
+		}
+	}
+}
+
+func TestAppSchema(t *testing.T) {
+	s := AppSchema()
+	if _, err := json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+
+	arch := s.Properties["compute"].Properties["architectures"].Items
+	if got := arch.Properties["family"].Enum; !reflect.DeepEqual(got, Families) {
+		t.Errorf("family enum = %v, want %v", got, Families)
+	}
+	if got := arch.Required; !reflect.DeepEqual(got, []string{"name", "family", "instance_types", "base_image"}) {
+		t.Errorf("architecture required = %v", got)
+	}
+	if arch.AdditionalProperties != false {
+		t.Errorf("architecture additionalProperties = %v, want false", arch.AdditionalProperties)
+	}
+	for path, enum := range map[string][]string{
+		"storage.input.type": StorageTypes,
+		"licensing.type":     LicenseTypes,
+	} {
+		node := s
+		for _, name := range strings.Split(path, ".") {
+			node = node.Properties[name]
+		}
+		if !reflect.DeepEqual(node.Enum, enum) {
+			t.Errorf("%s enum = %v, want %v", path, node.Enum, enum)
+		}
+	}
+	scaling := s.Properties["cost"].Properties["scaling_factors"]
+	if values, ok := scaling.AdditionalProperties.(*Schema); !ok || values.Type != "number" {
+		t.Errorf("scaling_factors values = %v, want numbers", scaling.AdditionalProperties)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	doc := `name: "x"
+version: 1.0
+variants:
+  - name: "serial"
+    parallelism: "threads"
+compute:
+  architectures: {}
+  batch:
+    min_vcpus: "none"
+storage:
+  input:
+    type: "gcs"
+  scratch:
+    size_gb: 100
+    size_gb: 200
+containers:
+  variants:
+    serial:
+      build_args: {VERSION: "1"}
+      dockerfiles: "Dockerfile"
+licensing:
+  type: "flexlm"
+networking:
+  efa: "yes"
+`
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, f := range AppSchema().Validate(&root) {
+		got[f.Path] = f.Message
+	}
+	want := map[string]string{
+		"platform_version":                       "is required",
+		"variants[0].parallelism":                `unknown value "threads", must be one of [openmp mpi serial gpu]`,
+		"compute.architectures":                  "must be a list",
+		"compute.batch.min_vcpus":                `must be an integer, not "none"`,
+		"storage.input.type":                     `unknown value "gcs", must be one of [s3 efs fsx-lustre]`,
+		"containers.variants.serial.dockerfiles": "unknown field dockerfiles",
+		"networking.efa":                         `must be a boolean, not "yes"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v\nwant %v", got, want)
+	}
+}
+
+func TestGEOSChemMatchesSchema(t *testing.T) {
+	_, report, err := CheckApplication("../../applications/geos-chem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Findings {
+		if f.Severity == SeverityError {
+			t.Errorf("%s: %s", report.Location(f), f)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg"
+	"gopkg.in/yaml.v3"
+)
+
+// parse parses app.yaml into its document node. The error is for
+// documents that are not YAML at all.
+func parse(data []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+	return &root, nil
+}
+
+// decode decodes a document, reporting values the Application type cannot
+// hold as findings instead of failing
+func decode(root *yaml.Node) (*Application, []Finding, error) {
+	var findings []Finding
+	var app Application
+	err := root.Decode(&app)
+	var typeErr *yaml.TypeError
+	switch {
+	case err == nil:
+	case errors.As(err, &typeErr):
+		for _, msg := range typeErr.Errors {
+			if f, ok := typeErrorFinding(root, msg); ok {
+				findings = append(findings, f)
+			}
+		}
+	default:
+		return nil, nil, err
+	}
+	return &app, findings, nil
+}
+
+// check parses app.yaml, migrates it to the current spec version in
+// memory, merges the overlays over it, resolves references with
+// DefaultResolver, and returns every finding, positioned and with secrets
+// redacted: spec version, overlay, reference, duplicate key and schema
+// findings, then decoding errors and the semantic checks of Check for
+// fields the schema has not already flagged, then the environment files in
+// dir
+func check(data []byte, dir string, overlays []string) (*Application, *yaml.Node, []Finding, error) {
+	root, err := parse(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var findings []Finding
+	from := SpecVersion(root)
+	if applied, err := Migrate(root); err != nil {
+		findings = append(findings, Finding{Path: "spec_version", Severity: SeverityError, Message: err.Error()})
+	} else if len(applied) > 0 {
+		findings = append(findings, Finding{Path: "spec_version", Severity: SeverityWarning,
+			Message: fmt.Sprintf("spec %s is older than %s; run aws-hpc app migrate to upgrade the file", from, pkg.AppSpecVersion)})
+	}
+
+	origin := map[*yaml.Node]string{}
+	for _, file := range overlays {
+		fs, err := applyOverlay(root, file, origin)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		findings = append(findings, fs...)
+	}
+
+	secrets, unresolved := DefaultResolver.interpolate(root)
+	findings = append(findings, unresolved...)
+
+	app, decoded, err := decode(root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	app.Dir = dir
+	app.secrets = secrets
+
+	schema := AppSchema().Validate(root)
+	findings = append(findings, duplicateKeys(root)...)
+	findings = append(findings, schema...)
+	flagged := map[string]bool{}
+	for _, f := range schema {
+		flagged[f.Path] = true
+	}
+	for _, f := range append(decoded, app.Check()...) {
+		if !flagged[f.Path] {
+			findings = append(findings, f)
+		}
+	}
+	findings = append(findings, app.CheckEnvironments()...)
+	for i, f := range findings {
+		if f.File != "" || f.Path == "" {
+			continue
+		}
+		at := locate(root, f.Path)
+		if f.Line == 0 {
+			findings[i].Line, findings[i].Column = at.Line, at.Column
+		}
+		findings[i].File = origin[at]
+	}
+	for i, f := range findings {
+		findings[i].Message = secrets.redact(f.Message)
+	}
+	return app, root, findings, nil
+}
+
+var (
+	lineMessage  = regexp.MustCompile(`^line (\d+): (.*)$`)
+	duplicateKey = regexp.MustCompile(`already (defined|set)`)
+)
+
+// typeErrorFinding turns a yaml.v3 decoding error into a finding at the
+// field it is about. Duplicate keys are skipped; duplicateKeys reports
+// them with both positions.
+func typeErrorFinding(root *yaml.Node, msg string) (Finding, bool) {
+	if duplicateKey.MatchString(msg) {
+		return Finding{}, false
+	}
+	f := Finding{Severity: SeverityError, Message: msg}
+	if m := lineMessage.FindStringSubmatch(msg); m != nil {
+		f.Line, _ = strconv.Atoi(m[1])
+		f.Message = m[2]
+		// Keys merged from overlays can share the line with a key of
+		// app.yaml; the one whose value the message quotes is meant
+		quoted := false
+		walkKeys(root, func(key, value *yaml.Node, path string) {
+			if key.Line != f.Line || quoted {
+				return
+			}
+			quoted = value.Kind == yaml.ScalarNode && strings.Contains(f.Message, "`"+value.Value+"`")
+			if f.Path == "" || quoted {
+				f.Path, f.Column = path, key.Column
+			}
+		})
+	}
+	return f, true
+}
+
+// duplicateKeys reports keys defined more than once in the same mapping
+func duplicateKeys(root *yaml.Node) []Finding {
+	var findings []Finding
+	seen := map[string]*yaml.Node{}
+	walkKeys(root, func(key, _ *yaml.Node, path string) {
+		if first, ok := seen[path]; ok {
+			findings = append(findings, Finding{
+				Path:     path,
+				Line:     key.Line,
+				Column:   key.Column,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("duplicate key %s, first defined at line %d", key.Value, first.Line),
+			})
+			return
+		}
+		seen[path] = key
+	})
+	return findings
+}
+
+// walkKeys calls fn for every mapping key in the document with its value
+// and YAML path, in document order
+func walkKeys(node *yaml.Node, fn func(key, value *yaml.Node, path string)) {
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				walk(c, fmt.Sprintf("%s[%d]", path, i))
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i]
+				keyPath := key.Value
+				if path != "" {
+					keyPath = path + "." + key.Value
+				}
+				fn(key, n.Content[i+1], keyPath)
+				walk(n.Content[i+1], keyPath)
+			}
+		}
+	}
+	walk(node, "")
+}
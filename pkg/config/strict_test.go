@@ -0,0 +1,103 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const typoApp = `name: "typo"
+version: "1.0.0"
+platform_version: ">=1.0.0-dev"
+spec_version: "0.2.0"
+variants:
+  - name: "serial"
+compute:
+  architectures:
+    - name: "c7a"
+      family: "amd"
+      instance_type: ["c7a.xlarge"]
+      base_image: "hpc-base-amd-zen4:latest"
+storage:
+  scratch:
+    type: "ebs"
+    size_gb: 100
+    type: "gp3"
+`
+
+func writeApp(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStrictDecoding(t *testing.T) {
+	dir := writeApp(t, typoApp)
+
+	_, err := LoadApplication(dir)
+	if err == nil {
+		t.Fatal("expected unknown and duplicate keys to fail loading")
+	}
+	for _, want := range []string{"line 11: compute.architectures[0].instance_type: unknown field instance_type", "line 17: storage.scratch.type: duplicate key type, first defined at line 15"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	}
+
+	_, report, err := CheckApplication(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]Finding{}
+	for _, f := range report.Findings {
+		found[f.Path] = f
+	}
+	if f := found["compute.architectures[0].instance_type"]; f.Line != 11 || f.Column != 7 {
+		t.Errorf("unknown field finding = %+v, want line 11 column 7", f)
+	}
+	if f := found["storage.scratch.type"]; f.Line != 17 || !strings.Contains(f.Message, "duplicate key") {
+		t.Errorf("duplicate key finding = %+v, want line 17", f)
+	}
+}
+
+func TestScratchStorage(t *testing.T) {
+	tests := []struct {
+		scratch ScratchStorage
+		path    string
+	}{
+		{ScratchStorage{Type: "ebs", SizeGB: 100, VolumeType: "io2", IOPS: 3000}, ""},
+		{ScratchStorage{Type: "instance-store"}, ""},
+		{ScratchStorage{Type: "instance-store", VolumeType: "gp3"}, "storage.scratch.volume_type"},
+		{ScratchStorage{Type: "ebs", VolumeType: "gp2", IOPS: 3000}, "storage.scratch.iops"},
+	}
+	for _, tt := range tests {
+		app := &Application{Storage: StorageSpec{Scratch: tt.scratch}}
+		var got string
+		for _, f := range app.Check() {
+			if strings.HasPrefix(f.Path, "storage.") {
+				got = f.Path
+			}
+		}
+		if got != tt.path {
+			t.Errorf("%+v: finding at %q, want %q", tt.scratch, got, tt.path)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a validation finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Allowed values of enumerated app.yaml fields, the enums of AppSchema
+var (
+	Families     = []string{"amd", "intel", "arm"}
+	VariantTypes = []string{"single-node", "multi-node"}
+	Parallelisms = []string{"openmp", "mpi", "serial", "gpu"}
+	ComputeTypes = []string{"spot", "on-demand"}
+	StorageTypes = []string{"s3", "efs", "fsx-lustre"}
+	SharedTypes  = []string{"efs", "fsx-lustre"}
+	ScratchTypes = []string{"ebs", "instance-store"}
+	VolumeTypes  = []string{"gp3", "gp2", "io1", "io2"}
+	LicenseTypes = []string{"none", "flexlm", "rlm", "custom"}
+)
+
+// Finding is a problem in an application specification. Path is the YAML
+// path of the field, such as compute.architectures[2].family. Line and
+// Column are 0 when the position is not known. File is the overlay the
+// field was set in, or empty for app.yaml.
+type Finding struct {
+	Path     string
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("%s: %s", f.Severity, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Path, f.Message)
+}
+
+// Report is the findings for an app.yaml file, with positions resolved
+// from the parsed document
+type Report struct {
+	File     string
+	Findings []Finding
+	root     *yaml.Node
+	secrets  redactor
+}
+
+// Add records a finding at a YAML path
+func (r *Report) Add(path string, severity Severity, format string, args ...interface{}) {
+	f := Finding{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)}
+	f.Line, f.Column = position(r.root, path)
+	r.Findings = append(r.Findings, f)
+}
+
+// Errors returns the number of error findings
+func (r *Report) Errors() int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Sort orders findings by position, those in app.yaml before those in
+// overlays
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.File != b.File {
+			return a.File == "" || b.File != "" && a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// Location returns file:line:column for a finding
+func (r *Report) Location(f Finding) string {
+	file := r.File
+	if f.File != "" {
+		file = f.File
+	}
+	if f.Line == 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d:%d", file, f.Line, f.Column)
+}
+
+// CheckApplication loads app.yaml from the application directory, merges
+// any overlays over it, and reports every problem in the result, rather
+// than stopping at the first. The returned error is for files that cannot
+// be read or parsed at all.
+func CheckApplication(path string, overlays ...string) (*Application, *Report, error) {
+	file := filepath.Join(path, "app.yaml")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read app.yaml: %w", err)
+	}
+
+	app, root, findings, err := check(data, path, overlays)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse app.yaml: %w", err)
+	}
+
+	report := &Report{File: file, Findings: findings, root: root, secrets: app.secrets}
+	report.Sort()
+	return app, report, nil
+}
+
+// Check returns the problems in the application specification that
+// AppSchema cannot express: empty required values, duplicate names,
+// references between sections and combinations of settings. Field types
+// and enum values are checked by the schema.
+func (a *Application) Check() []Finding {
+	var findings []Finding
+	add := func(path string, severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	required := func(path, value string) {
+		if value == "" {
+			add(path, SeverityError, "is required")
+		}
+	}
+
+	required("name", a.Name)
+	required("version", a.Version)
+	required("platform_version", a.PlatformVersion)
+	findings = append(findings, a.Compatibility()...)
+
+	// Variants
+	if len(a.Variants) == 0 {
+		add("variants", SeverityError, "at least one variant is required")
+	}
+	variants := map[string]bool{}
+	for i, v := range a.Variants {
+		path := fmt.Sprintf("variants[%d]", i)
+		required(path+".name", v.Name)
+		if v.Name != "" && variants[v.Name] {
+			add(path+".name", SeverityError, "duplicate variant %s", v.Name)
+		}
+		variants[v.Name] = true
+		if v.Type == "multi-node" && !a.Networking.EFA {
+			add(path+".type", SeverityWarning, "multi-node variant %s without networking.efa; MPI traffic will use TCP", v.Name)
+		}
+	}
+
+	// Architectures
+	if len(a.Compute.Architectures) == 0 {
+		add("compute.architectures", SeverityError, "at least one architecture is required")
+	}
+	archs := map[string]bool{}
+	for i, arch := range a.Compute.Architectures {
+		path := fmt.Sprintf("compute.architectures[%d]", i)
+		required(path+".name", arch.Name)
+		if arch.Name != "" && archs[arch.Name] {
+			add(path+".name", SeverityError, "duplicate architecture %s", arch.Name)
+		}
+		archs[arch.Name] = true
+		required(path+".family", arch.Family)
+		if len(arch.InstanceTypes) == 0 {
+			add(path+".instance_types", SeverityError, "at least one instance type is required")
+		}
+		required(path+".base_image", arch.BaseImage)
+	}
+
+	// Queues
+	queues := map[string]bool{}
+	for i, q := range a.Compute.Batch.Queues {
+		path := fmt.Sprintf("compute.batch.queues[%d]", i)
+		required(path+".name", q.Name)
+		if q.Name != "" && queues[q.Name] {
+			add(path+".name", SeverityError, "duplicate queue %s", q.Name)
+		}
+		queues[q.Name] = true
+		for j, ce := range q.ComputeEnvironments {
+			cePath := fmt.Sprintf("%s.compute_environments[%d]", path, j)
+			for k, name := range ce.Architectures {
+				if !archs[name] {
+					add(fmt.Sprintf("%s.architectures[%d]", cePath, k), SeverityError,
+						"unknown architecture %s", name)
+				}
+			}
+		}
+	}
+
+	// Containers
+	for _, name := range sortedKeys(a.Containers.Variants) {
+		if !variants[name] {
+			add("containers.variants."+name, SeverityError, "container variant %s matches no declared variant", name)
+		}
+	}
+	for i, v := range a.Variants {
+		if _, ok := a.Containers.Variants[v.Name]; v.Name != "" && !ok && len(a.Containers.Variants) > 0 {
+			add(fmt.Sprintf("variants[%d].name", i), SeverityWarning, "variant %s has no container configuration", v.Name)
+		}
+	}
+
+	// Scratch storage
+	scratch := a.Storage.Scratch
+	if scratch.VolumeType != "" && scratch.Type != "ebs" {
+		add("storage.scratch.volume_type", SeverityError, "volume_type only applies to ebs scratch storage")
+	}
+	if scratch.IOPS > 0 && (scratch.Type != "ebs" || scratch.VolumeType == "gp2") {
+		add("storage.scratch.iops", SeverityError, "iops only applies to gp3, io1 and io2 ebs volumes")
+	}
+
+	// Environments
+	envs := map[string]bool{}
+	for i, env := range a.Environments {
+		path := fmt.Sprintf("environments[%d]", i)
+		required(path+".name", env.Name)
+		if env.Name != "" && envs[env.Name] {
+			add(path+".name", SeverityError, "duplicate environment %s", env.Name)
+		}
+		envs[env.Name] = true
+	}
+
+	// Cost
+	for _, name := range sortedKeys(a.Cost.ScalingFactors) {
+		if !archs[name] {
+			add("cost.scaling_factors."+name, SeverityWarning, "no architecture named %s", name)
+		}
+	}
+
+	return findings
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var pathElement = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// position returns the line and column of the node at a YAML path, or of
+// its closest existing parent
+func position(root *yaml.Node, path string) (int, int) {
+	if root == nil {
+		return 0, 0
+	}
+	at := locate(root, path)
+	return at.Line, at.Column
+}
+
+// locate returns the node a finding at a YAML path is reported at: the key
+// of a mapping entry, or the closest existing parent
+func locate(root *yaml.Node, path string) *yaml.Node {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	// Mapping entries are reported at their key, which is where a reader
+	// looks for a nested block
+	at := node
+	for _, elem := range pathElement.FindAllString(path, -1) {
+		var next, key *yaml.Node
+		if elem[0] == '[' {
+			i, _ := strconv.Atoi(elem[1 : len(elem)-1])
+			if node.Kind == yaml.SequenceNode && i < len(node.Content) {
+				next, key = node.Content[i], node.Content[i]
+			}
+		} else if node.Kind == yaml.MappingNode {
+			for k := 0; k+1 < len(node.Content); k += 2 {
+				if node.Content[k].Value == elem {
+					next, key = node.Content[k+1], node.Content[k]
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node, at = next, key
+	}
+	return at
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const brokenApp = `name: "broken"
+version: "1.0.0"
+platform_version: ">=1.0.0-dev"
+spec_version: "0.2.0"
+variants:
+  - name: "serial"
+    type: "single-node"
+    parallelism: "threads"
+  - name: "mpi"
+    type: "multi-node"
+    parallelism: "mpi"
+  - name: "serial"
+    type: "cluster"
+compute:
+  architectures:
+    - name: "c7a"
+      family: "amd"
+      instance_types: ["c7a.xlarge"]
+      base_image: "hpc-base-amd-zen4:latest"
+    - name: "c7a"
+      family: "risc-v"
+      instance_types: []
+      base_image: "hpc-base-amd-zen4:latest"
+  batch:
+    queues:
+      - name: "q"
+        compute_environments:
+          - type: "spot"
+            architectures: ["c7a", "m7i"]
+containers:
+  variants:
+    serial: {}
+    mpi: {}
+    hybrid: {}
+cost:
+  scaling_factors:
+    c7a: 1.0
+    graviton4: 0.7
+`
+
+func TestCheckApplication(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(brokenApp), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, report, err := CheckApplication(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		line     int
+		severity Severity
+		path     string
+	}{
+		{8, SeverityError, "variants[0].parallelism"},
+		{10, SeverityWarning, "variants[1].type"},
+		{12, SeverityError, "variants[2].name"},
+		{13, SeverityError, "variants[2].type"},
+		{20, SeverityError, "compute.architectures[1].name"},
+		{21, SeverityError, "compute.architectures[1].family"},
+		{22, SeverityError, "compute.architectures[1].instance_types"},
+		{29, SeverityError, "compute.batch.queues[0].compute_environments[0].architectures[1]"},
+		{34, SeverityError, "containers.variants.hybrid"},
+		{38, SeverityWarning, "cost.scaling_factors.graviton4"},
+	}
+	if len(report.Findings) != len(want) {
+		for _, f := range report.Findings {
+			t.Logf("%s: %s", report.Location(f), f)
+		}
+		t.Fatalf("got %d findings, want %d", len(report.Findings), len(want))
+	}
+	for i, w := range want {
+		f := report.Findings[i]
+		if f.Line != w.line || f.Severity != w.severity || f.Path != w.path {
+			t.Errorf("finding %d = %d %s %s, want %d %s %s", i, f.Line, f.Severity, f.Path, w.line, w.severity, w.path)
+		}
+	}
+	if report.Errors() != 8 {
+		t.Errorf("Errors() = %d, want 8", report.Errors())
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	app := &Application{Name: "x"}
+	err := app.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, field := range []string{"version", "platform_version", "variants", "compute.architectures"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error does not mention %s:\n%v", field, err)
+		}
+	}
+}
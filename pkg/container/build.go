@@ -0,0 +1,321 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/command"
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// Build is one docker buildx invocation: an application variant built for
+// an architecture
+type Build struct {
+	Variant      string
+	Architecture string
+	Platform     string
+	Dockerfile   string
+	Context      string
+	Tag          string // immutable tag, see Image
+	Alias        string // moving tag
+	BuildArgs    map[string]string
+	Push         bool
+
+	// MetadataFile, when set, is where buildx writes the build result,
+	// including the image digest
+	MetadataFile string
+
+	// Generated is the Dockerfile generated from app.yaml, written to
+	// Dockerfile before building. Nil for hand-written Dockerfiles.
+	Generated []byte
+}
+
+// Args returns the docker arguments for the build. Build args are sorted,
+// so the same build always produces the same command line.
+func (b *Build) Args() []string {
+	args := []string{
+		"buildx", "build",
+		"--platform", b.Platform,
+		"--file", b.Dockerfile,
+		"--tag", b.Tag,
+	}
+	if b.Alias != "" {
+		args = append(args, "--tag", b.Alias)
+	}
+
+	names := make([]string, 0, len(b.BuildArgs))
+	for name := range b.BuildArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--build-arg", name+"="+b.BuildArgs[name])
+	}
+
+	// Cross-platform builds are not loaded by default; --load makes
+	// unpushed images available to the local backend
+	if b.Push {
+		args = append(args, "--push")
+	} else {
+		args = append(args, "--load")
+	}
+	if b.MetadataFile != "" {
+		args = append(args, "--metadata-file", b.MetadataFile)
+	}
+	return append(args, b.Context)
+}
+
+// Builder turns an application's container spec into image builds
+type Builder struct {
+	// AppDir is the application directory; Dockerfile and context paths
+	// in app.yaml are relative to it
+	AppDir string
+
+	// Registry is the registry host images are tagged for and base images
+	// are pulled from. Empty means the local image store.
+	Registry string
+
+	// BuildDir is where Dockerfiles generated from app.yaml are written
+	BuildDir string
+
+	Push   bool
+	Engine string // docker binary, normally "docker"
+	Runner command.Runner
+}
+
+// NewBuilder creates a builder for the application in appDir
+func NewBuilder(appDir, registry string, push bool) *Builder {
+	return &Builder{
+		AppDir:   appDir,
+		BuildDir: filepath.Join(appDir, ".build"),
+		Registry: registry,
+		Push:     push,
+		Engine:   "docker",
+		Runner:   command.Exec{},
+	}
+}
+
+// Build returns the build of a variant for an architecture
+func (b *Builder) Build(app *config.Application, variant, arch string) (*Build, error) {
+	v, err := app.GetVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+	a, err := app.GetArchitecture(arch)
+	if err != nil {
+		return nil, err
+	}
+	cv, ok := app.Containers.Variants[variant]
+	if !ok {
+		return nil, fmt.Errorf("no container configuration for variant %s", variant)
+	}
+
+	// Without a dockerfile in app.yaml, one is generated and the context
+	// defaults to containers/<variant> for the entrypoint scripts
+	var generated []byte
+	dockerfile := filepath.Join(b.AppDir, cv.Dockerfile)
+	buildContext := cv.Context
+	if cv.Dockerfile == "" {
+		generated, err = Dockerfile(app, v.Name, a.Name)
+		if err != nil {
+			return nil, err
+		}
+		dockerfile = filepath.Join(b.BuildDir, fmt.Sprintf("Dockerfile.%s-%s", v.Name, a.Name))
+		if buildContext == "" {
+			buildContext = filepath.Join("containers", v.Name)
+		}
+		if err := checkContext(filepath.Join(b.AppDir, buildContext), cv); err != nil {
+			return nil, err
+		}
+	} else if buildContext == "" {
+		buildContext = filepath.Dir(cv.Dockerfile)
+	}
+	img := imageFor(app, b.Registry, v.Name, a)
+
+	return &Build{
+		Variant:      v.Name,
+		Architecture: a.Name,
+		Platform:     img.Platform,
+		Dockerfile:   dockerfile,
+		Context:      filepath.Join(b.AppDir, buildContext),
+		Tag:          img.Ref,
+		Alias:        img.Alias,
+		BuildArgs:    b.buildArgs(app, v, a, cv),
+		Push:         b.Push,
+		Generated:    generated,
+	}, nil
+}
+
+// checkContext checks that a build context has the files a generated
+// Dockerfile copies from it
+func checkContext(dir string, cv config.ContainerVariant) error {
+	files := []string{cv.Entrypoint, cv.ConfigGenerator}
+	if files[0] == "" {
+		files[0] = DefaultEntrypoint
+	}
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			return fmt.Errorf("build context %s has no %s: %w", dir, f, err)
+		}
+	}
+	return nil
+}
+
+// Filter selects cells of the variant × architecture build matrix. Empty
+// fields match everything.
+type Filter struct {
+	Variants      []string
+	Families      []string // amd, intel, arm
+	Architectures []string
+}
+
+// Builds plans the build matrix: every variant for every architecture, in
+// app.yaml order, narrowed by the filter. Naming a variant, family or
+// architecture the application does not have is an error.
+func (b *Builder) Builds(app *config.Application, f Filter) ([]*Build, error) {
+	for _, v := range f.Variants {
+		if _, err := app.GetVariant(v); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range f.Architectures {
+		if _, err := app.GetArchitecture(a); err != nil {
+			return nil, err
+		}
+	}
+	for _, family := range f.Families {
+		found := false
+		for _, a := range app.Compute.Architectures {
+			found = found || a.Family == family
+		}
+		if !found {
+			return nil, fmt.Errorf("no architectures in family %s", family)
+		}
+	}
+
+	var builds []*Build
+	for _, v := range app.Variants {
+		if !matches(f.Variants, v.Name) {
+			continue
+		}
+		for _, a := range app.Compute.Architectures {
+			if !matches(f.Families, a.Family) || !matches(f.Architectures, a.Name) {
+				continue
+			}
+			build, err := b.Build(app, v.Name, a.Name)
+			if err != nil {
+				return nil, err
+			}
+			builds = append(builds, build)
+		}
+	}
+	if len(builds) == 0 {
+		return nil, fmt.Errorf("no builds match the filter")
+	}
+	return builds, nil
+}
+
+func matches(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs a build, streaming docker's output to w
+func (b *Builder) Run(ctx context.Context, build *Build, w io.Writer) error {
+	if build.Generated != nil {
+		if err := os.MkdirAll(filepath.Dir(build.Dockerfile), 0755); err != nil {
+			return fmt.Errorf("failed to create build directory: %w", err)
+		}
+		if err := os.WriteFile(build.Dockerfile, build.Generated, 0644); err != nil {
+			return fmt.Errorf("failed to write Dockerfile: %w", err)
+		}
+	}
+	if err := b.Runner.Run(ctx, w, w, b.Engine, build.Args()...); err != nil {
+		return fmt.Errorf("failed to build %s: %w", build.Tag, err)
+	}
+	return nil
+}
+
+// CreateManifest publishes a variant's manifest list from its pushed
+// per-architecture images
+func (b *Builder) CreateManifest(ctx context.Context, list ManifestList, w io.Writer) error {
+	args := []string{"buildx", "imagetools", "create", "--tag", list.Ref, "--tag", list.Alias}
+	for _, img := range list.Images {
+		args = append(args, img.Ref)
+	}
+	if err := b.Runner.Run(ctx, w, w, b.Engine, args...); err != nil {
+		return fmt.Errorf("failed to create manifest list %s: %w", list.Ref, err)
+	}
+	return nil
+}
+
+// buildArgs passes the architecture's base image, compiler flags and math
+// library to the Dockerfile. Variant build_args from app.yaml take
+// precedence.
+func (b *Builder) buildArgs(app *config.Application, v *config.Variant, a *config.Architecture, cv config.ContainerVariant) map[string]string {
+	flags := strings.Join(a.CompilerFlags, " ")
+	args := map[string]string{
+		"APP_NAME":     app.Name,
+		"APP_VERSION":  app.Version,
+		"VARIANT":      v.Name,
+		"ARCHITECTURE": a.Name,
+		"BASE_IMAGE":   a.BaseImage,
+		"CFLAGS":       flags,
+		"CXXFLAGS":     flags,
+		"FCFLAGS":      flags,
+	}
+	if b.Registry != "" && a.BaseImage != "" {
+		args["BASE_IMAGE"] = b.Registry + "/" + a.BaseImage
+	}
+	if v.UpstreamVersion != "" {
+		args["UPSTREAM_VERSION"] = v.UpstreamVersion
+	}
+
+	ml := a.MathLibrary
+	if ml.Name != "" {
+		args["MATH_LIBRARY"] = ml.Name
+	}
+	if ml.Version != "" {
+		args["MATH_LIBRARY_VERSION"] = ml.Version
+	}
+	if ml.BLAS != "" {
+		args["BLAS"] = ml.BLAS
+	}
+	if ml.LAPACK != "" {
+		args["LAPACK"] = ml.LAPACK
+	}
+
+	for name, value := range cv.BuildArgs {
+		args[name] = value
+	}
+	return args
+}
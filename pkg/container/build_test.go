@@ -0,0 +1,196 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// fakeRunner records commands instead of running them. Commands whose
+// arguments contain a key of fail return that error.
+type fakeRunner struct {
+	mu    sync.Mutex
+	calls [][]string
+	fail  map[string]error
+}
+
+func (f *fakeRunner) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	f.mu.Lock()
+	f.calls = append(f.calls, append([]string{name}, args...))
+	f.mu.Unlock()
+
+	joined := strings.Join(args, " ")
+	for key, err := range f.fail {
+		if strings.Contains(joined, key) {
+			return err
+		}
+	}
+	return nil
+}
+
+const appDir = "../../applications/geos-chem"
+
+func loadGeosChem(t *testing.T) *config.Application {
+	t.Helper()
+	app, err := config.LoadApplication(appDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestBuildArgs(t *testing.T) {
+	app := loadGeosChem(t)
+	b := NewBuilder(appDir, "", false)
+	b.BuildDir = "build"
+
+	build, err := b.Build(app, "classic", "c7a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"buildx", "build",
+		"--platform", "linux/amd64",
+		"--file", filepath.Join("build", "Dockerfile.classic-c7a"),
+		"--tag", "geos-chem:classic-" + app.Version + "-c7a",
+		"--tag", "geos-chem:classic-c7a",
+		"--build-arg", "APP_NAME=geos-chem",
+		"--build-arg", "APP_VERSION=" + app.Version,
+		"--build-arg", "ARCHITECTURE=c7a",
+		"--build-arg", "BASE_IMAGE=hpc-base-amd-zen4:latest",
+		"--build-arg", "BLAS=amdblis@4.2",
+		"--build-arg", "CFLAGS=-march=znver4 -mavx512f -O3 -fopenmp -ffast-math -funroll-loops",
+		"--build-arg", "CXXFLAGS=-march=znver4 -mavx512f -O3 -fopenmp -ffast-math -funroll-loops",
+		"--build-arg", "FCFLAGS=-march=znver4 -mavx512f -O3 -fopenmp -ffast-math -funroll-loops",
+		"--build-arg", "GEOS_CHEM_VERSION=14.4.3",
+		"--build-arg", "LAPACK=amdlibflame@4.2",
+		"--build-arg", "MATH_LIBRARY=amd-aocl",
+		"--build-arg", "MATH_LIBRARY_VERSION=4.2",
+		"--build-arg", "UPSTREAM_VERSION=14.4.3",
+		"--build-arg", "VARIANT=classic",
+		"--load",
+		filepath.Join(appDir, "containers/classic"),
+	}
+	if got := build.Args(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestBuildMissingEntrypoint(t *testing.T) {
+	app := loadGeosChem(t)
+	cv := app.Containers.Variants["classic"]
+	cv.ConfigGenerator = "missing.py"
+	app.Containers.Variants["classic"] = cv
+
+	_, err := NewBuilder(appDir, "", false).Build(app, "classic", "c7a")
+	if err == nil || !strings.Contains(err.Error(), "missing.py") {
+		t.Errorf("Build() error = %v, want the missing config generator", err)
+	}
+}
+
+func TestBuildPlatformAndRegistry(t *testing.T) {
+	app := loadGeosChem(t)
+	b := NewBuilder(appDir, "123456789012.dkr.ecr.us-east-1.amazonaws.com", true)
+
+	build, err := b.Build(app, "gchp", "graviton4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if build.Platform != "linux/arm64" {
+		t.Errorf("Platform = %s, want linux/arm64", build.Platform)
+	}
+	if want := "123456789012.dkr.ecr.us-east-1.amazonaws.com/geos-chem:gchp-" + app.Version + "-graviton4"; build.Tag != want {
+		t.Errorf("Tag = %s, want %s", build.Tag, want)
+	}
+	if want := "123456789012.dkr.ecr.us-east-1.amazonaws.com/geos-chem:gchp-graviton4"; build.Alias != want {
+		t.Errorf("Alias = %s, want %s", build.Alias, want)
+	}
+	if !strings.HasPrefix(build.BuildArgs["BASE_IMAGE"], "123456789012.dkr.ecr.us-east-1.amazonaws.com/") {
+		t.Errorf("BASE_IMAGE = %s, want it pulled from the registry", build.BuildArgs["BASE_IMAGE"])
+	}
+
+	args := build.Args()
+	if args[len(args)-2] != "--push" {
+		t.Errorf("expected --push before the context, got %q", args)
+	}
+}
+
+func TestBuildsMatrix(t *testing.T) {
+	app := loadGeosChem(t)
+	b := NewBuilder(appDir, "", false)
+
+	builds, err := b.Builds(app, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(app.Variants) * len(app.Compute.Architectures); len(builds) != want {
+		t.Fatalf("got %d builds, want %d", len(builds), want)
+	}
+
+	// Rebuilding the plan must give identical command lines
+	again, err := b.Builds(app, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range builds {
+		if !reflect.DeepEqual(builds[i].Args(), again[i].Args()) {
+			t.Errorf("build %d is not deterministic", i)
+		}
+	}
+
+	if _, err := b.Builds(app, Filter{Architectures: []string{"m7i"}}); err == nil {
+		t.Error("expected an error for an unknown architecture")
+	}
+}
+
+func TestRun(t *testing.T) {
+	app := loadGeosChem(t)
+	runner := &fakeRunner{fail: map[string]error{"graviton4": errors.New("exit status 1")}}
+	b := NewBuilder(appDir, "", false)
+	b.BuildDir = t.TempDir()
+	b.Runner = runner
+
+	ok, err := b.Build(app, "classic", "c7a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Run(context.Background(), ok, io.Discard); err != nil {
+		t.Errorf("Run() = %v", err)
+	}
+	if len(runner.calls) != 1 || runner.calls[0][0] != "docker" {
+		t.Fatalf("calls = %q, want one docker call", runner.calls)
+	}
+	if !reflect.DeepEqual(runner.calls[0][1:], ok.Args()) {
+		t.Errorf("ran %q, want %q", runner.calls[0][1:], ok.Args())
+	}
+
+	bad, err := b.Build(app, "classic", "graviton4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Run(context.Background(), bad, io.Discard); err == nil {
+		t.Error("expected the failed build to return an error")
+	}
+}
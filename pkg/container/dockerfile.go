@@ -0,0 +1,182 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+const (
+	// DefaultEntrypoint is the entrypoint script of generated Dockerfiles,
+	// relative to the build context
+	DefaultEntrypoint = "entrypoint.sh"
+
+	// baseProfile is set up by every base image to load its Spack packages
+	baseProfile = "/etc/profile.d/hpc-base.sh"
+
+	// appProfile loads the base image and the application's dependencies
+	appProfile = "/etc/profile.d/hpc-app.sh"
+)
+
+// Dockerfile generates the Dockerfile of a variant for an architecture
+// from app.yaml: the architecture's base image, Spack installs of the
+// container dependencies, compiler flags, the variant's install commands
+// and the entrypoint and config generator from the build context.
+//
+// BASE_IMAGE and the variant's build args are declared as ARGs with their
+// app.yaml values as defaults, so builds can still override them.
+func Dockerfile(app *config.Application, variant, arch string) ([]byte, error) {
+	v, err := app.GetVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+	a, err := app.GetArchitecture(arch)
+	if err != nil {
+		return nil, err
+	}
+	cv, ok := app.Containers.Variants[variant]
+	if !ok {
+		return nil, fmt.Errorf("no container configuration for variant %s", variant)
+	}
+	if cv.Dockerfile != "" {
+		return nil, fmt.Errorf("variant %s uses its own dockerfile %s", variant, cv.Dockerfile)
+	}
+	if len(cv.Install) == 0 {
+		return nil, fmt.Errorf("no install commands configured for variant %s", variant)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from app.yaml by aws-hpc. Do not edit; regenerate with\n")
+	fmt.Fprintf(&b, "#   aws-hpc app dockerfile %s --variant %s --arch %s\n", app.Name, v.Name, a.Name)
+	fmt.Fprintf(&b, "# Application: %s %s\n", app.Name, app.Version)
+	fmt.Fprintf(&b, "# Variant: %s\n", v.Name)
+	fmt.Fprintf(&b, "# Architecture: %s (%s %s)\n\n", a.Name, a.Family, a.Generation)
+
+	fmt.Fprintf(&b, "ARG BASE_IMAGE=%s\n", a.BaseImage)
+	fmt.Fprintf(&b, "FROM ${BASE_IMAGE}\n\n")
+
+	writeInstruction(&b, "LABEL", []string{
+		"org.hpc.app.name=" + quote(app.Name),
+		"org.hpc.app.variant=" + quote(v.Name),
+		"org.hpc.app.version=" + quote(app.Version),
+		"org.hpc.app.architecture=" + quote(a.Name),
+	})
+	b.WriteString("\n")
+
+	args := map[string]string{"APP_VERSION": app.Version}
+	if v.UpstreamVersion != "" {
+		args["UPSTREAM_VERSION"] = v.UpstreamVersion
+	}
+	for name, value := range cv.BuildArgs {
+		args[name] = value
+	}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "ARG %s=%s\n", name, args[name])
+	}
+	b.WriteString("\n")
+
+	flags := quote(strings.Join(a.CompilerFlags, " "))
+	writeInstruction(&b, "ENV", []string{
+		"APP_NAME=" + quote(app.Name),
+		"APP_ROOT=" + path.Join("/opt", app.Name),
+		"CFLAGS=" + flags,
+		"CXXFLAGS=" + flags,
+		"FCFLAGS=" + flags,
+		"FFLAGS=" + flags,
+	})
+	b.WriteString("\n")
+
+	// Base images already carry most dependencies, in which case Spack
+	// reuses them
+	load := []string{". " + baseProfile}
+	if len(app.Containers.Dependencies) > 0 {
+		b.WriteString("# Dependencies\n")
+		run := []string{". " + baseProfile}
+		var packages []string
+		for _, dep := range app.Containers.Dependencies {
+			run = append(run, "spack install --no-checksum "+dep+"%gcc")
+			packages = append(packages, spackPackage(dep))
+		}
+		run = append(run, "spack gc -y")
+		writeRun(&b, run)
+		load = append(load, "spack load "+strings.Join(packages, " "))
+	}
+	profile := []string{fmt.Sprintf("echo '#!/bin/bash' > %s", appProfile)}
+	for _, line := range load {
+		profile = append(profile, fmt.Sprintf("echo '%s' >> %s", line, appProfile))
+	}
+	writeRun(&b, profile)
+	b.WriteString("\n")
+
+	b.WriteString("# Build the application\n")
+	b.WriteString("WORKDIR /tmp/build\n")
+	writeRun(&b, append([]string{". " + appProfile, "mkdir -p ${APP_ROOT}"}, cv.Install...))
+	b.WriteString("RUN rm -rf /tmp/build\n\n")
+
+	entrypoint := cv.Entrypoint
+	if entrypoint == "" {
+		entrypoint = DefaultEntrypoint
+	}
+	b.WriteString("# Entrypoint\n")
+	b.WriteString("WORKDIR /app\n")
+	fmt.Fprintf(&b, "COPY %s /app/entrypoint.sh\n", entrypoint)
+	if cv.ConfigGenerator != "" {
+		target := "/app/" + path.Base(cv.ConfigGenerator)
+		fmt.Fprintf(&b, "COPY %s %s\n", cv.ConfigGenerator, target)
+		fmt.Fprintf(&b, "ENV CONFIG_GENERATOR=%s\n", target)
+	}
+	b.WriteString("RUN chmod +x /app/*\n\n")
+
+	b.WriteString("WORKDIR /opt/run-dir\n")
+	fmt.Fprintf(&b, "ENTRYPOINT [\"/bin/bash\", \"-c\", \"source %s && /app/entrypoint.sh \\\"$@\\\"\", \"--\"]\n", appProfile)
+	b.WriteString("CMD [\"--help\"]\n")
+
+	return []byte(b.String()), nil
+}
+
+// spackPackage returns the package name of a Spack spec such as
+// hdf5@1.14.3+fortran
+func spackPackage(spec string) string {
+	if i := strings.IndexAny(spec, "@%+~ ^"); i >= 0 {
+		return spec[:i]
+	}
+	return spec
+}
+
+// writeInstruction writes an instruction with one argument per line
+func writeInstruction(b *strings.Builder, instruction string, args []string) {
+	indent := strings.Repeat(" ", len(instruction)+1)
+	fmt.Fprintf(b, "%s %s", instruction, strings.Join(args, " \\\n"+indent))
+	b.WriteString("\n")
+}
+
+// writeRun writes a RUN instruction of commands joined with &&
+func writeRun(b *strings.Builder, commands []string) {
+	fmt.Fprintf(b, "RUN %s\n", strings.Join(commands, " && \\\n    "))
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestDockerfileGolden(t *testing.T) {
+	app := loadGeosChem(t)
+
+	for _, tt := range []struct{ variant, arch string }{
+		{"classic", "c7a"},
+		{"gchp", "graviton4"},
+	} {
+		name := "Dockerfile." + tt.variant + "-" + tt.arch
+		t.Run(name, func(t *testing.T) {
+			got, err := Dockerfile(app, tt.variant, tt.arch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", name)
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Dockerfile differs from %s (run go test -update after checking the change)", golden)
+			}
+		})
+	}
+}
+
+func TestDockerfileErrors(t *testing.T) {
+	app := loadGeosChem(t)
+
+	cv := app.Containers.Variants["classic"]
+	cv.Install = nil
+	app.Containers.Variants["classic"] = cv
+	if _, err := Dockerfile(app, "classic", "c7a"); err == nil {
+		t.Error("expected an error for a variant without install commands")
+	}
+
+	cv.Dockerfile = "containers/classic/Dockerfile"
+	app.Containers.Variants["classic"] = cv
+	if _, err := Dockerfile(app, "classic", "c7a"); err == nil {
+		t.Error("expected an error for a variant with its own dockerfile")
+	}
+}
+
+func TestRunWritesGeneratedDockerfile(t *testing.T) {
+	app := loadGeosChem(t)
+	b := NewBuilder(appDir, "", false)
+	b.BuildDir = t.TempDir()
+	b.Runner = &fakeRunner{}
+
+	build, err := b.Build(app, "classic", "c7a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Run(context.Background(), build, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(build.Dockerfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, build.Generated) {
+		t.Error("written Dockerfile differs from the generated one")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package container builds and names application container images
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/command"
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// Registry returns the registry host for ContainerSpec.Registry. ECR hosts
+// need the account and region; an empty result means the local image store.
+func Registry(registry, accountID, region string) string {
+	switch registry {
+	case "ecr":
+		if accountID == "" || region == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com", accountID, region)
+	case "dockerhub", "":
+		return ""
+	default:
+		// Any other value is taken as a registry host
+		return registry
+	}
+}
+
+// AccountID returns the AWS account of the current credentials, for ECR
+// registry hosts
+func AccountID(ctx context.Context, r command.Runner) (string, error) {
+	out, err := command.Output(ctx, r, "aws", "sts", "get-caller-identity", "--query", "Account", "--output", "text")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up AWS account: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ImageRef returns the image reference for an application variant built
+// for an architecture, prefixed with host when one is given
+func ImageRef(app *config.Application, host, variant, arch string) string {
+	ref := fmt.Sprintf("%s:%s-%s", app.Containers.Repository, variant, arch)
+	if host == "" {
+		return ref
+	}
+	return host + "/" + ref
+}
+
+// Platform returns the container platform for an architecture family
+func Platform(family string) string {
+	if family == "arm" {
+		return "linux/arm64"
+	}
+	return "linux/amd64"
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of one build in a matrix
+type Result struct {
+	Build    *Build
+	Err      error
+	Duration time.Duration
+	Digest   string // empty if the build failed or buildx did not report one
+}
+
+// RunMatrix runs builds with at most concurrency running at once. A failed
+// build does not stop the others; each outcome is in the returned results,
+// which are in the order of builds. Output is written to w, each line
+// prefixed with its build when more than one runs at a time.
+func (b *Builder) RunMatrix(ctx context.Context, builds []*Build, concurrency int, w io.Writer) []Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	metaDir, err := os.MkdirTemp("", "aws-hpc-build-")
+	if err != nil {
+		metaDir = ""
+	} else {
+		defer os.RemoveAll(metaDir)
+	}
+
+	var mu sync.Mutex // serializes writes to w
+	results := make([]Result, len(builds))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, build := range builds {
+		wg.Add(1)
+		go func(i int, build *Build) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = Result{Build: build, Err: ctx.Err()}
+				return
+			}
+
+			out := io.Writer(&lockedWriter{mu: &mu, w: w})
+			if concurrency > 1 {
+				out = &prefixWriter{mu: &mu, w: w, prefix: fmt.Sprintf("[%s-%s] ", build.Variant, build.Architecture)}
+			}
+			if metaDir != "" {
+				build.MetadataFile = filepath.Join(metaDir, fmt.Sprintf("%s-%s.json", build.Variant, build.Architecture))
+			}
+
+			start := time.Now()
+			err := b.Run(ctx, build, out)
+			if f, ok := out.(*prefixWriter); ok {
+				f.flush()
+			}
+			results[i] = Result{Build: build, Err: err, Duration: time.Since(start)}
+			if err == nil && build.MetadataFile != "" {
+				results[i].Digest = readDigest(build.MetadataFile)
+			}
+		}(i, build)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// readDigest returns the image digest from a buildx metadata file, or ""
+// if it is missing
+func readDigest(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var meta struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return ""
+	}
+	return meta.Digest
+}
+
+// lockedWriter serializes writes from concurrent builds
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// prefixWriter writes whole lines, each prefixed with the build name, so
+// output from concurrent builds stays readable
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf.Write(data)
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := p.buf.Next(i + 1)
+		p.mu.Lock()
+		_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+		p.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// flush writes a trailing partial line
+func (p *prefixWriter) flush() {
+	if p.buf.Len() == 0 {
+		return
+	}
+	p.mu.Lock()
+	fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf.Bytes())
+	p.mu.Unlock()
+	p.buf.Reset()
+}
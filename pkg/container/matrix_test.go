@@ -0,0 +1,138 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowRunner tracks how many builds run at once and writes a buildx
+// metadata file for each successful build
+type slowRunner struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	fail    string
+}
+
+func (s *slowRunner) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.max {
+		s.max = s.running
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	fmt.Fprintf(stdout, "building %s\n", args[len(args)-1])
+
+	if strings.Contains(strings.Join(args, " "), s.fail) {
+		return errors.New("exit status 1")
+	}
+	for i, arg := range args {
+		if arg == "--metadata-file" {
+			meta := `{"containerimage.digest": "sha256:0123456789abcdef"}`
+			if err := os.WriteFile(args[i+1], []byte(meta), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestBuildsFilter(t *testing.T) {
+	app := loadGeosChem(t)
+	b := NewBuilder(appDir, "", false)
+
+	builds, err := b.Builds(app, Filter{Variants: []string{"classic"}, Families: []string{"amd"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, build := range builds {
+		got = append(got, build.Variant+"-"+build.Architecture)
+	}
+	if want := "classic-c7a classic-c6a classic-c5a"; strings.Join(got, " ") != want {
+		t.Errorf("builds = %s, want %s", strings.Join(got, " "), want)
+	}
+
+	if _, err := b.Builds(app, Filter{Variants: []string{"hybrid"}}); err == nil {
+		t.Error("expected an error for an unknown variant")
+	}
+	if _, err := b.Builds(app, Filter{Families: []string{"power"}}); err == nil {
+		t.Error("expected an error for a family with no architectures")
+	}
+}
+
+func TestRunMatrix(t *testing.T) {
+	app := loadGeosChem(t)
+	runner := &slowRunner{fail: "classic-c6a"}
+	b := NewBuilder(appDir, "", false)
+	b.BuildDir = t.TempDir()
+	b.Runner = runner
+
+	builds, err := b.Builds(app, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	results := b.RunMatrix(context.Background(), builds, 3, &out)
+
+	if runner.max > 3 {
+		t.Errorf("ran %d builds at once, limit is 3", runner.max)
+	}
+	if len(results) != len(builds) {
+		t.Fatalf("got %d results, want %d", len(results), len(builds))
+	}
+
+	failed := 0
+	for i, r := range results {
+		if r.Build != builds[i] {
+			t.Errorf("result %d is for %s, want %s", i, r.Build.Tag, builds[i].Tag)
+		}
+		if r.Err != nil {
+			failed++
+			if r.Build.Alias != "geos-chem:classic-c6a" {
+				t.Errorf("unexpected failure of %s: %v", r.Build.Tag, r.Err)
+			}
+			continue
+		}
+		if r.Digest != "sha256:0123456789abcdef" {
+			t.Errorf("%s digest = %q", r.Build.Tag, r.Digest)
+		}
+	}
+	if failed != 1 {
+		t.Errorf("got %d failed builds, want 1", failed)
+	}
+
+	if !strings.Contains(out.String(), "[gchp-graviton4] building ") {
+		t.Errorf("output lines are not prefixed with their build:\n%s", out.String())
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// Image is the tags for one variant built for one architecture
+//
+//	<repository>:<variant>-<version>-<arch>[-<base tag>]  immutable
+//	<repository>:<variant>-<arch>                         moving alias
+//
+// The base image tag is included when it pins a build, so it is left out
+// for "latest" or untagged base images.
+type Image struct {
+	Variant      string
+	Architecture string
+	Platform     string
+	Ref          string
+	Alias        string
+}
+
+// ManifestList is the multi-platform tag for a variant
+//
+//	<repository>:<variant>-<version>  immutable
+//	<repository>:<variant>            moving alias
+//
+// A manifest list holds one image per platform, so each platform is served
+// by the first of its architectures in app.yaml. Jobs that must run the
+// image tuned for another architecture reference its own tag.
+type ManifestList struct {
+	Variant string
+	Ref     string
+	Alias   string
+	Images  []Image
+}
+
+// Images returns the tags of every variant for every architecture, in
+// app.yaml order, for the registry host (empty for local images)
+func Images(app *config.Application, host string) []Image {
+	var images []Image
+	for _, v := range app.Variants {
+		for _, a := range app.Compute.Architectures {
+			images = append(images, imageFor(app, host, v.Name, &a))
+		}
+	}
+	return images
+}
+
+// ImageFor returns the tags of a variant built for an architecture
+func ImageFor(app *config.Application, host, variant, arch string) (Image, error) {
+	a, err := app.GetArchitecture(arch)
+	if err != nil {
+		return Image{}, err
+	}
+	return imageFor(app, host, variant, a), nil
+}
+
+func imageFor(app *config.Application, host, variant string, a *config.Architecture) Image {
+	parts := []string{variant, app.Version, a.Name}
+	if base := BaseImageTag(a.BaseImage); base != "" && base != "latest" {
+		parts = append(parts, base)
+	}
+	return Image{
+		Variant:      variant,
+		Architecture: a.Name,
+		Platform:     Platform(a.Family),
+		Ref:          reference(app, host, tag(parts...)),
+		Alias:        ImageRef(app, host, variant, a.Name),
+	}
+}
+
+// ManifestLists returns the multi-platform tags for every variant
+func ManifestLists(app *config.Application, host string) []ManifestList {
+	var lists []ManifestList
+	for _, v := range app.Variants {
+		list := ManifestList{
+			Variant: v.Name,
+			Ref:     reference(app, host, tag(v.Name, app.Version)),
+			Alias:   reference(app, host, tag(v.Name)),
+		}
+		seen := map[string]bool{}
+		for _, a := range app.Compute.Architectures {
+			img := imageFor(app, host, v.Name, &a)
+			if !seen[img.Platform] {
+				seen[img.Platform] = true
+				list.Images = append(list.Images, img)
+			}
+		}
+		lists = append(lists, list)
+	}
+	return lists
+}
+
+// BaseImageTag returns the tag of an image reference, or "" if it has none
+func BaseImageTag(ref string) string {
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || strings.Contains(ref[i:], "/") {
+		return ""
+	}
+	return ref[i+1:]
+}
+
+func reference(app *config.Application, host, tag string) string {
+	ref := app.Containers.Repository + ":" + tag
+	if host == "" {
+		return ref
+	}
+	return host + "/" + ref
+}
+
+// tag joins parts with "-", replacing characters Docker does not allow in
+// tags, such as "+" in semver build metadata
+func tag(parts ...string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, strings.Join(parts, "-"))
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestImageFor(t *testing.T) {
+	app := loadGeosChem(t)
+	app.Version = "1.2.0+build.7"
+	app.Compute.Architectures[0].BaseImage = "hpc-base-amd-zen4:2025.01.15"
+
+	img, err := ImageFor(app, "registry.example.com", "classic", "c7a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "registry.example.com/geos-chem:classic-1.2.0-build.7-c7a-2025.01.15"; img.Ref != want {
+		t.Errorf("Ref = %s, want %s", img.Ref, want)
+	}
+	if want := "registry.example.com/geos-chem:classic-c7a"; img.Alias != want {
+		t.Errorf("Alias = %s, want %s", img.Alias, want)
+	}
+
+	// latest base images do not pin the build, so they are left out
+	img, err = ImageFor(app, "", "gchp", "c6a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "geos-chem:gchp-1.2.0-build.7-c6a"; img.Ref != want {
+		t.Errorf("Ref = %s, want %s", img.Ref, want)
+	}
+
+	if _, err := ImageFor(app, "", "classic", "m7i"); err == nil {
+		t.Error("expected an error for an unknown architecture")
+	}
+}
+
+func TestBaseImageTag(t *testing.T) {
+	tests := map[string]string{
+		"hpc-base-amd-zen4:latest":                "latest",
+		"hpc-base-amd-zen4":                       "",
+		"localhost:5000/hpc-base-amd-zen4":        "",
+		"localhost:5000/hpc-base-amd-zen4:2025.1": "2025.1",
+	}
+	for ref, want := range tests {
+		if got := BaseImageTag(ref); got != want {
+			t.Errorf("BaseImageTag(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestManifestLists(t *testing.T) {
+	app := loadGeosChem(t)
+	lists := ManifestLists(app, "")
+	if len(lists) != len(app.Variants) {
+		t.Fatalf("got %d manifest lists, want %d", len(lists), len(app.Variants))
+	}
+
+	list := lists[0]
+	if want := "geos-chem:classic-" + app.Version; list.Ref != want {
+		t.Errorf("Ref = %s, want %s", list.Ref, want)
+	}
+	if list.Alias != "geos-chem:classic" {
+		t.Errorf("Alias = %s, want geos-chem:classic", list.Alias)
+	}
+	var platforms []string
+	for _, img := range list.Images {
+		platforms = append(platforms, img.Platform+"="+img.Architecture)
+	}
+	if want := []string{"linux/amd64=c7a", "linux/arm64=graviton4"}; !reflect.DeepEqual(platforms, want) {
+		t.Errorf("images = %q, want %q", platforms, want)
+	}
+
+	runner := &fakeRunner{}
+	b := NewBuilder(appDir, "", true)
+	b.Runner = runner
+	if err := b.CreateManifest(context.Background(), list, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"docker", "buildx", "imagetools", "create",
+		"--tag", list.Ref, "--tag", list.Alias, list.Images[0].Ref, list.Images[1].Ref}
+	if !reflect.DeepEqual(runner.calls[0], want) {
+		t.Errorf("ran %q, want %q", runner.calls[0], want)
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cost estimates what an application's jobs cost to run
+package cost
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/pricing"
+)
+
+// RuntimeScaling estimates from a baseline run: runtime scales with each
+// architecture's scaling factor and inversely with vCPUs. Instances are
+// priced from the pricing catalog, or else at the baseline's price per
+// vCPU-hour.
+const RuntimeScaling = "runtime_scaling"
+
+// HoursPerMonth converts monthly storage prices to hourly ones
+const HoursPerMonth = 730
+
+// Rates are the storage and transfer prices estimates use, in USD
+type Rates struct {
+	EBSPerGBMonth   map[string]float64 // by volume type
+	EBSPerIOPSMonth map[string]float64 // provisioned IOPS above the free baseline, by volume type
+	EBSFreeIOPS     map[string]int     // IOPS included with the volume, by volume type
+	S3TransferPerGB float64            // data moved between S3 and the job
+}
+
+// DefaultRates are us-east-1 list prices. S3 transfer is priced at the
+// inter-region rate, so that estimates hold wherever the buckets are.
+var DefaultRates = Rates{
+	EBSPerGBMonth:   map[string]float64{"gp3": 0.08, "gp2": 0.10, "io1": 0.125, "io2": 0.125},
+	EBSPerIOPSMonth: map[string]float64{"gp3": 0.005, "io1": 0.065, "io2": 0.065},
+	EBSFreeIOPS:     map[string]int{"gp3": 3000},
+	S3TransferPerGB: 0.02,
+}
+
+// Request describes the job to estimate
+type Request struct {
+	Architecture string  // default: the baseline's
+	VCPUs        int     // smallest instance with at least this many; default: the baseline's size
+	RuntimeHours float64 // measured or expected runtime; default: scaled from the baseline
+	InputGB      float64 // read from the S3 input
+	OutputGB     float64 // written to the S3 output
+}
+
+// Estimate is the cost of one job, broken down
+type Estimate struct {
+	Architecture  string
+	InstanceType  string
+	VCPUs         int
+	ScalingFactor float64
+	RuntimeHours  float64
+	HourlyPrice   float64
+	PriceSource   string // on-demand or spot from the catalog, or baseline
+
+	Compute  float64
+	Scratch  float64 // EBS scratch volume for the runtime
+	Transfer float64 // S3 input and output
+}
+
+// Total returns the cost of the job
+func (e *Estimate) Total() float64 {
+	return e.Compute + e.Scratch + e.Transfer
+}
+
+// Calculator estimates job costs for an application
+type Calculator struct {
+	App   *config.Application
+	Rates Rates
+
+	// Catalog prices instances in Region, at spot prices if Spot and the
+	// catalog has them. Without a catalog, or for instance types it does
+	// not price, prices scale from the baseline.
+	Catalog *pricing.Catalog
+	Region  string
+	Spot    bool
+}
+
+// NewCalculator creates a calculator with the default rates and no
+// pricing catalog
+func NewCalculator(app *config.Application) *Calculator {
+	return &Calculator{App: app, Rates: DefaultRates}
+}
+
+// Estimate estimates the cost of a job
+func (c *Calculator) Estimate(req Request) (*Estimate, error) {
+	spec := c.App.Cost
+	if spec.EstimateMethod != RuntimeScaling {
+		return nil, fmt.Errorf("unsupported estimate method %q; only %s is supported", spec.EstimateMethod, RuntimeScaling)
+	}
+	baseVCPUs := c.vcpus(spec.Baseline.Architecture)
+	if baseVCPUs == 0 {
+		return nil, fmt.Errorf("cannot tell the vCPUs of baseline instance type %q", spec.Baseline.Architecture)
+	}
+	if spec.Baseline.RuntimeHours <= 0 || spec.Baseline.CostPerHour <= 0 {
+		return nil, fmt.Errorf("cost baseline needs runtime_hours and cost_per_hour")
+	}
+
+	baseArch := c.baselineArchitecture()
+	archName := req.Architecture
+	if archName == "" {
+		archName = baseArch
+	}
+	arch, err := c.App.GetArchitecture(archName)
+	if err != nil {
+		return nil, err
+	}
+	factor, ok := spec.ScalingFactors[archName]
+	if !ok {
+		if archName != baseArch {
+			return nil, fmt.Errorf("no scaling factor for architecture %s", archName)
+		}
+		factor = 1
+	}
+
+	vcpus := req.VCPUs
+	if vcpus == 0 {
+		vcpus = baseVCPUs
+	}
+	instance, instanceVCPUs := c.fit(arch.InstanceTypes, vcpus)
+	if instance == "" {
+		return nil, fmt.Errorf("no %s instance type has %d vCPUs", archName, vcpus)
+	}
+
+	e := &Estimate{
+		Architecture:  archName,
+		InstanceType:  instance,
+		VCPUs:         instanceVCPUs,
+		ScalingFactor: factor,
+		RuntimeHours:  req.RuntimeHours,
+	}
+	e.HourlyPrice, e.PriceSource = c.price(instance)
+	if e.PriceSource == "baseline" {
+		e.HourlyPrice = spec.Baseline.CostPerHour * float64(instanceVCPUs) / float64(baseVCPUs)
+	}
+	if e.RuntimeHours == 0 {
+		e.RuntimeHours = spec.Baseline.RuntimeHours * factor * float64(baseVCPUs) / float64(instanceVCPUs)
+	}
+	e.Compute = e.RuntimeHours * e.HourlyPrice
+	e.Scratch = c.scratch(e.RuntimeHours)
+	e.Transfer = c.transfer(req.InputGB, req.OutputGB)
+	return e, nil
+}
+
+// Compare estimates a job on each of the application's architectures. The
+// errors are for architectures that cannot be estimated, such as those
+// without a scaling factor.
+func (c *Calculator) Compare(req Request) ([]*Estimate, []error) {
+	var estimates []*Estimate
+	var errs []error
+	for _, arch := range c.App.Compute.Architectures {
+		req.Architecture = arch.Name
+		e, err := c.Estimate(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		estimates = append(estimates, e)
+	}
+	return estimates, errs
+}
+
+// baselineArchitecture returns the architecture with the baseline's
+// instance type, whose scaling factor is 1 unless it has one
+func (c *Calculator) baselineArchitecture() string {
+	for _, arch := range c.App.Compute.Architectures {
+		for _, t := range arch.InstanceTypes {
+			if t == c.App.Cost.Baseline.Architecture {
+				return arch.Name
+			}
+		}
+	}
+	return ""
+}
+
+// scratch returns the cost of the scratch EBS volume for a runtime
+func (c *Calculator) scratch(hours float64) float64 {
+	s := c.App.Storage.Scratch
+	if s.Type != "ebs" {
+		return 0
+	}
+	volumeType := s.VolumeType
+	if volumeType == "" {
+		volumeType = "gp3"
+	}
+	monthly := float64(s.SizeGB) * c.Rates.EBSPerGBMonth[volumeType]
+	if extra := s.IOPS - c.Rates.EBSFreeIOPS[volumeType]; extra > 0 {
+		monthly += float64(extra) * c.Rates.EBSPerIOPSMonth[volumeType]
+	}
+	return monthly * hours / HoursPerMonth
+}
+
+// transfer returns the cost of moving data between S3 and the job
+func (c *Calculator) transfer(inputGB, outputGB float64) float64 {
+	var gb float64
+	if c.App.Storage.Input.Type == "s3" {
+		gb += inputGB
+	}
+	if c.App.Storage.Output.Type == "s3" {
+		gb += outputGB
+	}
+	return gb * c.Rates.S3TransferPerGB
+}
+
+// price returns the catalog's hourly price of an instance type and which
+// price it is, or "baseline" if the catalog does not price it
+func (c *Calculator) price(instanceType string) (float64, string) {
+	if c.Catalog == nil {
+		return 0, "baseline"
+	}
+	region := c.Region
+	if region == "" {
+		region = pricing.DefaultRegion
+	}
+	p, err := c.Catalog.Price(region, instanceType)
+	if err != nil {
+		return 0, "baseline"
+	}
+	if c.Spot && p.Spot > 0 {
+		return p.Spot, "spot"
+	}
+	return p.OnDemand, "on-demand"
+}
+
+// vcpus returns the vCPUs of an instance type from the catalog, or else
+// from its size
+func (c *Calculator) vcpus(instanceType string) int {
+	if c.Catalog != nil {
+		if i, ok := c.Catalog.Instance(instanceType); ok && i.VCPUs > 0 {
+			return i.VCPUs
+		}
+	}
+	return InstanceVCPUs(instanceType)
+}
+
+// fit returns the smallest instance type with at least vcpus vCPUs
+func (c *Calculator) fit(instanceTypes []string, vcpus int) (string, int) {
+	best, bestVCPUs := "", 0
+	for _, t := range instanceTypes {
+		n := c.vcpus(t)
+		if n >= vcpus && (best == "" || n < bestVCPUs) {
+			best, bestVCPUs = t, n
+		}
+	}
+	return best, bestVCPUs
+}
+
+// InstanceVCPUs returns the vCPU count implied by an EC2 instance size
+// (large = 2, xlarge = 4, 2xlarge = 8, ...), or zero if it is not known
+func InstanceVCPUs(instanceType string) int {
+	_, size, ok := strings.Cut(instanceType, ".")
+	if !ok {
+		return 0
+	}
+	switch {
+	case size == "large":
+		return 2
+	case size == "xlarge":
+		return 4
+	case strings.HasSuffix(size, "xlarge"):
+		n, err := strconv.Atoi(strings.TrimSuffix(size, "xlarge"))
+		if err != nil {
+			return 0
+		}
+		return n * 4
+	}
+	return 0
+}
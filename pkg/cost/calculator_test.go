@@ -0,0 +1,184 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/pricing"
+)
+
+func geosChem(t *testing.T) *config.Application {
+	t.Helper()
+	app, err := config.LoadApplication("../../applications/geos-chem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestEstimate(t *testing.T) {
+	// geos-chem's baseline is 2.5 hours on a c7a.2xlarge at $0.3468/hour,
+	// with a 100 GB gp3 scratch volume at the free 3000 IOPS: $8/month
+	tests := []struct {
+		name     string
+		req      Request
+		instance string
+		runtime  float64
+		price    float64
+		compute  float64
+		scratch  float64
+		transfer float64
+	}{
+		{"baseline", Request{},
+			"c7a.2xlarge", 2.5, 0.3468, 0.867, 8 * 2.5 / 730, 0},
+		{"scaling factor", Request{Architecture: "graviton4"},
+			"c8g.2xlarge", 1.75, 0.3468, 0.6069, 8 * 1.75 / 730, 0},
+		{"larger instance", Request{Architecture: "c7a", VCPUs: 16},
+			"c7a.4xlarge", 1.25, 0.6936, 0.867, 8 * 1.25 / 730, 0},
+		{"vcpus round up", Request{Architecture: "c6a", VCPUs: 12},
+			"c6a.4xlarge", 0.95 * 1.25, 0.6936, 0.95 * 0.867, 8 * 0.95 * 1.25 / 730, 0},
+		{"runtime", Request{Architecture: "graviton4", VCPUs: 16, RuntimeHours: 4},
+			"c8g.4xlarge", 4, 0.6936, 2.7744, 8 * 4.0 / 730, 0},
+		{"transfer", Request{InputGB: 10, OutputGB: 50},
+			"c7a.2xlarge", 2.5, 0.3468, 0.867, 8 * 2.5 / 730, 1.2},
+	}
+
+	calc := NewCalculator(geosChem(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := calc.Estimate(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.InstanceType != tt.instance {
+				t.Errorf("instance = %s, want %s", e.InstanceType, tt.instance)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"runtime", e.RuntimeHours, tt.runtime},
+				{"price", e.HourlyPrice, tt.price},
+				{"compute", e.Compute, tt.compute},
+				{"scratch", e.Scratch, tt.scratch},
+				{"transfer", e.Transfer, tt.transfer},
+				{"total", e.Total(), tt.compute + tt.scratch + tt.transfer},
+			} {
+				if math.Abs(f.got-f.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateErrors(t *testing.T) {
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{Architecture: "c7i"}, "no scaling factor for architecture c7i"},
+		{Request{Architecture: "c7a", VCPUs: 64}, "no c7a instance type has 64 vCPUs"},
+		{Request{Architecture: "power9"}, "architecture power9 not found"},
+	}
+	calc := NewCalculator(geosChem(t))
+	for _, tt := range tests {
+		if _, err := calc.Estimate(tt.req); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Estimate(%+v) error = %v, want %q", tt.req, err, tt.want)
+		}
+	}
+
+	app := geosChem(t)
+	app.Cost.EstimateMethod = "benchmark"
+	if _, err := NewCalculator(app).Estimate(Request{}); err == nil {
+		t.Error("expected an error for an unsupported estimate method")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	estimates, errs := NewCalculator(geosChem(t)).Compare(Request{})
+	var archs []string
+	for _, e := range estimates {
+		archs = append(archs, e.Architecture)
+	}
+	if got := strings.Join(archs, " "); got != "c7a c6a c5a graviton4 graviton3" {
+		t.Errorf("estimated architectures = %s", got)
+	}
+	if len(errs) != 4 {
+		t.Errorf("errors = %v, want one for each architecture without a scaling factor", errs)
+	}
+}
+
+func TestInstanceVCPUs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"c7a.large", 2},
+		{"c7a.xlarge", 4},
+		{"c7a.2xlarge", 8},
+		{"c8g.48xlarge", 192},
+		{"c7a.metal", 0},
+		{"c7a", 0},
+	}
+	for _, tt := range tests {
+		if got := InstanceVCPUs(tt.in); got != tt.want {
+			t.Errorf("InstanceVCPUs(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateCatalog(t *testing.T) {
+	calc := NewCalculator(geosChem(t))
+	calc.Catalog = &pricing.Catalog{
+		Instances: map[string]pricing.Instance{"c8g.2xlarge": {VCPUs: 8}},
+		Regions: map[string]map[string]pricing.Price{
+			"us-west-2": {
+				"c7a.2xlarge": {OnDemand: 0.41056, Spot: 0.18},
+				"c8g.2xlarge": {OnDemand: 0.31904},
+			},
+		},
+	}
+	calc.Region = "us-west-2"
+
+	tests := []struct {
+		arch   string
+		spot   bool
+		price  float64
+		source string
+	}{
+		{"c7a", false, 0.41056, "on-demand"},
+		{"c7a", true, 0.18, "spot"},
+		{"graviton4", true, 0.31904, "on-demand"}, // no spot price
+		{"c6a", false, 0.3468, "baseline"},        // not in the catalog
+	}
+	for _, tt := range tests {
+		calc.Spot = tt.spot
+		e, err := calc.Estimate(Request{Architecture: tt.arch})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.HourlyPrice != tt.price || e.PriceSource != tt.source {
+			t.Errorf("%s (spot %v): price = %v %s, want %v %s", tt.arch, tt.spot, e.HourlyPrice, e.PriceSource, tt.price, tt.source)
+		}
+		if math.Abs(e.Compute-e.RuntimeHours*tt.price) > 1e-9 {
+			t.Errorf("%s: compute = %v, want runtime %v at %v", tt.arch, e.Compute, e.RuntimeHours, tt.price)
+		}
+	}
+}
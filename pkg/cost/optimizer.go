@@ -0,0 +1,107 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import "fmt"
+
+// Recommendation is a change that lowers the cost of an application's jobs
+type Recommendation struct {
+	Title   string
+	Detail  string
+	Savings float64 // per job, or zero if not estimated
+}
+
+// Recommend suggests ways to lower the cost of a job. The savings of each
+// recommendation are on top of those before it, so they add up.
+func (c *Calculator) Recommend(req Request) ([]Recommendation, error) {
+	current, err := c.Estimate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []Recommendation
+	best := current
+	estimates, _ := c.Compare(req)
+	for _, e := range estimates {
+		if e.Total() < best.Total() {
+			best = e
+		}
+	}
+	if best != current {
+		recs = append(recs, Recommendation{
+			Title: fmt.Sprintf("Run on %s (%s) instead of %s", best.Architecture, best.InstanceType, current.Architecture),
+			Detail: fmt.Sprintf("$%.2f per job instead of $%.2f (%.0f%% less)",
+				best.Total(), current.Total(), percent(current.Total()-best.Total(), current.Total())),
+			Savings: current.Total() - best.Total(),
+		})
+	}
+
+	if !c.Spot && c.Catalog != nil {
+		spotCalc := *c
+		spotCalc.Spot = true
+		req.Architecture = best.Architecture
+		if spot, err := spotCalc.Estimate(req); err == nil && spot.PriceSource == "spot" {
+			detail := fmt.Sprintf("$%.2f per job on %s at spot prices (%.0f%% less); jobs must tolerate interruption",
+				spot.Total(), spot.InstanceType, percent(best.Total()-spot.Total(), best.Total()))
+			if q := c.spotQueue(best.Architecture); q != "" {
+				detail += fmt.Sprintf(". Submit to queue %s, which runs spot", q)
+			} else {
+				detail += fmt.Sprintf(". Add a spot compute environment for %s to a job queue", best.Architecture)
+			}
+			recs = append(recs, Recommendation{
+				Title:   "Use spot instances",
+				Detail:  detail,
+				Savings: best.Total() - spot.Total(),
+			})
+		}
+	}
+
+	if out := c.App.Storage.Output; out.Type == "s3" && (out.Lifecycle == nil || out.Lifecycle.TransitionIA == 0 && out.Lifecycle.TransitionGlacier == 0) {
+		recs = append(recs, Recommendation{
+			Title:  "Add S3 lifecycle transitions to the output bucket",
+			Detail: "Set storage.output.lifecycle.transition_ia and transition_glacier to move old results to cheaper storage classes",
+		})
+	}
+	return recs, nil
+}
+
+// spotQueue returns the first job queue with a spot compute environment
+// that runs the architecture
+func (c *Calculator) spotQueue(arch string) string {
+	for _, q := range c.App.Compute.Batch.Queues {
+		for _, ce := range q.ComputeEnvironments {
+			if ce.Type == "spot" && contains(ce.Architectures, arch) {
+				return q.Name
+			}
+		}
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func percent(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole * 100
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/pricing"
+)
+
+func TestRecommend(t *testing.T) {
+	app := geosChem(t)
+	app.Storage.Output.Lifecycle = nil
+	calc := NewCalculator(app)
+	calc.Catalog = &pricing.Catalog{Regions: map[string]map[string]pricing.Price{
+		"us-east-1": {
+			"c7a.2xlarge": {OnDemand: 0.41056, Spot: 0.18},
+			"c8g.2xlarge": {OnDemand: 0.31904, Spot: 0.14},
+		},
+	}}
+
+	recs, err := calc.Recommend(Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, r := range recs {
+		titles = append(titles, r.Title)
+	}
+	want := []string{
+		"Run on graviton4 (c8g.2xlarge) instead of c7a",
+		"Use spot instances",
+		"Add S3 lifecycle transitions to the output bucket",
+	}
+	if strings.Join(titles, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("recommendations:\n%s\nwant:\n%s", strings.Join(titles, "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.Contains(recs[1].Detail, "queue geos-chem-spot-graviton") {
+		t.Errorf("spot detail = %s, want the queue that runs graviton4 on spot", recs[1].Detail)
+	}
+
+	// Savings add up to the difference between the baseline on demand and
+	// graviton4 on spot
+	current, _ := calc.Estimate(Request{})
+	calc.Spot = true
+	best, _ := calc.Estimate(Request{Architecture: "graviton4"})
+	if got := recs[0].Savings + recs[1].Savings + recs[2].Savings; math.Abs(got-(current.Total()-best.Total())) > 1e-9 {
+		t.Errorf("total savings = %v, want %v", got, current.Total()-best.Total())
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// ScratchDevice is the block device an EBS scratch volume is attached as
+const ScratchDevice = "/dev/xvdb"
+
+// SharedPath is where shared storage is mounted on hosts and in containers
+const SharedPath = "/shared"
+
+// userDataBoundary separates the MIME parts of launch template user data
+const userDataBoundary = "==AWSHPC=="
+
+// UserData returns the launch template user data that prepares a compute
+// environment host: it formats and mounts scratch storage at ScratchPath
+// and mounts shared storage at SharedPath. sharedSource is the NFS or
+// Lustre mount source for app.Storage.Shared and is ignored without it.
+//
+// The script contains no "${" sequences, so deploy templates can embed it
+// in CloudFormation Fn::Sub or Terraform strings that fill in sharedSource.
+func UserData(app *config.Application, sharedSource string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "MIME-Version: 1.0\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=\"%s\"\n\n", userDataBoundary)
+	fmt.Fprintf(&b, "--%s\n", userDataBoundary)
+	fmt.Fprintf(&b, "Content-Type: text/x-shellscript; charset=\"us-ascii\"\n\n")
+	fmt.Fprintf(&b, "#!/bin/bash\nset -euo pipefail\n\n")
+
+	fmt.Fprintf(&b, "mkdir -p %s\n", ScratchPath)
+	switch app.Storage.Scratch.Type {
+	case "ebs":
+		fmt.Fprintf(&b, "if [ -b %s ]; then\n", ScratchDevice)
+		fmt.Fprintf(&b, "  mkfs -t xfs %s\n", ScratchDevice)
+		fmt.Fprintf(&b, "  mount %s %s\n", ScratchDevice, ScratchPath)
+		fmt.Fprintf(&b, "fi\n")
+	case "instance-store":
+		fmt.Fprintf(&b, "dev=$(lsblk -dpno NAME,MODEL | awk '/Instance Storage/ {print $1; exit}')\n")
+		fmt.Fprintf(&b, "if [ -n \"$dev\" ]; then\n")
+		fmt.Fprintf(&b, "  mkfs -t xfs \"$dev\"\n")
+		fmt.Fprintf(&b, "  mount \"$dev\" %s\n", ScratchPath)
+		fmt.Fprintf(&b, "fi\n")
+	}
+	fmt.Fprintf(&b, "chmod 1777 %s\n", ScratchPath)
+
+	if shared := app.Storage.Shared; shared != nil && sharedSource != "" {
+		fmt.Fprintf(&b, "\nmkdir -p %s\n", SharedPath)
+		switch shared.Type {
+		case "efs":
+			fmt.Fprintf(&b, "mount -t nfs4 -o nfsvers=4.1,rsize=1048576,wsize=1048576,hard,timeo=600,retrans=2,noresvport %s %s\n",
+				sharedSource, SharedPath)
+		case "fsx-lustre":
+			fmt.Fprintf(&b, "dnf install -y lustre-client\n")
+			fmt.Fprintf(&b, "mount -t lustre -o relatime,flock %s %s\n", sharedSource, SharedPath)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n--%s--\n", userDataBoundary)
+	return b.String()
+}
@@ -0,0 +1,413 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package batch renders AWS Batch compute environments, job queues and job
+// definitions from an application's ComputeSpec
+package batch
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/container"
+	"github.com/aws-hpc/platform/pkg/job"
+)
+
+const (
+	// DefaultNumNodes is the node count for multi-node job definitions
+	DefaultNumNodes = 2
+
+	// ScratchPath is where scratch storage is mounted on hosts and in
+	// containers
+	ScratchPath = "/scratch"
+)
+
+// Options holds the account-specific values the renderer cannot derive
+// from app.yaml. Empty fields are left out of the rendered documents.
+type Options struct {
+	AccountID        string
+	Region           string
+	Subnets          []string
+	SecurityGroupIDs []string
+	InstanceRole     string
+	ServiceRole      string
+	JobRoleArn       string
+}
+
+// Documents are the request payloads for the AWS Batch Create/Register APIs
+type Documents struct {
+	ComputeEnvironments []ComputeEnvironment `json:"computeEnvironments"`
+	JobQueues           []JobQueue           `json:"jobQueues"`
+	JobDefinitions      []JobDefinition      `json:"jobDefinitions"`
+}
+
+// ComputeEnvironment is a CreateComputeEnvironment request
+type ComputeEnvironment struct {
+	ComputeEnvironmentName string            `json:"computeEnvironmentName"`
+	Type                   string            `json:"type"`
+	State                  string            `json:"state"`
+	ComputeResources       ComputeResources  `json:"computeResources"`
+	ServiceRole            string            `json:"serviceRole,omitempty"`
+	Tags                   map[string]string `json:"tags,omitempty"`
+
+	// Architectures are the app.yaml architectures the environment runs.
+	// They are not part of the API request.
+	Architectures []string `json:"-"`
+}
+
+// ComputeResources describes the instances of a managed compute environment
+type ComputeResources struct {
+	Type               string             `json:"type"`
+	AllocationStrategy string             `json:"allocationStrategy"`
+	MinvCpus           int                `json:"minvCpus"`
+	MaxvCpus           int                `json:"maxvCpus"`
+	InstanceTypes      []string           `json:"instanceTypes"`
+	BidPercentage      int                `json:"bidPercentage,omitempty"`
+	Subnets            []string           `json:"subnets,omitempty"`
+	SecurityGroupIDs   []string           `json:"securityGroupIds,omitempty"`
+	InstanceRole       string             `json:"instanceRole,omitempty"`
+	Ec2Configuration   []Ec2Configuration `json:"ec2Configuration"`
+	Tags               map[string]string  `json:"tags,omitempty"`
+}
+
+// Ec2Configuration selects the ECS-optimized AMI for the instances
+type Ec2Configuration struct {
+	ImageType string `json:"imageType"`
+}
+
+// JobQueue is a CreateJobQueue request
+type JobQueue struct {
+	JobQueueName            string                    `json:"jobQueueName"`
+	State                   string                    `json:"state"`
+	Priority                int                       `json:"priority"`
+	ComputeEnvironmentOrder []ComputeEnvironmentOrder `json:"computeEnvironmentOrder"`
+	Tags                    map[string]string         `json:"tags,omitempty"`
+}
+
+// ComputeEnvironmentOrder places a compute environment in a queue
+type ComputeEnvironmentOrder struct {
+	Order              int    `json:"order"`
+	ComputeEnvironment string `json:"computeEnvironment"`
+}
+
+// JobDefinition is a RegisterJobDefinition request. Single-node variants
+// set ContainerProperties; multi-node variants set NodeProperties.
+type JobDefinition struct {
+	JobDefinitionName    string               `json:"jobDefinitionName"`
+	Type                 string               `json:"type"`
+	PlatformCapabilities []string             `json:"platformCapabilities"`
+	ContainerProperties  *ContainerProperties `json:"containerProperties,omitempty"`
+	NodeProperties       *NodeProperties      `json:"nodeProperties,omitempty"`
+	RetryStrategy        RetryStrategy        `json:"retryStrategy"`
+	PropagateTags        bool                 `json:"propagateTags"`
+	Tags                 map[string]string    `json:"tags,omitempty"`
+
+	// Variant and Architecture identify the app.yaml entries the
+	// definition was rendered from. They are not part of the API request.
+	Variant      string `json:"-"`
+	Architecture string `json:"-"`
+}
+
+// ContainerProperties describes a job's container
+type ContainerProperties struct {
+	Image                string                `json:"image"`
+	ResourceRequirements []ResourceRequirement `json:"resourceRequirements"`
+	Environment          []KeyValuePair        `json:"environment"`
+	Volumes              []Volume              `json:"volumes"`
+	MountPoints          []MountPoint          `json:"mountPoints"`
+	JobRoleArn           string                `json:"jobRoleArn,omitempty"`
+	LinuxParameters      *LinuxParameters      `json:"linuxParameters,omitempty"`
+}
+
+// ResourceRequirement is a VCPU, MEMORY or GPU requirement
+type ResourceRequirement struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// KeyValuePair is a container environment variable
+type KeyValuePair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Volume is a host volume available to the container
+type Volume struct {
+	Name string     `json:"name"`
+	Host VolumeHost `json:"host"`
+}
+
+// VolumeHost is the host path backing a volume
+type VolumeHost struct {
+	SourcePath string `json:"sourcePath"`
+}
+
+// MountPoint mounts a volume in the container
+type MountPoint struct {
+	SourceVolume  string `json:"sourceVolume"`
+	ContainerPath string `json:"containerPath"`
+	ReadOnly      bool   `json:"readOnly"`
+}
+
+// LinuxParameters exposes host devices such as EFA interfaces
+type LinuxParameters struct {
+	Devices []Device `json:"devices,omitempty"`
+}
+
+// Device is a host device mapped into the container
+type Device struct {
+	HostPath      string   `json:"hostPath"`
+	ContainerPath string   `json:"containerPath"`
+	Permissions   []string `json:"permissions"`
+}
+
+// NodeProperties describes a multi-node parallel job
+type NodeProperties struct {
+	NumNodes            int                 `json:"numNodes"`
+	MainNode            int                 `json:"mainNode"`
+	NodeRangeProperties []NodeRangeProperty `json:"nodeRangeProperties"`
+}
+
+// NodeRangeProperty is the container for a range of nodes
+type NodeRangeProperty struct {
+	TargetNodes string              `json:"targetNodes"`
+	Container   ContainerProperties `json:"container"`
+}
+
+// RetryStrategy retries jobs whose spot instances were reclaimed
+type RetryStrategy struct {
+	Attempts       int              `json:"attempts"`
+	EvaluateOnExit []EvaluateOnExit `json:"evaluateOnExit"`
+}
+
+// EvaluateOnExit is a retry condition
+type EvaluateOnExit struct {
+	OnStatusReason string `json:"onStatusReason,omitempty"`
+	OnReason       string `json:"onReason,omitempty"`
+	Action         string `json:"action"`
+}
+
+// Render turns an application's compute and container specs into Batch
+// documents. Queue and compute environment order follows app.yaml.
+func Render(app *config.Application, opts Options) (*Documents, error) {
+	docs := &Documents{}
+	tags := Tags(app)
+
+	maxPriority := 0
+	for _, q := range app.Compute.Batch.Queues {
+		if q.Priority > maxPriority {
+			maxPriority = q.Priority
+		}
+	}
+
+	for _, q := range app.Compute.Batch.Queues {
+		if len(q.ComputeEnvironments) == 0 {
+			return nil, fmt.Errorf("queue %s has no compute environments", q.Name)
+		}
+
+		// app.yaml ranks queues with 1 as the most preferred; Batch
+		// schedules higher priority values first
+		queue := JobQueue{
+			JobQueueName: q.Name,
+			State:        "ENABLED",
+			Priority:     maxPriority - q.Priority + 1,
+			Tags:         tags,
+		}
+
+		n := 0
+		for _, ce := range q.ComputeEnvironments {
+			envs, err := computeEnvironments(app, ce, opts)
+			if err != nil {
+				return nil, fmt.Errorf("queue %s: %w", q.Name, err)
+			}
+			for _, env := range envs {
+				n++
+				env.ComputeEnvironmentName = fmt.Sprintf("%s-%d", q.Name, n)
+				docs.ComputeEnvironments = append(docs.ComputeEnvironments, env)
+				queue.ComputeEnvironmentOrder = append(queue.ComputeEnvironmentOrder, ComputeEnvironmentOrder{
+					Order:              n,
+					ComputeEnvironment: env.ComputeEnvironmentName,
+				})
+			}
+		}
+		docs.JobQueues = append(docs.JobQueues, queue)
+	}
+
+	host := container.Registry(app.Containers.Registry, opts.AccountID, opts.Region)
+	for _, v := range app.Variants {
+		for _, arch := range app.Compute.Architectures {
+			docs.JobDefinitions = append(docs.JobDefinitions,
+				jobDefinition(app, v, arch, host, opts))
+		}
+	}
+
+	return docs, nil
+}
+
+// Tags returns the tags applied to every rendered resource
+func Tags(app *config.Application) map[string]string {
+	return map[string]string{
+		"aws-hpc:app":     app.Name,
+		"aws-hpc:version": app.Version,
+	}
+}
+
+// computeEnvironments renders one app.yaml compute environment. Batch
+// cannot mix x86 and ARM instances, so architectures are split by
+// platform into separate environments, x86 first.
+func computeEnvironments(app *config.Application, ce config.ComputeEnvironment, opts Options) ([]ComputeEnvironment, error) {
+	var resourceType, strategy string
+	switch ce.Type {
+	case "spot":
+		resourceType, strategy = "SPOT", "SPOT_PRICE_CAPACITY_OPTIMIZED"
+	case "on-demand":
+		resourceType, strategy = "EC2", "BEST_FIT_PROGRESSIVE"
+	default:
+		return nil, fmt.Errorf("unknown compute environment type %q (expected spot or on-demand)", ce.Type)
+	}
+
+	maxVCPUs := ce.MaxVCPUs
+	if maxVCPUs == 0 {
+		maxVCPUs = app.Compute.Batch.MaxVCPUs
+	}
+
+	byPlatform := map[string][]config.Architecture{}
+	for _, name := range ce.Architectures {
+		arch, err := app.GetArchitecture(name)
+		if err != nil {
+			return nil, err
+		}
+		p := container.Platform(arch.Family)
+		byPlatform[p] = append(byPlatform[p], *arch)
+	}
+
+	var envs []ComputeEnvironment
+	for _, p := range []string{"linux/amd64", "linux/arm64"} {
+		archs := byPlatform[p]
+		if len(archs) == 0 {
+			continue
+		}
+
+		var names, instanceTypes []string
+		seen := map[string]bool{}
+		for _, a := range archs {
+			names = append(names, a.Name)
+			for _, it := range a.InstanceTypes {
+				if !seen[it] {
+					seen[it] = true
+					instanceTypes = append(instanceTypes, it)
+				}
+			}
+		}
+
+		env := ComputeEnvironment{
+			Type:  "MANAGED",
+			State: "ENABLED",
+			ComputeResources: ComputeResources{
+				Type:               resourceType,
+				AllocationStrategy: strategy,
+				MinvCpus:           app.Compute.Batch.MinVCPUs,
+				MaxvCpus:           maxVCPUs,
+				InstanceTypes:      instanceTypes,
+				Subnets:            opts.Subnets,
+				SecurityGroupIDs:   opts.SecurityGroupIDs,
+				InstanceRole:       opts.InstanceRole,
+				Ec2Configuration:   []Ec2Configuration{{ImageType: "ECS_AL2023"}},
+				Tags:               Tags(app),
+			},
+			ServiceRole:   opts.ServiceRole,
+			Tags:          Tags(app),
+			Architectures: names,
+		}
+		if ce.Type == "spot" {
+			env.ComputeResources.BidPercentage = app.Compute.Batch.SpotBidPercentage
+		}
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
+// jobDefinition renders the job definition for a variant on an architecture
+func jobDefinition(app *config.Application, v config.Variant, arch config.Architecture, host string, opts Options) JobDefinition {
+	// The job submit defaults; submissions override them per job
+	vcpus, memoryMB := job.DefaultResources(&arch)
+	props := ContainerProperties{
+		Image: container.ImageRef(app, host, v.Name, arch.Name),
+		ResourceRequirements: []ResourceRequirement{
+			{Type: "VCPU", Value: strconv.Itoa(vcpus)},
+			{Type: "MEMORY", Value: strconv.Itoa(memoryMB)},
+		},
+		Environment: []KeyValuePair{
+			{Name: "OMP_NUM_THREADS", Value: strconv.Itoa(vcpus)},
+			{Name: "SCRATCH_DIR", Value: ScratchPath},
+		},
+		Volumes: []Volume{
+			{Name: "scratch", Host: VolumeHost{SourcePath: ScratchPath}},
+		},
+		MountPoints: []MountPoint{
+			{SourceVolume: "scratch", ContainerPath: ScratchPath},
+		},
+		JobRoleArn: opts.JobRoleArn,
+	}
+	if app.Storage.Shared != nil {
+		props.Volumes = append(props.Volumes,
+			Volume{Name: "shared", Host: VolumeHost{SourcePath: SharedPath}})
+		props.MountPoints = append(props.MountPoints,
+			MountPoint{SourceVolume: "shared", ContainerPath: SharedPath})
+	}
+	if app.GPU.Required && app.GPU.Count > 0 {
+		props.ResourceRequirements = append(props.ResourceRequirements,
+			ResourceRequirement{Type: "GPU", Value: strconv.Itoa(app.GPU.Count)})
+	}
+
+	def := JobDefinition{
+		JobDefinitionName:    job.JobDefinitionName(app.Name, v.Name, arch.Name),
+		PlatformCapabilities: []string{"EC2"},
+		RetryStrategy: RetryStrategy{
+			Attempts: 3,
+			EvaluateOnExit: []EvaluateOnExit{
+				{OnStatusReason: "Host EC2*", Action: "RETRY"},
+				{OnReason: "*", Action: "EXIT"},
+			},
+		},
+		PropagateTags: true,
+		Tags:          Tags(app),
+		Variant:       v.Name,
+		Architecture:  arch.Name,
+	}
+
+	if v.Type == "multi-node" {
+		if app.Networking.EFA {
+			props.LinuxParameters = &LinuxParameters{Devices: []Device{{
+				HostPath:      "/dev/infiniband/uverbs0",
+				ContainerPath: "/dev/infiniband/uverbs0",
+				Permissions:   []string{"READ", "WRITE", "MKNOD"},
+			}}}
+		}
+		def.Type = "multinode"
+		def.NodeProperties = &NodeProperties{
+			NumNodes: DefaultNumNodes,
+			MainNode: 0,
+			NodeRangeProperties: []NodeRangeProperty{
+				{TargetNodes: "0:", Container: props},
+			},
+		}
+		return def
+	}
+
+	def.Type = "container"
+	def.ContainerProperties = &props
+	return def
+}
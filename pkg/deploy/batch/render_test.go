@@ -0,0 +1,136 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/job"
+)
+
+func loadGEOSChem(t *testing.T) *config.Application {
+	t.Helper()
+	app, err := config.LoadApplication(filepath.Join("..", "..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestRenderQueuePriority(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []int // app.yaml priorities, 1 most preferred
+		want       []int // Batch priorities, higher scheduled first
+	}{
+		{"consecutive", []int{1, 2, 3}, []int{3, 2, 1}},
+		{"gaps", []int{1, 5, 10}, []int{10, 6, 1}},
+		{"ties", []int{2, 1, 2}, []int{1, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := loadGEOSChem(t)
+			for i, p := range tt.priorities {
+				app.Compute.Batch.Queues[i].Priority = p
+			}
+
+			docs, err := Render(app, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, q := range docs.JobQueues {
+				if q.JobQueueName != app.Compute.Batch.Queues[i].Name || q.Priority != tt.want[i] {
+					t.Errorf("queue %d = %s priority %d, want %s priority %d",
+						i, q.JobQueueName, q.Priority, app.Compute.Batch.Queues[i].Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSplitsArchitectures(t *testing.T) {
+	app := loadGEOSChem(t)
+	app.Compute.Batch.Queues = []config.Queue{{
+		Name:     "mixed",
+		Priority: 1,
+		ComputeEnvironments: []config.ComputeEnvironment{
+			{Type: "spot", Architectures: []string{"graviton4", "c7a", "c7i"}, MaxVCPUs: 256},
+			{Type: "on-demand", Architectures: []string{"c7a"}},
+		},
+	}}
+
+	docs, err := Render(app, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Batch cannot mix x86 and ARM instances, so the spot environment is
+	// split in two, x86 first, and numbering continues across environments
+	var got []string
+	for _, ce := range docs.ComputeEnvironments {
+		got = append(got, ce.ComputeEnvironmentName+" "+ce.ComputeResources.Type+" "+strings.Join(ce.Architectures, ","))
+	}
+	want := []string{
+		"mixed-1 SPOT c7a,c7i",
+		"mixed-2 SPOT graviton4",
+		"mixed-3 EC2 c7a",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("compute environments =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if cr := docs.ComputeEnvironments[2].ComputeResources; cr.MaxvCpus != app.Compute.Batch.MaxVCPUs {
+		t.Errorf("on-demand max vCPUs = %d, want the app-wide %d", cr.MaxvCpus, app.Compute.Batch.MaxVCPUs)
+	}
+
+	order := docs.JobQueues[0].ComputeEnvironmentOrder
+	if len(order) != 3 {
+		t.Fatalf("queue order = %+v", order)
+	}
+	for i, o := range order {
+		if o.Order != i+1 || o.ComputeEnvironment != docs.ComputeEnvironments[i].ComputeEnvironmentName {
+			t.Errorf("queue order %d = %+v", i, o)
+		}
+	}
+
+	app.Compute.Batch.Queues[0].ComputeEnvironments[0].Type = "reserved"
+	if _, err := Render(app, Options{}); err == nil {
+		t.Error("expected an error for an unknown compute environment type")
+	}
+}
+
+func TestRenderJobDefinitionDefaults(t *testing.T) {
+	docs, err := Render(loadGEOSChem(t), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := docs.JobDefinitions[0]
+	if def.JobDefinitionName != job.JobDefinitionName("geos-chem", "classic", "c7a") {
+		t.Errorf("first job definition = %s", def.JobDefinitionName)
+	}
+	if def.ContainerProperties == nil {
+		t.Fatalf("%s has no container properties", def.JobDefinitionName)
+	}
+	// Job definitions carry the job submit defaults, which fit on c7a.xlarge
+	want := map[string]string{"VCPU": "4", "MEMORY": strconv.Itoa(8*1024 - job.HostReservedMemoryMB)}
+	for _, r := range def.ContainerProperties.ResourceRequirements {
+		if want[r.Type] != r.Value {
+			t.Errorf("%s = %s, want %s", r.Type, r.Value, want[r.Type])
+		}
+	}
+}
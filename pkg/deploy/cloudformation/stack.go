@@ -0,0 +1,87 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws-hpc/platform/pkg/command"
+)
+
+// StackName returns the stack an application environment is deployed as
+func StackName(app, env string) string {
+	return fmt.Sprintf("aws-hpc-%s-%s", app, env)
+}
+
+// Stack deploys templates to a CloudFormation stack with the aws CLI
+type Stack struct {
+	Name   string
+	Region string
+	Runner command.Runner
+}
+
+// NewStack creates a stack deployer for the region
+func NewStack(name, region string) *Stack {
+	return &Stack{
+		Name:   name,
+		Region: region,
+		Runner: command.Exec{},
+	}
+}
+
+// Deploy creates or updates the stack from the template file, waiting for
+// the change set to finish. CLI progress is written to w.
+func (s *Stack) Deploy(ctx context.Context, templatePath string, params, tags map[string]string, w io.Writer) error {
+	args := []string{
+		"cloudformation", "deploy",
+		"--stack-name", s.Name,
+		"--template-file", templatePath,
+		"--capabilities", "CAPABILITY_IAM",
+		"--no-fail-on-empty-changeset",
+	}
+	if len(params) > 0 {
+		args = append(args, "--parameter-overrides")
+		args = append(args, keyValues(params)...)
+	}
+	if len(tags) > 0 {
+		args = append(args, "--tags")
+		args = append(args, keyValues(tags)...)
+	}
+	if s.Region != "" {
+		args = append(args, "--region", s.Region)
+	}
+
+	if err := s.Runner.Run(ctx, w, nil, "aws", args...); err != nil {
+		return fmt.Errorf("failed to deploy stack %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+// keyValues returns Key=Value arguments in key order
+func keyValues(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, len(keys))
+	for i, k := range keys {
+		args[i] = k + "=" + m[k]
+	}
+	return args
+}
@@ -0,0 +1,679 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudformation renders the CloudFormation template that deploys
+// an application's infrastructure and deploys it with the AWS CLI
+package cloudformation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/deploy"
+	"github.com/aws-hpc/platform/pkg/deploy/batch"
+)
+
+// Template parameters. Networking is account-specific, so it is supplied
+// at deploy time; the security groups must allow traffic between members
+// when shared storage is used. A template cannot create a resource per
+// list member, so the number of subnets is fixed when rendering: EFS gets a
+// mount target in each, as hosts need one in their own availability zone,
+// and FSx for Lustre is placed in the first.
+const (
+	ParamSubnets          = "Subnets"
+	ParamSecurityGroupIDs = "SecurityGroupIds"
+	ParamEnvironment      = "Environment"
+)
+
+// Template is a CloudFormation template. Maps marshal with sorted keys and
+// slices follow app.yaml order, so rendering the same spec twice produces
+// identical bytes.
+type Template struct {
+	AWSTemplateFormatVersion string               `json:"AWSTemplateFormatVersion"`
+	Description              string               `json:"Description"`
+	Parameters               map[string]Parameter `json:"Parameters"`
+	Resources                map[string]Resource  `json:"Resources"`
+	Outputs                  map[string]Output    `json:"Outputs"`
+}
+
+// Parameter is a template input
+type Parameter struct {
+	Type        string `json:"Type"`
+	Description string `json:"Description"`
+	Default     string `json:"Default,omitempty"`
+}
+
+// Resource is a template resource
+type Resource struct {
+	Type       string         `json:"Type"`
+	DependsOn  []string       `json:"DependsOn,omitempty"`
+	Properties map[string]any `json:"Properties"`
+}
+
+// Output is a template output
+type Output struct {
+	Description string `json:"Description"`
+	Value       any    `json:"Value"`
+}
+
+// JSON returns the template as indented JSON
+func (t *Template) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		return nil, fmt.Errorf("failed to encode template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Logical IDs of the fixed template resources
+const (
+	instanceRoleID    = "BatchInstanceRole"
+	instanceProfileID = "BatchInstanceProfile"
+	jobRoleID         = "JobRole"
+	outputBucketID    = "OutputBucket"
+	bucketPolicyID    = "OutputBucketPolicy"
+	sharedFSID        = "SharedFileSystem"
+	mountTargetID     = "SharedMountTarget"
+	placementGroupID  = "PlacementGroup"
+	launchTemplateID  = "ScratchLaunchTemplate"
+)
+
+// Options controls template rendering
+type Options struct {
+	// Subnets is the number of subnets the Subnets parameter will list;
+	// default 1. EFS shared storage has a mount target in each.
+	Subnets int
+}
+
+// Render builds the template that deploys app for the named environment:
+// IAM roles, the output bucket, shared storage, a placement group, the
+// scratch launch template and the Batch resources from batch.Render.
+func Render(app *config.Application, env string, opts Options) (*Template, error) {
+	if _, err := app.GetEnvironment(env); err != nil {
+		return nil, err
+	}
+	if opts.Subnets < 1 {
+		opts.Subnets = 1
+	}
+
+	// Image URIs are resolved by CloudFormation in the target account
+	docs, err := batch.Render(app, batch.Options{
+		AccountID: "${AWS::AccountId}",
+		Region:    "${AWS::Region}",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := batch.Tags(app)
+	tags["aws-hpc:environment"] = env
+
+	t := &Template{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Description:              fmt.Sprintf("aws-hpc %s %s (%s)", app.Name, app.Version, env),
+		Parameters: map[string]Parameter{
+			ParamSubnets: {
+				Type:        "List<AWS::EC2::Subnet::Id>",
+				Description: subnetsDescription(app, opts.Subnets),
+			},
+			ParamSecurityGroupIDs: {
+				Type:        "List<AWS::EC2::SecurityGroup::Id>",
+				Description: "Security groups for compute environments and shared storage",
+			},
+			ParamEnvironment: {
+				Type:        "String",
+				Description: "Application environment, which suffixes the bucket and Batch resource names",
+				Default:     env,
+			},
+		},
+		Resources: map[string]Resource{},
+		Outputs:   map[string]Output{},
+	}
+
+	t.addRoles(app, tags)
+	t.addOutputBucket(app, tags)
+	sharedSource, err := t.addSharedStorage(app, opts.Subnets, tags)
+	if err != nil {
+		return nil, err
+	}
+	if app.Networking.PlacementGroup {
+		t.Resources[placementGroupID] = Resource{
+			Type:       "AWS::EC2::PlacementGroup",
+			Properties: map[string]any{"Strategy": "cluster", "Tags": tagList(tags)},
+		}
+	}
+	t.addLaunchTemplate(app, sharedSource, tags)
+
+	for _, ce := range docs.ComputeEnvironments {
+		t.addComputeEnvironment(ce, tags)
+	}
+	for _, q := range docs.JobQueues {
+		t.addJobQueue(q, tags)
+	}
+	for _, def := range docs.JobDefinitions {
+		t.addJobDefinition(def, tags)
+	}
+
+	return t, nil
+}
+
+// addRoles adds the ECS instance role and the role assumed by job
+// containers, which may read the input bucket and write the output bucket
+func (t *Template) addRoles(app *config.Application, tags map[string]string) {
+	t.Resources[instanceRoleID] = Resource{
+		Type: "AWS::IAM::Role",
+		Properties: map[string]any{
+			"AssumeRolePolicyDocument": assumeRolePolicy("ec2.amazonaws.com"),
+			"ManagedPolicyArns": []any{
+				sub("arn:${AWS::Partition}:iam::aws:policy/service-role/AmazonEC2ContainerServiceforEC2Role"),
+			},
+			"Tags": tagList(tags),
+		},
+	}
+	t.Resources[instanceProfileID] = Resource{
+		Type: "AWS::IAM::InstanceProfile",
+		Properties: map[string]any{
+			"Roles": []any{ref(instanceRoleID)},
+		},
+	}
+
+	var statements []any
+	if in := app.Storage.Input; in.Type == "s3" && in.Bucket != "" {
+		statements = append(statements, map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"s3:GetObject", "s3:ListBucket"},
+			"Resource": []any{sub("arn:${AWS::Partition}:s3:::" + in.Bucket), sub("arn:${AWS::Partition}:s3:::" + in.Bucket + "/*")},
+		})
+	}
+	if out := app.Storage.Output; out.Type == "s3" && out.Bucket != "" {
+		statements = append(statements, map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"s3:AbortMultipartUpload", "s3:GetObject", "s3:ListBucket", "s3:PutObject"},
+			"Resource": []any{getAtt(outputBucketID, "Arn"), sub("${" + outputBucketID + ".Arn}/*")},
+		})
+	}
+
+	props := map[string]any{
+		"AssumeRolePolicyDocument": assumeRolePolicy("ecs-tasks.amazonaws.com"),
+		"Tags":                     tagList(tags),
+	}
+	if len(statements) > 0 {
+		props["Policies"] = []any{map[string]any{
+			"PolicyName": "aws-hpc-data",
+			"PolicyDocument": map[string]any{
+				"Version":   "2012-10-17",
+				"Statement": statements,
+			},
+		}}
+	}
+	t.Resources[jobRoleID] = Resource{Type: "AWS::IAM::Role", Properties: props}
+	t.Outputs["JobRoleArn"] = Output{Description: "Role assumed by job containers", Value: getAtt(jobRoleID, "Arn")}
+}
+
+// addOutputBucket adds the results bucket with its lifecycle rules and a
+// policy that denies unencrypted transport
+func (t *Template) addOutputBucket(app *config.Application, tags map[string]string) {
+	out := app.Storage.Output
+	if out.Type != "s3" || out.Bucket == "" {
+		return
+	}
+
+	props := map[string]any{
+		"BucketName": physicalName(out.Bucket),
+		"BucketEncryption": map[string]any{
+			"ServerSideEncryptionConfiguration": []any{map[string]any{
+				"ServerSideEncryptionByDefault": map[string]any{"SSEAlgorithm": "AES256"},
+			}},
+		},
+		"PublicAccessBlockConfiguration": map[string]any{
+			"BlockPublicAcls":       true,
+			"BlockPublicPolicy":     true,
+			"IgnorePublicAcls":      true,
+			"RestrictPublicBuckets": true,
+		},
+		"Tags": tagList(tags),
+	}
+	if rule := lifecycleRule(out); rule != nil {
+		props["LifecycleConfiguration"] = map[string]any{"Rules": []any{rule}}
+	}
+	t.Resources[outputBucketID] = Resource{
+		Type:       "AWS::S3::Bucket",
+		Properties: props,
+	}
+
+	t.Resources[bucketPolicyID] = Resource{
+		Type: "AWS::S3::BucketPolicy",
+		Properties: map[string]any{
+			"Bucket": ref(outputBucketID),
+			"PolicyDocument": map[string]any{
+				"Version": "2012-10-17",
+				"Statement": []any{map[string]any{
+					"Sid":       "DenyInsecureTransport",
+					"Effect":    "Deny",
+					"Principal": "*",
+					"Action":    "s3:*",
+					"Resource":  []any{getAtt(outputBucketID, "Arn"), sub("${" + outputBucketID + ".Arn}/*")},
+					"Condition": map[string]any{"Bool": map[string]any{"aws:SecureTransport": "false"}},
+				}},
+			},
+		},
+	}
+	t.Outputs["OutputBucket"] = Output{Description: "Results bucket", Value: ref(outputBucketID)}
+}
+
+// lifecycleRule converts a storage lifecycle policy, or returns nil when
+// the location has none
+func lifecycleRule(loc config.StorageLocation) map[string]any {
+	lc := loc.Lifecycle
+	if lc == nil || (lc.TransitionIA == 0 && lc.TransitionGlacier == 0 && lc.Expiration == 0) {
+		return nil
+	}
+
+	rule := map[string]any{
+		"Id":     "aws-hpc-lifecycle",
+		"Status": "Enabled",
+	}
+	if loc.Prefix != "" {
+		rule["Prefix"] = loc.Prefix
+	}
+	var transitions []any
+	if lc.TransitionIA > 0 {
+		transitions = append(transitions, map[string]any{
+			"StorageClass":     "STANDARD_IA",
+			"TransitionInDays": lc.TransitionIA,
+		})
+	}
+	if lc.TransitionGlacier > 0 {
+		transitions = append(transitions, map[string]any{
+			"StorageClass":     "GLACIER",
+			"TransitionInDays": lc.TransitionGlacier,
+		})
+	}
+	if len(transitions) > 0 {
+		rule["Transitions"] = transitions
+	}
+	if lc.Expiration > 0 {
+		rule["ExpirationInDays"] = lc.Expiration
+	}
+	return rule
+}
+
+// addSharedStorage adds the EFS or FSx for Lustre file system and returns
+// its mount source as an Fn::Sub string, or "" without shared storage
+func (t *Template) addSharedStorage(app *config.Application, subnets int, tags map[string]string) (string, error) {
+	shared := app.Storage.Shared
+	if shared == nil {
+		return "", nil
+	}
+
+	switch shared.Type {
+	case "efs":
+		props := map[string]any{
+			"Encrypted":       true,
+			"PerformanceMode": "generalPurpose",
+			"ThroughputMode":  "bursting",
+			"FileSystemTags":  tagList(tags),
+		}
+		if shared.ThroughputMode != "" {
+			props["ThroughputMode"] = shared.ThroughputMode
+		}
+		if shared.ThroughputMode == "provisioned" {
+			props["ProvisionedThroughputInMibps"] = deploy.EFSProvisionedThroughput(shared.SizeGB)
+		}
+		t.Resources[sharedFSID] = Resource{Type: "AWS::EFS::FileSystem", Properties: props}
+		for i := 0; i < subnets; i++ {
+			t.Resources[mountTargetLogicalID(i)] = Resource{
+				Type: "AWS::EFS::MountTarget",
+				Properties: map[string]any{
+					"FileSystemId":   ref(sharedFSID),
+					"SubnetId":       subnet(i),
+					"SecurityGroups": ref(ParamSecurityGroupIDs),
+				},
+			}
+		}
+		t.Outputs["SharedFileSystem"] = Output{Description: "Shared EFS file system", Value: ref(sharedFSID)}
+		return "${" + sharedFSID + "}.efs.${AWS::Region}.${AWS::URLSuffix}:/", nil
+
+	case "fsx-lustre":
+		t.Resources[sharedFSID] = Resource{
+			Type: "AWS::FSx::FileSystem",
+			Properties: map[string]any{
+				"FileSystemType":   "LUSTRE",
+				"StorageCapacity":  deploy.LustreCapacity(shared.SizeGB),
+				"SubnetIds":        []any{subnet(0)},
+				"SecurityGroupIds": ref(ParamSecurityGroupIDs),
+				"LustreConfiguration": map[string]any{
+					"DeploymentType": "SCRATCH_2",
+				},
+				"Tags": tagList(tags),
+			},
+		}
+		t.Outputs["SharedFileSystem"] = Output{Description: "Shared FSx for Lustre file system", Value: ref(sharedFSID)}
+		return "${" + sharedFSID + ".DNSName}@tcp:/${" + sharedFSID + ".LustreMountName}", nil
+
+	default:
+		return "", fmt.Errorf("unsupported shared storage type %q (expected efs or fsx-lustre)", shared.Type)
+	}
+}
+
+// addLaunchTemplate adds the launch template that attaches scratch storage
+// and mounts it, and any shared storage, on compute environment hosts
+func (t *Template) addLaunchTemplate(app *config.Application, sharedSource string, tags map[string]string) {
+	userData := any(batch.UserData(app, sharedSource))
+	if sharedSource != "" {
+		userData = sub(userData.(string))
+	}
+
+	data := map[string]any{
+		"UserData": map[string]any{"Fn::Base64": userData},
+		"TagSpecifications": []any{map[string]any{
+			"ResourceType": "volume",
+			"Tags":         tagList(tags),
+		}},
+	}
+
+	if scratch := app.Storage.Scratch; scratch.Type == "ebs" && scratch.SizeGB > 0 {
+		ebs := map[string]any{
+			"VolumeSize":          scratch.SizeGB,
+			"VolumeType":          "gp3",
+			"Encrypted":           true,
+			"DeleteOnTermination": true,
+		}
+		if scratch.VolumeType != "" {
+			ebs["VolumeType"] = scratch.VolumeType
+		}
+		if scratch.IOPS > 0 {
+			ebs["Iops"] = scratch.IOPS
+		}
+		data["BlockDeviceMappings"] = []any{map[string]any{
+			"DeviceName": batch.ScratchDevice,
+			"Ebs":        ebs,
+		}}
+	}
+
+	t.Resources[launchTemplateID] = Resource{
+		Type: "AWS::EC2::LaunchTemplate",
+		Properties: map[string]any{
+			"LaunchTemplateData": data,
+		},
+	}
+}
+
+func (t *Template) addComputeEnvironment(ce batch.ComputeEnvironment, tags map[string]string) {
+	cr := ce.ComputeResources
+	resources := map[string]any{
+		"Type":               cr.Type,
+		"AllocationStrategy": cr.AllocationStrategy,
+		"MinvCpus":           cr.MinvCpus,
+		"MaxvCpus":           cr.MaxvCpus,
+		"InstanceTypes":      cr.InstanceTypes,
+		"Subnets":            ref(ParamSubnets),
+		"SecurityGroupIds":   ref(ParamSecurityGroupIDs),
+		"InstanceRole":       getAtt(instanceProfileID, "Arn"),
+		"Ec2Configuration":   ec2Configuration(cr.Ec2Configuration),
+		"LaunchTemplate": map[string]any{
+			"LaunchTemplateId": ref(launchTemplateID),
+			"Version":          getAtt(launchTemplateID, "LatestVersionNumber"),
+		},
+		"Tags": tags,
+	}
+	if cr.BidPercentage > 0 {
+		resources["BidPercentage"] = cr.BidPercentage
+	}
+	if _, ok := t.Resources[placementGroupID]; ok {
+		resources["PlacementGroup"] = ref(placementGroupID)
+	}
+
+	// Hosts mount EFS at boot, so the mount targets must exist first
+	var dependsOn []string
+	for i := 0; ; i++ {
+		if _, ok := t.Resources[mountTargetLogicalID(i)]; !ok {
+			break
+		}
+		dependsOn = append(dependsOn, mountTargetLogicalID(i))
+	}
+
+	t.Resources[logicalID("ComputeEnvironment", ce.ComputeEnvironmentName)] = Resource{
+		Type:      "AWS::Batch::ComputeEnvironment",
+		DependsOn: dependsOn,
+		Properties: map[string]any{
+			"ComputeEnvironmentName": physicalName(ce.ComputeEnvironmentName),
+			"Type":                   ce.Type,
+			"State":                  ce.State,
+			"ComputeResources":       resources,
+			"Tags":                   tags,
+		},
+	}
+}
+
+func ec2Configuration(configs []batch.Ec2Configuration) []any {
+	out := make([]any, len(configs))
+	for i, c := range configs {
+		out[i] = map[string]any{"ImageType": c.ImageType}
+	}
+	return out
+}
+
+func (t *Template) addJobQueue(q batch.JobQueue, tags map[string]string) {
+	order := make([]any, len(q.ComputeEnvironmentOrder))
+	for i, o := range q.ComputeEnvironmentOrder {
+		order[i] = map[string]any{
+			"Order":              o.Order,
+			"ComputeEnvironment": ref(logicalID("ComputeEnvironment", o.ComputeEnvironment)),
+		}
+	}
+
+	id := logicalID("JobQueue", q.JobQueueName)
+	t.Resources[id] = Resource{
+		Type: "AWS::Batch::JobQueue",
+		Properties: map[string]any{
+			"JobQueueName":            physicalName(q.JobQueueName),
+			"State":                   q.State,
+			"Priority":                q.Priority,
+			"ComputeEnvironmentOrder": order,
+			"Tags":                    tags,
+		},
+	}
+	t.Outputs[id] = Output{Description: "Job queue " + q.JobQueueName, Value: ref(id)}
+}
+
+func (t *Template) addJobDefinition(def batch.JobDefinition, tags map[string]string) {
+	props := map[string]any{
+		"JobDefinitionName":    physicalName(def.JobDefinitionName),
+		"Type":                 def.Type,
+		"PlatformCapabilities": def.PlatformCapabilities,
+		"PropagateTags":        def.PropagateTags,
+		"RetryStrategy":        retryStrategy(def.RetryStrategy),
+		"Tags":                 tags,
+	}
+	if def.ContainerProperties != nil {
+		props["ContainerProperties"] = containerProperties(*def.ContainerProperties)
+	}
+	if np := def.NodeProperties; np != nil {
+		ranges := make([]any, len(np.NodeRangeProperties))
+		for i, r := range np.NodeRangeProperties {
+			ranges[i] = map[string]any{
+				"TargetNodes": r.TargetNodes,
+				"Container":   containerProperties(r.Container),
+			}
+		}
+		props["NodeProperties"] = map[string]any{
+			"NumNodes":            np.NumNodes,
+			"MainNode":            np.MainNode,
+			"NodeRangeProperties": ranges,
+		}
+	}
+
+	t.Resources[logicalID("JobDefinition", def.JobDefinitionName)] = Resource{
+		Type:       "AWS::Batch::JobDefinition",
+		Properties: props,
+	}
+}
+
+func containerProperties(c batch.ContainerProperties) map[string]any {
+	resources := make([]any, len(c.ResourceRequirements))
+	for i, r := range c.ResourceRequirements {
+		resources[i] = map[string]any{"Type": r.Type, "Value": r.Value}
+	}
+	env := make([]any, len(c.Environment))
+	for i, e := range c.Environment {
+		env[i] = map[string]any{"Name": e.Name, "Value": e.Value}
+	}
+	volumes := make([]any, len(c.Volumes))
+	for i, v := range c.Volumes {
+		volumes[i] = map[string]any{
+			"Name": v.Name,
+			"Host": map[string]any{"SourcePath": v.Host.SourcePath},
+		}
+	}
+	mounts := make([]any, len(c.MountPoints))
+	for i, m := range c.MountPoints {
+		mounts[i] = map[string]any{
+			"SourceVolume":  m.SourceVolume,
+			"ContainerPath": m.ContainerPath,
+			"ReadOnly":      m.ReadOnly,
+		}
+	}
+
+	props := map[string]any{
+		"Image":                sub(c.Image),
+		"ResourceRequirements": resources,
+		"Environment":          env,
+		"Volumes":              volumes,
+		"MountPoints":          mounts,
+		"JobRoleArn":           getAtt(jobRoleID, "Arn"),
+	}
+	if lp := c.LinuxParameters; lp != nil {
+		devices := make([]any, len(lp.Devices))
+		for i, d := range lp.Devices {
+			devices[i] = map[string]any{
+				"HostPath":      d.HostPath,
+				"ContainerPath": d.ContainerPath,
+				"Permissions":   d.Permissions,
+			}
+		}
+		props["LinuxParameters"] = map[string]any{"Devices": devices}
+	}
+	return props
+}
+
+func retryStrategy(r batch.RetryStrategy) map[string]any {
+	conditions := make([]any, len(r.EvaluateOnExit))
+	for i, e := range r.EvaluateOnExit {
+		c := map[string]any{"Action": e.Action}
+		if e.OnStatusReason != "" {
+			c["OnStatusReason"] = e.OnStatusReason
+		}
+		if e.OnReason != "" {
+			c["OnReason"] = e.OnReason
+		}
+		conditions[i] = c
+	}
+	return map[string]any{
+		"Attempts":       r.Attempts,
+		"EvaluateOnExit": conditions,
+	}
+}
+
+// logicalID turns a resource name such as "geos-chem-spot-x86" into a
+// logical ID such as "JobQueueGeosChemSpotX86"
+func logicalID(prefix, name string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func assumeRolePolicy(service string) map[string]any {
+	return map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []any{map[string]any{
+			"Effect":    "Allow",
+			"Principal": map[string]any{"Service": service},
+			"Action":    "sts:AssumeRole",
+		}},
+	}
+}
+
+func tagList(tags map[string]string) []any {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]any, len(keys))
+	for i, k := range keys {
+		list[i] = map[string]any{"Key": k, "Value": tags[k]}
+	}
+	return list
+}
+
+func ref(id string) map[string]any {
+	return map[string]any{"Ref": id}
+}
+
+func getAtt(id, attr string) map[string]any {
+	return map[string]any{"Fn::GetAtt": []string{id, attr}}
+}
+
+func sub(s string) map[string]any {
+	return map[string]any{"Fn::Sub": s}
+}
+
+// subnet selects the i-th member of the Subnets parameter
+func subnet(i int) map[string]any {
+	return map[string]any{"Fn::Select": []any{i, ref(ParamSubnets)}}
+}
+
+// mountTargetLogicalID names the EFS mount target in the i-th subnet. The
+// first keeps the ID templates used when they had a single mount target,
+// so updating a stack does not replace it.
+func mountTargetLogicalID(i int) string {
+	if i == 0 {
+		return mountTargetID
+	}
+	return fmt.Sprintf("%s%d", mountTargetID, i+1)
+}
+
+// subnetsDescription describes the Subnets parameter, noting how many
+// subnets it must list and which shared storage uses
+func subnetsDescription(app *config.Application, subnets int) string {
+	switch shared := app.Storage.Shared; {
+	case shared == nil:
+		return "Subnets for compute environments"
+	case shared.Type == "efs":
+		return fmt.Sprintf("Exactly %d subnet(s) in different availability zones, for compute environments and an EFS mount target each", subnets)
+	default:
+		return "Subnets for compute environments; shared storage is created in the first"
+	}
+}
+
+// physicalName suffixes a resource name with the Environment parameter, as
+// job.DeployedName does, so each environment's stack has its own bucket
+// and Batch resources
+func physicalName(name string) map[string]any {
+	return sub(name + "-${" + ParamEnvironment + "}")
+}
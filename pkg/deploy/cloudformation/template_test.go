@@ -0,0 +1,195 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudformation
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/job"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		opts   Options
+		modify func(app *config.Application)
+	}{
+		{
+			name: "geos-chem-production",
+			env:  "production",
+		},
+		{
+			name: "geos-chem-efs",
+			env:  "benchmark",
+			opts: Options{Subnets: 2},
+			modify: func(app *config.Application) {
+				app.Storage.Shared = &config.SharedStorage{Type: "efs", SizeGB: 2048, ThroughputMode: "provisioned"}
+			},
+		},
+		{
+			name: "geos-chem-fsx-placement",
+			env:  "production",
+			modify: func(app *config.Application) {
+				app.Storage.Shared = &config.SharedStorage{Type: "fsx-lustre", SizeGB: 3000}
+				app.Networking = config.NetworkingSpec{EFA: true, PlacementGroup: true}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := config.LoadApplication(filepath.Join("..", "..", "..", "applications", "geos-chem"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.modify != nil {
+				tt.modify(app)
+			}
+
+			tmpl, err := Render(app, tt.env, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tmpl.JSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("template differs from %s (run go test -update after checking the change)", golden)
+			}
+
+			// Rendering must be deterministic
+			again, err := Render(app, tt.env, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := again.JSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Error("rendering twice produced different templates")
+			}
+		})
+	}
+}
+
+func TestRenderUnknownEnvironment(t *testing.T) {
+	app, err := config.LoadApplication(filepath.Join("..", "..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Render(app, "staging", Options{}); err == nil {
+		t.Error("expected an error for an environment not in app.yaml")
+	}
+}
+
+// physicalNames returns the names a template gives its bucket and Batch
+// resources when deployed with the Environment parameter's default
+func physicalNames(t *testing.T, tmpl *Template) map[string]string {
+	t.Helper()
+	properties := map[string]string{
+		"AWS::S3::Bucket":                "BucketName",
+		"AWS::Batch::ComputeEnvironment": "ComputeEnvironmentName",
+		"AWS::Batch::JobQueue":           "JobQueueName",
+		"AWS::Batch::JobDefinition":      "JobDefinitionName",
+	}
+	names := map[string]string{}
+	for id, r := range tmpl.Resources {
+		property, ok := properties[r.Type]
+		if !ok {
+			continue
+		}
+		name, ok := r.Properties[property].(map[string]any)["Fn::Sub"].(string)
+		if !ok {
+			t.Fatalf("%s %s is not an Fn::Sub of the environment", id, property)
+		}
+		names[id] = strings.ReplaceAll(name, "${"+ParamEnvironment+"}", tmpl.Parameters[ParamEnvironment].Default)
+	}
+	return names
+}
+
+func TestRenderEnvironmentNames(t *testing.T) {
+	app, err := config.LoadApplication(filepath.Join("..", "..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	production, err := Render(app, "production", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	benchmark, err := Render(app, "benchmark", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both stacks can be deployed to one account and region
+	prodNames := physicalNames(t, production)
+	benchNames := physicalNames(t, benchmark)
+	if len(prodNames) == 0 || len(prodNames) != len(benchNames) {
+		t.Fatalf("physical names: production %v, benchmark %v", prodNames, benchNames)
+	}
+	used := map[string]bool{}
+	for _, name := range prodNames {
+		used[name] = true
+	}
+	for id, name := range benchNames {
+		if used[name] {
+			t.Errorf("%s is named %s in both environments", id, name)
+		}
+	}
+
+	// Jobs submitted with --env find the environment's queue and job
+	// definition
+	spec, err := job.Resolve(app, &config.Environment{Name: "benchmark"}, job.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !contains(benchNames, spec.Queue) {
+		t.Errorf("job queue %s is not deployed (deployed names: %v)", spec.Queue, benchNames)
+	}
+	if !contains(benchNames, spec.JobDefinition) {
+		t.Errorf("job definition %s is not deployed (deployed names: %v)", spec.JobDefinition, benchNames)
+	}
+}
+
+func contains(names map[string]string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
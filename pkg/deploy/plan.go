@@ -0,0 +1,351 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// Action is what a deploy does to a resource
+type Action string
+
+const (
+	Add    Action = "add"
+	Change Action = "change"
+	Remove Action = "remove"
+)
+
+// Kinds of planned resources, in the order plans list them
+const (
+	KindArchitecture       = "architecture"
+	KindComputeEnvironment = "compute environment"
+	KindJobQueue           = "job queue"
+	KindJobDefinition      = "job definition"
+	KindBucket             = "bucket"
+)
+
+var kindOrder = []string{KindArchitecture, KindComputeEnvironment, KindJobQueue, KindJobDefinition, KindBucket}
+
+// PlannedChange is one planned difference from the last deploy. Destructive
+// changes remove or shrink something running jobs or stored data depend on.
+type PlannedChange struct {
+	Kind        string
+	Name        string
+	Action      Action
+	Details     []string
+	Destructive bool
+}
+
+// Plan is the set of changes a deploy would make
+type Plan struct {
+	Changes []PlannedChange
+}
+
+// Destructive reports whether any change is destructive
+func (p *Plan) Destructive() bool {
+	for _, c := range p.Changes {
+		if c.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of changes with the action
+func (p *Plan) Count(a Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// Diff plans the move from the deployed snapshot to the desired one. A nil
+// deployed snapshot, meaning a first deploy, plans adding everything.
+func Diff(deployed, desired *Snapshot) *Plan {
+	if deployed == nil {
+		deployed = &Snapshot{}
+	}
+	p := &Plan{}
+
+	diffNames(p, KindArchitecture, deployed.Architectures, desired.Architectures)
+
+	for _, name := range keys(deployed.ComputeEnvironments, desired.ComputeEnvironments) {
+		old, had := deployed.ComputeEnvironments[name]
+		cur, has := desired.ComputeEnvironments[name]
+		switch {
+		case !had:
+			p.add(KindComputeEnvironment, name, Add, false,
+				fmt.Sprintf("%s, %d-%d vCPUs, %s", cur.Type, cur.MinVCPUs, cur.MaxVCPUs, strings.Join(cur.Architectures, ", ")))
+		case !has:
+			p.add(KindComputeEnvironment, name, Remove, true)
+		default:
+			diffComputeEnvironment(p, name, old, cur)
+		}
+	}
+
+	for _, name := range keys(deployed.JobQueues, desired.JobQueues) {
+		old, had := deployed.JobQueues[name]
+		cur, has := desired.JobQueues[name]
+		switch {
+		case !had:
+			p.add(KindJobQueue, name, Add, false,
+				fmt.Sprintf("priority %d, %s", cur.Priority, strings.Join(cur.ComputeEnvironments, ", ")))
+		case !has:
+			p.add(KindJobQueue, name, Remove, true)
+		default:
+			var details []string
+			if old.Priority != cur.Priority {
+				details = append(details, fmt.Sprintf("priority %d -> %d", old.Priority, cur.Priority))
+			}
+			if !equal(old.ComputeEnvironments, cur.ComputeEnvironments) {
+				details = append(details, fmt.Sprintf("compute environments %s -> %s",
+					strings.Join(old.ComputeEnvironments, ", "), strings.Join(cur.ComputeEnvironments, ", ")))
+			}
+			if len(details) > 0 {
+				p.add(KindJobQueue, name, Change, false, details...)
+			}
+		}
+	}
+
+	for _, name := range keys(deployed.JobDefinitions, desired.JobDefinitions) {
+		old, had := deployed.JobDefinitions[name]
+		cur, has := desired.JobDefinitions[name]
+		switch {
+		case !had:
+			p.add(KindJobDefinition, name, Add, false, cur)
+		case !has:
+			p.add(KindJobDefinition, name, Remove, true)
+		case old != cur:
+			p.add(KindJobDefinition, name, Change, false, fmt.Sprintf("image %s -> %s", old, cur))
+		}
+	}
+
+	for _, name := range keys(deployed.Buckets, desired.Buckets) {
+		old, had := deployed.Buckets[name]
+		cur, has := desired.Buckets[name]
+		switch {
+		case !had:
+			p.add(KindBucket, name, Add, false, bucketSummary(cur))
+		case !has:
+			// Dropping a created bucket deletes it; dropping an input
+			// bucket only removes the jobs' access to it
+			p.add(KindBucket, name, Remove, old.Created)
+		default:
+			diffBucket(p, name, old, cur)
+		}
+	}
+
+	sort.SliceStable(p.Changes, func(i, k int) bool {
+		return kindIndex(p.Changes[i].Kind) < kindIndex(p.Changes[k].Kind)
+	})
+	return p
+}
+
+func diffComputeEnvironment(p *Plan, name string, old, cur ComputeEnvironmentState) {
+	var details []string
+	destructive := false
+
+	if old.Type != cur.Type {
+		details = append(details, fmt.Sprintf("type %s -> %s (replaces the environment)", old.Type, cur.Type))
+		destructive = true
+	}
+	if old.MaxVCPUs != cur.MaxVCPUs {
+		details = append(details, fmt.Sprintf("max_vcpus %d -> %d", old.MaxVCPUs, cur.MaxVCPUs))
+		if cur.MaxVCPUs < old.MaxVCPUs {
+			destructive = true
+		}
+	}
+	if old.MinVCPUs != cur.MinVCPUs {
+		details = append(details, fmt.Sprintf("min_vcpus %d -> %d", old.MinVCPUs, cur.MinVCPUs))
+	}
+	if added, removed := setDiff(old.Architectures, cur.Architectures); len(added)+len(removed) > 0 {
+		details = append(details, describeSetDiff("architectures", added, removed))
+		if len(removed) > 0 {
+			destructive = true
+		}
+	}
+	if added, removed := setDiff(old.InstanceTypes, cur.InstanceTypes); len(added)+len(removed) > 0 {
+		details = append(details, describeSetDiff("instance types", added, removed))
+		if len(removed) > 0 {
+			destructive = true
+		}
+	}
+
+	if len(details) > 0 {
+		p.add(KindComputeEnvironment, name, Change, destructive, details...)
+	}
+}
+
+func diffBucket(p *Plan, name string, old, cur BucketState) {
+	var details []string
+	destructive := false
+
+	if old.Access != cur.Access {
+		details = append(details, fmt.Sprintf("job access %s -> %s", old.Access, cur.Access))
+	}
+	if old.Created != cur.Created {
+		if cur.Created {
+			details = append(details, "now created and managed by the deploy")
+		} else {
+			details = append(details, "no longer managed by the deploy (deletes it)")
+			destructive = true
+		}
+	}
+
+	before, after := lifecycleOf(old.Lifecycle), lifecycleOf(cur.Lifecycle)
+	if before != after {
+		details = append(details, fmt.Sprintf("lifecycle %s -> %s", describeLifecycle(before), describeLifecycle(after)))
+		// Objects expiring sooner, or at all, is data loss
+		if after.Expiration > 0 && (before.Expiration == 0 || after.Expiration < before.Expiration) {
+			destructive = true
+		}
+	}
+
+	if len(details) > 0 {
+		p.add(KindBucket, name, Change, destructive, details...)
+	}
+}
+
+func (p *Plan) add(kind, name string, action Action, destructive bool, details ...string) {
+	p.Changes = append(p.Changes, PlannedChange{
+		Kind:        kind,
+		Name:        name,
+		Action:      action,
+		Details:     details,
+		Destructive: destructive,
+	})
+}
+
+// diffNames plans added and removed names; removing one is destructive
+func diffNames(p *Plan, kind string, old, cur []string) {
+	added, removed := setDiff(old, cur)
+	for _, name := range removed {
+		p.add(kind, name, Remove, true)
+	}
+	for _, name := range added {
+		p.add(kind, name, Add, false)
+	}
+}
+
+func bucketSummary(b BucketState) string {
+	s := b.Access + " access"
+	if b.Created {
+		s += ", created"
+	}
+	if b.Lifecycle != nil {
+		s += ", lifecycle " + describeLifecycle(*b.Lifecycle)
+	}
+	return s
+}
+
+func lifecycleOf(lc *config.LifecyclePolicy) config.LifecyclePolicy {
+	if lc == nil {
+		return config.LifecyclePolicy{}
+	}
+	return *lc
+}
+
+func describeLifecycle(lc config.LifecyclePolicy) string {
+	var parts []string
+	if lc.TransitionIA > 0 {
+		parts = append(parts, fmt.Sprintf("IA after %dd", lc.TransitionIA))
+	}
+	if lc.TransitionGlacier > 0 {
+		parts = append(parts, fmt.Sprintf("Glacier after %dd", lc.TransitionGlacier))
+	}
+	if lc.Expiration > 0 {
+		parts = append(parts, fmt.Sprintf("expire after %dd", lc.Expiration))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func describeSetDiff(what string, added, removed []string) string {
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "+"+strings.Join(added, " +"))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "-"+strings.Join(removed, " -"))
+	}
+	return what + " " + strings.Join(parts, " ")
+}
+
+// setDiff returns the names only in cur and only in old, each in input order
+func setDiff(old, cur []string) (added, removed []string) {
+	inOld := map[string]bool{}
+	for _, s := range old {
+		inOld[s] = true
+	}
+	inCur := map[string]bool{}
+	for _, s := range cur {
+		inCur[s] = true
+		if !inOld[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range old {
+		if !inCur[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+// keys returns the sorted union of both maps' keys
+func keys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				out = append(out, k)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func kindIndex(kind string) int {
+	for i, k := range kindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(kindOrder)
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// deployedSnapshot is a small deploy: two architectures, one compute
+// environment per queue and an output bucket expiring objects after a year
+func deployedSnapshot() *Snapshot {
+	return &Snapshot{
+		App:           "geos-chem",
+		Architectures: []string{"c7a", "graviton4"},
+		ComputeEnvironments: map[string]ComputeEnvironmentState{
+			"spot-1":     {Type: "SPOT", MaxVCPUs: 256, InstanceTypes: []string{"c7a.8xlarge", "c8g.8xlarge"}, Architectures: []string{"c7a", "graviton4"}},
+			"ondemand-1": {Type: "EC2", MaxVCPUs: 64, InstanceTypes: []string{"c7a.8xlarge"}, Architectures: []string{"c7a"}},
+		},
+		JobQueues: map[string]JobQueueState{
+			"spot":     {Priority: 2, ComputeEnvironments: []string{"spot-1"}},
+			"ondemand": {Priority: 1, ComputeEnvironments: []string{"ondemand-1"}},
+		},
+		JobDefinitions: map[string]string{
+			"geos-chem-classic-c7a":       "repo:classic-c7a",
+			"geos-chem-classic-graviton4": "repo:classic-graviton4",
+		},
+		Buckets: map[string]BucketState{
+			"input":   {Access: "read"},
+			"results": {Access: "read-write", Created: true, Lifecycle: &config.LifecyclePolicy{TransitionIA: 30, Expiration: 365}},
+		},
+	}
+}
+
+// summary lists a plan's changes as "kind name action", with a "!" suffix
+// on destructive ones
+func summary(p *Plan) []string {
+	var out []string
+	for _, c := range p.Changes {
+		s := fmt.Sprintf("%s %s %s", c.Kind, c.Name, c.Action)
+		if c.Destructive {
+			s += "!"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *Snapshot)
+		want   []string
+	}{
+		{
+			name:   "no change",
+			modify: func(s *Snapshot) {},
+		},
+		{
+			name: "architecture removed",
+			modify: func(s *Snapshot) {
+				s.Architectures = []string{"c7a"}
+				s.ComputeEnvironments["spot-1"] = ComputeEnvironmentState{Type: "SPOT", MaxVCPUs: 256, InstanceTypes: []string{"c7a.8xlarge"}, Architectures: []string{"c7a"}}
+				delete(s.JobDefinitions, "geos-chem-classic-graviton4")
+			},
+			want: []string{
+				"architecture graviton4 remove!",
+				"compute environment spot-1 change!",
+				"job definition geos-chem-classic-graviton4 remove!",
+			},
+		},
+		{
+			name: "max_vcpus shrunk",
+			modify: func(s *Snapshot) {
+				ce := s.ComputeEnvironments["spot-1"]
+				ce.MaxVCPUs = 128
+				s.ComputeEnvironments["spot-1"] = ce
+			},
+			want: []string{"compute environment spot-1 change!"},
+		},
+		{
+			name: "max_vcpus grown",
+			modify: func(s *Snapshot) {
+				ce := s.ComputeEnvironments["spot-1"]
+				ce.MaxVCPUs = 512
+				s.ComputeEnvironments["spot-1"] = ce
+			},
+			want: []string{"compute environment spot-1 change"},
+		},
+		{
+			name: "provisioning type changed",
+			modify: func(s *Snapshot) {
+				ce := s.ComputeEnvironments["ondemand-1"]
+				ce.Type = "SPOT"
+				s.ComputeEnvironments["ondemand-1"] = ce
+			},
+			want: []string{"compute environment ondemand-1 change!"},
+		},
+		{
+			name: "queue and compute environment removed",
+			modify: func(s *Snapshot) {
+				delete(s.JobQueues, "ondemand")
+				delete(s.ComputeEnvironments, "ondemand-1")
+			},
+			want: []string{
+				"compute environment ondemand-1 remove!",
+				"job queue ondemand remove!",
+			},
+		},
+		{
+			name: "queue priority and image changed",
+			modify: func(s *Snapshot) {
+				s.JobQueues["spot"] = JobQueueState{Priority: 3, ComputeEnvironments: []string{"spot-1"}}
+				s.JobDefinitions["geos-chem-classic-c7a"] = "repo:classic-c7a-1.1"
+			},
+			want: []string{
+				"job queue spot change",
+				"job definition geos-chem-classic-c7a change",
+			},
+		},
+		{
+			name: "lifecycle expiration shortened",
+			modify: func(s *Snapshot) {
+				s.Buckets["results"] = BucketState{Access: "read-write", Created: true, Lifecycle: &config.LifecyclePolicy{TransitionIA: 30, Expiration: 90}}
+			},
+			want: []string{"bucket results change!"},
+		},
+		{
+			name: "lifecycle expiration removed",
+			modify: func(s *Snapshot) {
+				s.Buckets["results"] = BucketState{Access: "read-write", Created: true, Lifecycle: &config.LifecyclePolicy{TransitionIA: 30}}
+			},
+			want: []string{"bucket results change"},
+		},
+		{
+			name: "buckets removed",
+			modify: func(s *Snapshot) {
+				s.Buckets = map[string]BucketState{}
+			},
+			// Only the created bucket is deleted
+			want: []string{
+				"bucket input remove",
+				"bucket results remove!",
+			},
+		},
+		{
+			name: "architecture added",
+			modify: func(s *Snapshot) {
+				s.Architectures = append(s.Architectures, "c7i")
+				s.JobDefinitions["geos-chem-classic-c7i"] = "repo:classic-c7i"
+			},
+			want: []string{
+				"architecture c7i add",
+				"job definition geos-chem-classic-c7i add",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := deployedSnapshot()
+			tt.modify(desired)
+
+			p := Diff(deployedSnapshot(), desired)
+			got := strings.Join(summary(p), "\n")
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+			}
+			destructive := strings.Contains(strings.Join(tt.want, " "), "!")
+			if p.Destructive() != destructive {
+				t.Errorf("Destructive() = %v, want %v", p.Destructive(), destructive)
+			}
+		})
+	}
+}
+
+func TestDiffFirstDeploy(t *testing.T) {
+	p := Diff(nil, deployedSnapshot())
+	if p.Destructive() || p.Count(Add) != len(p.Changes) {
+		t.Errorf("first deploy = %v, want only additions", summary(p))
+	}
+	if p.Count(Add) != 2+2+2+2+2 {
+		t.Errorf("first deploy adds %d resources, want 10", p.Count(Add))
+	}
+}
+
+func TestNewSnapshot(t *testing.T) {
+	app, err := config.LoadApplication(filepath.Join("..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSnapshot(app, "nope", "us-east-1"); err == nil {
+		t.Error("expected an error for an environment not in app.yaml")
+	}
+	s, err := NewSnapshot(app, "production", "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.JobQueues["geos-chem-spot-x86-production"]; !ok {
+		t.Errorf("job queues = %v, want the production stack's names", s.JobQueues)
+	}
+	if b, ok := s.Buckets["geos-chem-results-production"]; !ok || !b.Created {
+		t.Errorf("buckets = %v, want the production stack's output bucket", s.Buckets)
+	}
+	if p := Diff(s, s); len(p.Changes) != 0 {
+		t.Errorf("Diff() of a snapshot with itself = %v", summary(p))
+	}
+}
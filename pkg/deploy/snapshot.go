@@ -0,0 +1,172 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/deploy/batch"
+	"github.com/aws-hpc/platform/pkg/job"
+)
+
+// Snapshot records the infrastructure an application deploy created, in
+// enough detail to plan the next deploy against it
+type Snapshot struct {
+	App         string    `json:"app"`
+	Version     string    `json:"version"`
+	Environment string    `json:"environment"`
+	Region      string    `json:"region"`
+	DeployedAt  time.Time `json:"deployed_at"`
+
+	Architectures       []string                           `json:"architectures"`
+	ComputeEnvironments map[string]ComputeEnvironmentState `json:"compute_environments"`
+	JobQueues           map[string]JobQueueState           `json:"job_queues"`
+	JobDefinitions      map[string]string                  `json:"job_definitions"` // name -> image
+	Buckets             map[string]BucketState             `json:"buckets"`
+}
+
+// ComputeEnvironmentState is a deployed Batch compute environment
+type ComputeEnvironmentState struct {
+	Type          string   `json:"type"` // SPOT, EC2
+	MinVCPUs      int      `json:"min_vcpus"`
+	MaxVCPUs      int      `json:"max_vcpus"`
+	InstanceTypes []string `json:"instance_types"`
+	Architectures []string `json:"architectures"`
+}
+
+// JobQueueState is a deployed Batch job queue
+type JobQueueState struct {
+	Priority            int      `json:"priority"`
+	ComputeEnvironments []string `json:"compute_environments"`
+}
+
+// BucketState is an S3 bucket jobs use and the policy the deploy applies
+// to it. Only created buckets have a lifecycle.
+type BucketState struct {
+	Access    string                  `json:"access"` // read, read-write
+	Created   bool                    `json:"created"`
+	Lifecycle *config.LifecyclePolicy `json:"lifecycle,omitempty"`
+}
+
+// NewSnapshot describes the infrastructure a deploy of app would create
+// for the named environment, under the names the environment's stack gives
+// it
+func NewSnapshot(app *config.Application, env, region string) (*Snapshot, error) {
+	if _, err := app.GetEnvironment(env); err != nil {
+		return nil, err
+	}
+
+	docs, err := batch.Render(app, batch.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{
+		App:                 app.Name,
+		Version:             app.Version,
+		Environment:         env,
+		Region:              region,
+		ComputeEnvironments: map[string]ComputeEnvironmentState{},
+		JobQueues:           map[string]JobQueueState{},
+		JobDefinitions:      map[string]string{},
+		Buckets:             map[string]BucketState{},
+	}
+	for _, arch := range app.Compute.Architectures {
+		s.Architectures = append(s.Architectures, arch.Name)
+	}
+	for _, ce := range docs.ComputeEnvironments {
+		s.ComputeEnvironments[job.DeployedName(ce.ComputeEnvironmentName, env)] = ComputeEnvironmentState{
+			Type:          ce.ComputeResources.Type,
+			MinVCPUs:      ce.ComputeResources.MinvCpus,
+			MaxVCPUs:      ce.ComputeResources.MaxvCpus,
+			InstanceTypes: ce.ComputeResources.InstanceTypes,
+			Architectures: ce.Architectures,
+		}
+	}
+	for _, q := range docs.JobQueues {
+		state := JobQueueState{Priority: q.Priority}
+		for _, o := range q.ComputeEnvironmentOrder {
+			state.ComputeEnvironments = append(state.ComputeEnvironments, job.DeployedName(o.ComputeEnvironment, env))
+		}
+		s.JobQueues[job.DeployedName(q.JobQueueName, env)] = state
+	}
+	for _, def := range docs.JobDefinitions {
+		image := ""
+		if def.ContainerProperties != nil {
+			image = def.ContainerProperties.Image
+		} else if def.NodeProperties != nil && len(def.NodeProperties.NodeRangeProperties) > 0 {
+			image = def.NodeProperties.NodeRangeProperties[0].Container.Image
+		}
+		s.JobDefinitions[job.DeployedName(def.JobDefinitionName, env)] = image
+	}
+
+	if in := app.Storage.Input; in.Type == "s3" && in.Bucket != "" {
+		s.Buckets[in.Bucket] = BucketState{Access: "read"}
+	}
+	if out := app.Storage.Output; out.Type == "s3" && out.Bucket != "" {
+		s.Buckets[job.DeployedName(out.Bucket, env)] = BucketState{
+			Access:    "read-write",
+			Created:   true,
+			Lifecycle: out.Lifecycle,
+		}
+	}
+
+	return s, nil
+}
+
+// SnapshotPath returns where the last deploy of an application environment
+// to a region is recorded, under dir (normally config.HomeDir)
+func SnapshotPath(dir, app, env, region string) string {
+	return filepath.Join(dir, "deployments", region, fmt.Sprintf("%s-%s.json", app, env))
+}
+
+// LoadSnapshot reads a recorded snapshot, or returns nil if none exists
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deploy snapshot: %w", err)
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse deploy snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save records the snapshot at path
+func (s *Snapshot) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deploy snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write deploy snapshot: %w", err)
+	}
+	return nil
+}
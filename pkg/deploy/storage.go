@@ -0,0 +1,36 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deploy holds what the infrastructure renderers in its
+// subpackages share
+package deploy
+
+// LustreCapacity rounds a size up to a valid FSx for Lustre SCRATCH_2
+// capacity: 1200 GiB or a multiple of 2400 GiB
+func LustreCapacity(sizeGB int) int {
+	if sizeGB <= 1200 {
+		return 1200
+	}
+	return (sizeGB + 2399) / 2400 * 2400
+}
+
+// EFSProvisionedThroughput returns the MiB/s to provision for an EFS file
+// system of sizeGB: what bursting mode would give it, 50 MiB/s per TiB
+func EFSProvisionedThroughput(sizeGB int) int {
+	mibps := sizeGB * 50 / 1024
+	if mibps < 1 {
+		return 1
+	}
+	return mibps
+}
@@ -0,0 +1,32 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import "testing"
+
+func TestLustreCapacity(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{0, 1200},
+		{1200, 1200},
+		{1201, 2400},
+		{2400, 2400},
+		{3000, 4800},
+	}
+	for _, tt := range tests {
+		if got := LustreCapacity(tt.in); got != tt.want {
+			t.Errorf("LustreCapacity(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,602 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package terraform renders an application's infrastructure as a
+// Terraform JSON module (.tf.json), an alternative to the CloudFormation
+// template for sites that manage AWS with Terraform
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/deploy"
+	"github.com/aws-hpc/platform/pkg/deploy/batch"
+)
+
+// ProviderVersion is the AWS provider constraint the module is written
+// for; job queues use compute_environment_order, added in 5.62
+const ProviderVersion = ">= 5.62, < 6.0"
+
+// Module is a Terraform JSON configuration. Maps marshal with sorted keys
+// and slices follow app.yaml order, so output is deterministic.
+type Module struct {
+	Terraform map[string]any                       `json:"terraform"`
+	Variable  map[string]Variable                  `json:"variable"`
+	Data      map[string]map[string]any            `json:"data"`
+	Locals    map[string]any                       `json:"locals"`
+	Resource  map[string]map[string]map[string]any `json:"resource"`
+	Output    map[string]Output                    `json:"output"`
+}
+
+// Variable is a module input
+type Variable struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// Output is a module output
+type Output struct {
+	Description string `json:"description"`
+	Value       string `json:"value"`
+}
+
+// JSON returns the module as indented JSON, suitable for a .tf.json file
+func (m *Module) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return nil, fmt.Errorf("failed to encode terraform module: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FileName returns the conventional file name for an environment's module
+func FileName(app, env string) string {
+	return fmt.Sprintf("%s-%s.tf.json", app, env)
+}
+
+// Addresses of resources other resources refer to
+const (
+	instanceRole    = "aws_iam_role.batch_instance"
+	instanceProfile = "aws_iam_instance_profile.batch_instance"
+	jobRole         = "aws_iam_role.job"
+	outputBucket    = "aws_s3_bucket.output"
+	launchTemplate  = "aws_launch_template.scratch"
+	placementGroup  = "aws_placement_group.cluster"
+	efsMountTarget  = "aws_efs_mount_target.shared"
+)
+
+// Render builds the module that deploys app for the named environment.
+// The app name and environment are variables defaulting to the rendered
+// values; subnets and security groups are required variables.
+func Render(app *config.Application, env string) (*Module, error) {
+	if _, err := app.GetEnvironment(env); err != nil {
+		return nil, err
+	}
+
+	docs, err := batch.Render(app, batch.Options{
+		AccountID:  "${data.aws_caller_identity.current.account_id}",
+		Region:     "${data.aws_region.current.name}",
+		JobRoleArn: "${" + jobRole + ".arn}",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Module{
+		Terraform: map[string]any{
+			"required_providers": map[string]any{
+				"aws": map[string]any{
+					"source":  "hashicorp/aws",
+					"version": ProviderVersion,
+				},
+			},
+		},
+		Variable: map[string]Variable{
+			"app_name": {
+				Type:        "string",
+				Description: "Application name, used in resource names and tags",
+				Default:     app.Name,
+			},
+			"environment": {
+				Type:        "string",
+				Description: "Application environment, used in resource names and tags",
+				Default:     env,
+			},
+			"subnet_ids": {
+				Type:        "list(string)",
+				Description: subnetsDescription(app),
+			},
+			"security_group_ids": {
+				Type:        "list(string)",
+				Description: "Security groups for compute environments and shared storage",
+			},
+		},
+		Data: map[string]map[string]any{
+			"aws_caller_identity": {"current": map[string]any{}},
+			"aws_region":          {"current": map[string]any{}},
+			"aws_partition":       {"current": map[string]any{}},
+		},
+		Locals: map[string]any{
+			"tags": map[string]string{
+				"aws-hpc:app":         "${var.app_name}",
+				"aws-hpc:environment": "${var.environment}",
+				"aws-hpc:version":     app.Version,
+			},
+		},
+		Resource: map[string]map[string]map[string]any{},
+		Output:   map[string]Output{},
+	}
+
+	if err := m.addRoles(app); err != nil {
+		return nil, err
+	}
+	if err := m.addOutputBucket(app); err != nil {
+		return nil, err
+	}
+	sharedSource, err := m.addSharedStorage(app)
+	if err != nil {
+		return nil, err
+	}
+	if app.Networking.PlacementGroup {
+		m.add(placementGroup, map[string]any{
+			"name":     "${var.app_name}-${var.environment}",
+			"strategy": "cluster",
+			"tags":     "${local.tags}",
+		})
+	}
+	m.addLaunchTemplate(app, sharedSource)
+
+	for _, ce := range docs.ComputeEnvironments {
+		m.addComputeEnvironment(ce)
+	}
+	for _, q := range docs.JobQueues {
+		m.addJobQueue(q)
+	}
+	for _, def := range docs.JobDefinitions {
+		if err := m.addJobDefinition(def); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// add adds a resource by its address, "type.name"
+func (m *Module) add(address string, body map[string]any) {
+	typ, name, _ := strings.Cut(address, ".")
+	if m.Resource[typ] == nil {
+		m.Resource[typ] = map[string]map[string]any{}
+	}
+	m.Resource[typ][name] = body
+}
+
+func (m *Module) has(address string) bool {
+	typ, name, _ := strings.Cut(address, ".")
+	_, ok := m.Resource[typ][name]
+	return ok
+}
+
+// addRoles adds the ECS instance role and the role assumed by job
+// containers, which may read the input bucket and write the output bucket
+func (m *Module) addRoles(app *config.Application) error {
+	assumeEC2, err := policyJSON(assumeRoleStatement("ec2.amazonaws.com"))
+	if err != nil {
+		return err
+	}
+	m.add(instanceRole, map[string]any{
+		"name_prefix":        "${var.app_name}-${var.environment}-",
+		"assume_role_policy": assumeEC2,
+		"tags":               "${local.tags}",
+	})
+	m.add("aws_iam_role_policy_attachment.batch_instance_ecs", map[string]any{
+		"role":       "${" + instanceRole + ".name}",
+		"policy_arn": "arn:${data.aws_partition.current.partition}:iam::aws:policy/service-role/AmazonEC2ContainerServiceforEC2Role",
+	})
+	m.add(instanceProfile, map[string]any{
+		"name_prefix": "${var.app_name}-${var.environment}-",
+		"role":        "${" + instanceRole + ".name}",
+		"tags":        "${local.tags}",
+	})
+
+	assumeECS, err := policyJSON(assumeRoleStatement("ecs-tasks.amazonaws.com"))
+	if err != nil {
+		return err
+	}
+	m.add(jobRole, map[string]any{
+		"name_prefix":        "${var.app_name}-${var.environment}-",
+		"assume_role_policy": assumeECS,
+		"tags":               "${local.tags}",
+	})
+
+	var statements []any
+	if in := app.Storage.Input; in.Type == "s3" && in.Bucket != "" {
+		arn := "arn:${data.aws_partition.current.partition}:s3:::" + in.Bucket
+		statements = append(statements, map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"s3:GetObject", "s3:ListBucket"},
+			"Resource": []string{arn, arn + "/*"},
+		})
+	}
+	if out := app.Storage.Output; out.Type == "s3" && out.Bucket != "" {
+		statements = append(statements, map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"s3:AbortMultipartUpload", "s3:GetObject", "s3:ListBucket", "s3:PutObject"},
+			"Resource": []string{"${" + outputBucket + ".arn}", "${" + outputBucket + ".arn}/*"},
+		})
+	}
+	if len(statements) > 0 {
+		policy, err := policyJSON(statements...)
+		if err != nil {
+			return err
+		}
+		m.add("aws_iam_role_policy.job_data", map[string]any{
+			"name":   "aws-hpc-data",
+			"role":   "${" + jobRole + ".id}",
+			"policy": policy,
+		})
+	}
+
+	m.Output["job_role_arn"] = Output{Description: "Role assumed by job containers", Value: "${" + jobRole + ".arn}"}
+	return nil
+}
+
+// addOutputBucket adds the results bucket with encryption, public access
+// blocking, its lifecycle rules and a policy denying insecure transport
+func (m *Module) addOutputBucket(app *config.Application) error {
+	out := app.Storage.Output
+	if out.Type != "s3" || out.Bucket == "" {
+		return nil
+	}
+
+	bucketID := "${" + outputBucket + ".id}"
+	m.add(outputBucket, map[string]any{
+		"bucket": physicalName(out.Bucket),
+		"tags":   "${local.tags}",
+	})
+	m.add("aws_s3_bucket_server_side_encryption_configuration.output", map[string]any{
+		"bucket": bucketID,
+		"rule": []any{map[string]any{
+			"apply_server_side_encryption_by_default": []any{map[string]any{"sse_algorithm": "AES256"}},
+		}},
+	})
+	m.add("aws_s3_bucket_public_access_block.output", map[string]any{
+		"bucket":                  bucketID,
+		"block_public_acls":       true,
+		"block_public_policy":     true,
+		"ignore_public_acls":      true,
+		"restrict_public_buckets": true,
+	})
+	if rule := lifecycleRule(out); rule != nil {
+		m.add("aws_s3_bucket_lifecycle_configuration.output", map[string]any{
+			"bucket": bucketID,
+			"rule":   []any{rule},
+		})
+	}
+
+	policy, err := policyJSON(map[string]any{
+		"Sid":       "DenyInsecureTransport",
+		"Effect":    "Deny",
+		"Principal": "*",
+		"Action":    "s3:*",
+		"Resource":  []string{"${" + outputBucket + ".arn}", "${" + outputBucket + ".arn}/*"},
+		"Condition": map[string]any{"Bool": map[string]any{"aws:SecureTransport": "false"}},
+	})
+	if err != nil {
+		return err
+	}
+	m.add("aws_s3_bucket_policy.output", map[string]any{
+		"bucket": bucketID,
+		"policy": policy,
+	})
+
+	m.Output["output_bucket"] = Output{Description: "Results bucket", Value: bucketID}
+	return nil
+}
+
+// lifecycleRule converts a storage lifecycle policy, or returns nil when
+// the location has none
+func lifecycleRule(loc config.StorageLocation) map[string]any {
+	lc := loc.Lifecycle
+	if lc == nil || (lc.TransitionIA == 0 && lc.TransitionGlacier == 0 && lc.Expiration == 0) {
+		return nil
+	}
+
+	rule := map[string]any{
+		"id":     "aws-hpc-lifecycle",
+		"status": "Enabled",
+		"filter": []any{map[string]any{"prefix": loc.Prefix}},
+	}
+	var transitions []any
+	if lc.TransitionIA > 0 {
+		transitions = append(transitions, map[string]any{
+			"days":          lc.TransitionIA,
+			"storage_class": "STANDARD_IA",
+		})
+	}
+	if lc.TransitionGlacier > 0 {
+		transitions = append(transitions, map[string]any{
+			"days":          lc.TransitionGlacier,
+			"storage_class": "GLACIER",
+		})
+	}
+	if len(transitions) > 0 {
+		rule["transition"] = transitions
+	}
+	if lc.Expiration > 0 {
+		rule["expiration"] = []any{map[string]any{"days": lc.Expiration}}
+	}
+	return rule
+}
+
+// addSharedStorage adds the EFS or FSx for Lustre file system and returns
+// its mount source as a Terraform string, or "" without shared storage
+func (m *Module) addSharedStorage(app *config.Application) (string, error) {
+	shared := app.Storage.Shared
+	if shared == nil {
+		return "", nil
+	}
+
+	switch shared.Type {
+	case "efs":
+		fs := map[string]any{
+			"encrypted":        true,
+			"performance_mode": "generalPurpose",
+			"throughput_mode":  "bursting",
+			"tags":             "${local.tags}",
+		}
+		if shared.ThroughputMode != "" {
+			fs["throughput_mode"] = shared.ThroughputMode
+		}
+		if shared.ThroughputMode == "provisioned" {
+			fs["provisioned_throughput_in_mibps"] = deploy.EFSProvisionedThroughput(shared.SizeGB)
+		}
+		m.add("aws_efs_file_system.shared", fs)
+		// Hosts mount EFS through a mount target in their own availability
+		// zone, so there is one in each subnet
+		m.add(efsMountTarget, map[string]any{
+			"count":           "${length(var.subnet_ids)}",
+			"file_system_id":  "${aws_efs_file_system.shared.id}",
+			"subnet_id":       "${var.subnet_ids[count.index]}",
+			"security_groups": "${var.security_group_ids}",
+		})
+		m.Output["shared_file_system"] = Output{Description: "Shared EFS file system", Value: "${aws_efs_file_system.shared.id}"}
+		return "${aws_efs_file_system.shared.dns_name}:/", nil
+
+	case "fsx-lustre":
+		m.add("aws_fsx_lustre_file_system.shared", map[string]any{
+			"deployment_type":    "SCRATCH_2",
+			"storage_capacity":   deploy.LustreCapacity(shared.SizeGB),
+			"subnet_ids":         []string{"${var.subnet_ids[0]}"},
+			"security_group_ids": "${var.security_group_ids}",
+			"tags":               "${local.tags}",
+		})
+		m.Output["shared_file_system"] = Output{Description: "Shared FSx for Lustre file system", Value: "${aws_fsx_lustre_file_system.shared.id}"}
+		return "${aws_fsx_lustre_file_system.shared.dns_name}@tcp:/${aws_fsx_lustre_file_system.shared.mount_name}", nil
+
+	default:
+		return "", fmt.Errorf("unsupported shared storage type %q (expected efs or fsx-lustre)", shared.Type)
+	}
+}
+
+// addLaunchTemplate adds the launch template that attaches scratch storage
+// and mounts it, and any shared storage, on compute environment hosts
+func (m *Module) addLaunchTemplate(app *config.Application, sharedSource string) {
+	m.Locals["user_data"] = batch.UserData(app, sharedSource)
+
+	lt := map[string]any{
+		"name_prefix": "${var.app_name}-${var.environment}-scratch-",
+		"user_data":   "${base64encode(local.user_data)}",
+		"tag_specifications": []any{map[string]any{
+			"resource_type": "volume",
+			"tags":          "${local.tags}",
+		}},
+		"tags": "${local.tags}",
+	}
+
+	if scratch := app.Storage.Scratch; scratch.Type == "ebs" && scratch.SizeGB > 0 {
+		ebs := map[string]any{
+			"volume_size":           scratch.SizeGB,
+			"volume_type":           "gp3",
+			"encrypted":             true,
+			"delete_on_termination": true,
+		}
+		if scratch.VolumeType != "" {
+			ebs["volume_type"] = scratch.VolumeType
+		}
+		if scratch.IOPS > 0 {
+			ebs["iops"] = scratch.IOPS
+		}
+		lt["block_device_mappings"] = []any{map[string]any{
+			"device_name": batch.ScratchDevice,
+			"ebs":         []any{ebs},
+		}}
+	}
+
+	m.add(launchTemplate, lt)
+}
+
+func (m *Module) addComputeEnvironment(ce batch.ComputeEnvironment) {
+	cr := ce.ComputeResources
+	ec2Config := make([]any, len(cr.Ec2Configuration))
+	for i, c := range cr.Ec2Configuration {
+		ec2Config[i] = map[string]any{"image_type": c.ImageType}
+	}
+
+	resources := map[string]any{
+		"type":                cr.Type,
+		"allocation_strategy": cr.AllocationStrategy,
+		"min_vcpus":           cr.MinvCpus,
+		"max_vcpus":           cr.MaxvCpus,
+		"instance_type":       cr.InstanceTypes,
+		"subnets":             "${var.subnet_ids}",
+		"security_group_ids":  "${var.security_group_ids}",
+		"instance_role":       "${" + instanceProfile + ".arn}",
+		"ec2_configuration":   ec2Config,
+		"launch_template": []any{map[string]any{
+			"launch_template_id": "${" + launchTemplate + ".id}",
+			"version":            "${" + launchTemplate + ".latest_version}",
+		}},
+		"tags": "${local.tags}",
+	}
+	if cr.BidPercentage > 0 {
+		resources["bid_percentage"] = cr.BidPercentage
+	}
+	if m.has(placementGroup) {
+		resources["placement_group"] = "${" + placementGroup + ".name}"
+	}
+
+	body := map[string]any{
+		"compute_environment_name": physicalName(ce.ComputeEnvironmentName),
+		"type":                     ce.Type,
+		"state":                    ce.State,
+		"compute_resources":        []any{resources},
+		"tags":                     "${local.tags}",
+	}
+	if m.has(efsMountTarget) {
+		// Hosts mount EFS at boot, so the mount target must exist first
+		body["depends_on"] = []string{efsMountTarget}
+	}
+	m.add(address("aws_batch_compute_environment", ce.ComputeEnvironmentName), body)
+}
+
+func (m *Module) addJobQueue(q batch.JobQueue) {
+	order := make([]any, len(q.ComputeEnvironmentOrder))
+	for i, o := range q.ComputeEnvironmentOrder {
+		order[i] = map[string]any{
+			"order":               o.Order,
+			"compute_environment": "${" + address("aws_batch_compute_environment", o.ComputeEnvironment) + ".arn}",
+		}
+	}
+
+	addr := address("aws_batch_job_queue", q.JobQueueName)
+	m.add(addr, map[string]any{
+		"name":                      physicalName(q.JobQueueName),
+		"state":                     q.State,
+		"priority":                  q.Priority,
+		"compute_environment_order": order,
+		"tags":                      "${local.tags}",
+	})
+	m.Output[resourceName(q.JobQueueName)+"_queue"] = Output{
+		Description: "Job queue " + q.JobQueueName,
+		Value:       "${" + addr + ".arn}",
+	}
+}
+
+// addJobDefinition adds a job definition. Container and node properties
+// are JSON strings in the API's own format, as the provider expects.
+func (m *Module) addJobDefinition(def batch.JobDefinition) error {
+	conditions := make([]any, len(def.RetryStrategy.EvaluateOnExit))
+	for i, e := range def.RetryStrategy.EvaluateOnExit {
+		c := map[string]any{"action": e.Action}
+		if e.OnStatusReason != "" {
+			c["on_status_reason"] = e.OnStatusReason
+		}
+		if e.OnReason != "" {
+			c["on_reason"] = e.OnReason
+		}
+		conditions[i] = c
+	}
+
+	body := map[string]any{
+		"name":                  physicalName(def.JobDefinitionName),
+		"type":                  def.Type,
+		"platform_capabilities": def.PlatformCapabilities,
+		"propagate_tags":        def.PropagateTags,
+		"retry_strategy": []any{map[string]any{
+			"attempts":         def.RetryStrategy.Attempts,
+			"evaluate_on_exit": conditions,
+		}},
+		"tags": "${local.tags}",
+	}
+	if def.ContainerProperties != nil {
+		data, err := json.Marshal(def.ContainerProperties)
+		if err != nil {
+			return fmt.Errorf("failed to encode container properties for %s: %w", def.JobDefinitionName, err)
+		}
+		body["container_properties"] = string(data)
+	}
+	if def.NodeProperties != nil {
+		data, err := json.Marshal(def.NodeProperties)
+		if err != nil {
+			return fmt.Errorf("failed to encode node properties for %s: %w", def.JobDefinitionName, err)
+		}
+		body["node_properties"] = string(data)
+	}
+
+	m.add(address("aws_batch_job_definition", def.JobDefinitionName), body)
+	return nil
+}
+
+// address returns the resource address for a named AWS resource
+func address(typ, name string) string {
+	return typ + "." + resourceName(name)
+}
+
+// subnetsDescription describes the subnet_ids variable, noting where
+// shared storage is placed
+func subnetsDescription(app *config.Application) string {
+	switch shared := app.Storage.Shared; {
+	case shared == nil:
+		return "Subnets for compute environments"
+	case shared.Type == "efs":
+		return "Subnets for compute environments, each in a different availability zone; EFS has a mount target in each"
+	default:
+		return "Subnets for compute environments; shared storage is created in the first"
+	}
+}
+
+// physicalName suffixes a resource name with the environment variable, as
+// job.DeployedName does, so each environment has its own bucket and Batch
+// resources
+func physicalName(name string) string {
+	return name + "-${var.environment}"
+}
+
+// resourceName turns a name such as "geos-chem-spot-x86" into a Terraform
+// identifier such as "geos_chem_spot_x86"
+func resourceName(name string) string {
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+}
+
+func assumeRoleStatement(service string) map[string]any {
+	return map[string]any{
+		"Effect":    "Allow",
+		"Principal": map[string]any{"Service": service},
+		"Action":    "sts:AssumeRole",
+	}
+}
+
+// policyJSON returns an IAM policy document as the JSON string resource
+// arguments take
+func policyJSON(statements ...any) (string, error) {
+	data, err := json.Marshal(map[string]any{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode policy: %w", err)
+	}
+	return string(data), nil
+}
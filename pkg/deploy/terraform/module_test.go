@@ -0,0 +1,126 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/job"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		modify func(app *config.Application)
+	}{
+		{
+			name: "geos-chem-production",
+			env:  "production",
+		},
+		{
+			name: "geos-chem-efs-placement",
+			env:  "benchmark",
+			modify: func(app *config.Application) {
+				app.Storage.Shared = &config.SharedStorage{Type: "efs", SizeGB: 500}
+				app.Networking = config.NetworkingSpec{EFA: true, PlacementGroup: true}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := config.LoadApplication(filepath.Join("..", "..", "..", "applications", "geos-chem"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.modify != nil {
+				tt.modify(app)
+			}
+
+			m, err := Render(app, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.JSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".tf.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("module differs from %s (run go test -update after checking the change)", golden)
+			}
+		})
+	}
+}
+
+func TestRenderEnvironmentNames(t *testing.T) {
+	app, err := config.LoadApplication(filepath.Join("..", "..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Render(app, "benchmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each environment names its bucket and Batch resources after itself,
+	// as jobs submitted with --env expect
+	names := map[string]string{
+		"aws_s3_bucket":                 "bucket",
+		"aws_batch_compute_environment": "compute_environment_name",
+		"aws_batch_job_queue":           "name",
+		"aws_batch_job_definition":      "name",
+	}
+	deployed := map[string]bool{}
+	for typ, attr := range names {
+		if len(m.Resource[typ]) == 0 {
+			t.Fatalf("no %s resources", typ)
+		}
+		for address, body := range m.Resource[typ] {
+			name, _ := body[attr].(string)
+			if !strings.HasSuffix(name, "-${var.environment}") {
+				t.Errorf("%s.%s %s = %q, want it suffixed with the environment", typ, address, attr, name)
+			}
+			deployed[strings.ReplaceAll(name, "${var.environment}", "benchmark")] = true
+		}
+	}
+
+	spec, err := job.Resolve(app, &config.Environment{Name: "benchmark"}, job.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deployed[spec.Queue] || !deployed[spec.JobDefinition] {
+		t.Errorf("job queue %s or job definition %s is not deployed (deployed names: %v)", spec.Queue, spec.JobDefinition, deployed)
+	}
+}
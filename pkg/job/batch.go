@@ -0,0 +1,374 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/command"
+)
+
+// DefaultLogGroup is the CloudWatch log group AWS Batch writes job output to
+const DefaultLogGroup = "/aws/batch/job"
+
+// Batch submits jobs to AWS Batch through the aws CLI
+type Batch struct {
+	Region       string
+	LogGroup     string
+	PollInterval time.Duration
+	Runner       command.Runner
+}
+
+// NewBatch creates an AWS Batch backend for the region
+func NewBatch(region string) *Batch {
+	return &Batch{
+		Region:       region,
+		LogGroup:     DefaultLogGroup,
+		PollInterval: 5 * time.Second,
+		Runner:       command.Exec{},
+	}
+}
+
+// Name returns the backend identifier
+func (b *Batch) Name() string {
+	return "batch"
+}
+
+// batchJob mirrors the fields of a Batch JobDetail or JobSummary we use
+type batchJob struct {
+	JobID        string `json:"jobId"`
+	JobName      string `json:"jobName"`
+	JobQueue     string `json:"jobQueue"`
+	Status       string `json:"status"`
+	StatusReason string `json:"statusReason"`
+	CreatedAt    int64  `json:"createdAt"`
+	StartedAt    int64  `json:"startedAt"`
+	StoppedAt    int64  `json:"stoppedAt"`
+	Container    *struct {
+		ExitCode      *int   `json:"exitCode"`
+		LogStreamName string `json:"logStreamName"`
+	} `json:"container"`
+}
+
+func (bj *batchJob) toJob() *Job {
+	j := &Job{
+		ID:           bj.JobID,
+		Name:         bj.JobName,
+		Queue:        bj.JobQueue,
+		Status:       Status(bj.Status),
+		StatusReason: bj.StatusReason,
+		CreatedAt:    time.UnixMilli(bj.CreatedAt).UTC(),
+	}
+	if bj.StartedAt > 0 {
+		t := time.UnixMilli(bj.StartedAt).UTC()
+		j.StartedAt = &t
+	}
+	if bj.StoppedAt > 0 {
+		t := time.UnixMilli(bj.StoppedAt).UTC()
+		j.StoppedAt = &t
+	}
+	if bj.Container != nil {
+		j.ExitCode = bj.Container.ExitCode
+		j.LogStream = bj.Container.LogStreamName
+	}
+	return j
+}
+
+// Submit calls batch submit-job with container overrides for the job's
+// resources, environment and entrypoint arguments. Multi-node parallel
+// jobs take them as node overrides for every node instead.
+func (b *Batch) Submit(ctx context.Context, spec *Spec) (*Job, error) {
+	if spec.Queue == "" {
+		return nil, fmt.Errorf("no job queue runs architecture %s", spec.Architecture)
+	}
+
+	flag, overrides := "--container-overrides", any(b.containerOverrides(spec))
+	if spec.MultiNode {
+		flag, overrides = "--node-overrides", b.nodeOverrides(spec)
+	}
+	encoded, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job overrides: %w", err)
+	}
+
+	args := []string{"batch", "submit-job",
+		"--job-name", spec.Name,
+		"--job-queue", spec.Queue,
+		"--job-definition", spec.JobDefinition,
+		flag, string(encoded)}
+	if spec.TimeoutSeconds > 0 {
+		// Batch rejects timeouts under a minute
+		args = append(args, "--timeout", "attemptDurationSeconds="+strconv.Itoa(max(spec.TimeoutSeconds, 60)))
+	}
+	if spec.RetryAttempts > 0 {
+		args = append(args, "--retry-strategy", "attempts="+strconv.Itoa(spec.RetryAttempts))
+	}
+
+	out, err := b.aws(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	var resp struct {
+		JobID   string `json:"jobId"`
+		JobName string `json:"jobName"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse submit-job response: %w", err)
+	}
+
+	return &Job{
+		ID:        resp.JobID,
+		Name:      resp.JobName,
+		Queue:     spec.Queue,
+		Status:    StatusSubmitted,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+type keyValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type resourceRequirement struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type containerOverrides struct {
+	Command              []string              `json:"command"`
+	Environment          []keyValue            `json:"environment,omitempty"`
+	ResourceRequirements []resourceRequirement `json:"resourceRequirements,omitempty"`
+}
+
+func (b *Batch) containerOverrides(spec *Spec) containerOverrides {
+	o := containerOverrides{Command: spec.Command()}
+
+	names := make([]string, 0, len(spec.Env))
+	for name := range spec.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o.Environment = append(o.Environment, keyValue{Name: name, Value: spec.Env[name]})
+	}
+
+	if spec.VCPUs > 0 {
+		o.ResourceRequirements = append(o.ResourceRequirements,
+			resourceRequirement{Type: "VCPU", Value: strconv.Itoa(spec.VCPUs)})
+	}
+	if spec.MemoryMB > 0 {
+		o.ResourceRequirements = append(o.ResourceRequirements,
+			resourceRequirement{Type: "MEMORY", Value: strconv.Itoa(spec.MemoryMB)})
+	}
+	return o
+}
+
+type nodeOverrides struct {
+	NodePropertyOverrides []nodePropertyOverride `json:"nodePropertyOverrides"`
+}
+
+type nodePropertyOverride struct {
+	TargetNodes        string             `json:"targetNodes"`
+	ContainerOverrides containerOverrides `json:"containerOverrides"`
+}
+
+// nodeOverrides applies the container overrides to every node of a
+// multi-node parallel job
+func (b *Batch) nodeOverrides(spec *Spec) nodeOverrides {
+	return nodeOverrides{NodePropertyOverrides: []nodePropertyOverride{
+		{TargetNodes: "0:", ContainerOverrides: b.containerOverrides(spec)},
+	}}
+}
+
+// Describe calls batch describe-jobs
+func (b *Batch) Describe(ctx context.Context, id string) (*Job, error) {
+	out, err := b.aws(ctx, "batch", "describe-jobs", "--jobs", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe job: %w", err)
+	}
+
+	var resp struct {
+		Jobs []batchJob `json:"jobs"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse describe-jobs response: %w", err)
+	}
+	if len(resp.Jobs) == 0 {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return resp.Jobs[0].toJob(), nil
+}
+
+// List calls batch list-jobs. AWS Batch only lists jobs per queue, so
+// opts.Queue is required. Without a status filter every state is queried.
+func (b *Batch) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	if opts.Queue == "" {
+		return nil, fmt.Errorf("a job queue is required to list AWS Batch jobs")
+	}
+
+	statuses := Statuses
+	if opts.Status != "" {
+		statuses = []Status{opts.Status}
+	}
+
+	var jobs []*Job
+	for _, status := range statuses {
+		out, err := b.aws(ctx, "batch", "list-jobs",
+			"--job-queue", opts.Queue,
+			"--job-status", string(status))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+
+		var resp struct {
+			JobSummaryList []batchJob `json:"jobSummaryList"`
+		}
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse list-jobs response: %w", err)
+		}
+		for i := range resp.JobSummaryList {
+			j := resp.JobSummaryList[i].toJob()
+			j.Queue = opts.Queue
+			jobs = append(jobs, j)
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, k int) bool {
+		return jobs[i].CreatedAt.After(jobs[k].CreatedAt)
+	})
+	if opts.Limit > 0 && len(jobs) > opts.Limit {
+		jobs = jobs[:opts.Limit]
+	}
+	return jobs, nil
+}
+
+// Cancel calls batch terminate-job, which stops jobs in any state
+func (b *Batch) Cancel(ctx context.Context, id string, reason string) error {
+	if _, err := b.aws(ctx, "batch", "terminate-job", "--job-id", id, "--reason", reason); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return nil
+}
+
+// Logs reads the job's CloudWatch log stream. With follow set it polls for
+// new events until the job reaches a terminal state.
+func (b *Batch) Logs(ctx context.Context, id string, follow bool, w io.Writer) error {
+	j, err := b.Describe(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// The log stream only exists once the container has started
+	for j.LogStream == "" {
+		if !follow || j.Status.Done() {
+			return fmt.Errorf("job %s has no logs yet (status %s)", id, j.Status)
+		}
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		if j, err = b.Describe(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	token := ""
+	for {
+		next, err := b.writeLogEvents(ctx, j.LogStream, token, w)
+		if err != nil {
+			return err
+		}
+		caughtUp := next == token
+		token = next
+
+		if !follow {
+			if caughtUp {
+				return nil
+			}
+			continue
+		}
+		if caughtUp {
+			if j.Status.Done() {
+				return nil
+			}
+			if err := b.wait(ctx); err != nil {
+				return err
+			}
+			if j, err = b.Describe(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeLogEvents writes one page of log events and returns the forward
+// token for the next page. CloudWatch returns the same token once the end
+// of the stream has been reached.
+func (b *Batch) writeLogEvents(ctx context.Context, stream, token string, w io.Writer) (string, error) {
+	args := []string{"logs", "get-log-events",
+		"--log-group-name", b.LogGroup,
+		"--log-stream-name", stream,
+		"--start-from-head"}
+	if token != "" {
+		args = append(args, "--next-token", token)
+	}
+
+	out, err := b.aws(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	var resp struct {
+		Events []struct {
+			Message string `json:"message"`
+		} `json:"events"`
+		NextForwardToken string `json:"nextForwardToken"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse get-log-events response: %w", err)
+	}
+
+	for _, e := range resp.Events {
+		if _, err := fmt.Fprintln(w, e.Message); err != nil {
+			return "", err
+		}
+	}
+	return resp.NextForwardToken, nil
+}
+
+func (b *Batch) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(b.PollInterval):
+		return nil
+	}
+}
+
+// aws runs an aws CLI command with JSON output in the backend's region
+func (b *Batch) aws(ctx context.Context, args ...string) ([]byte, error) {
+	args = append(args, "--output", "json")
+	if b.Region != "" {
+		args = append(args, "--region", b.Region)
+	}
+	return command.Output(ctx, b.Runner, "aws", args...)
+}
@@ -0,0 +1,255 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// awsRunner answers aws CLI commands with canned JSON, keyed by the
+// command's arguments up to --output
+type awsRunner struct {
+	responses map[string][]string // later calls get later responses
+	calls     []string
+}
+
+func (r *awsRunner) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	if name != "aws" {
+		return fmt.Errorf("unexpected command %s", name)
+	}
+	key, _, _ := strings.Cut(strings.Join(args, " "), " --output")
+	r.calls = append(r.calls, key)
+
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		return fmt.Errorf("unexpected command aws %s", key)
+	}
+	if len(responses) > 1 {
+		r.responses[key] = responses[1:]
+	}
+	_, err := io.WriteString(stdout, responses[0])
+	return err
+}
+
+func newTestBatch(responses map[string][]string) (*Batch, *awsRunner) {
+	r := &awsRunner{responses: responses}
+	b := NewBatch("")
+	b.Runner = r
+	b.PollInterval = 0
+	return b, r
+}
+
+func TestBatchSubmit(t *testing.T) {
+	spec := &Spec{
+		Name:           "geos-chem-classic-20251001-120000",
+		Architecture:   "c7a",
+		Queue:          "geos-chem-spot-x86-production",
+		JobDefinition:  "geos-chem-classic-c7a-production",
+		VCPUs:          16,
+		MemoryMB:       32768,
+		Input:          "s3://in/",
+		Output:         "s3://out/",
+		TimeoutSeconds: 30,
+		RetryAttempts:  2,
+		Env:            map[string]string{"B": "2", "A": "1"},
+	}
+	overrides, err := json.Marshal((&Batch{}).containerOverrides(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	submit := "batch submit-job --job-name geos-chem-classic-20251001-120000" +
+		" --job-queue geos-chem-spot-x86-production --job-definition geos-chem-classic-c7a-production" +
+		" --container-overrides " + string(overrides) +
+		" --timeout attemptDurationSeconds=60 --retry-strategy attempts=2"
+	b, r := newTestBatch(map[string][]string{
+		submit: {`{"jobId": "abc-123", "jobName": "geos-chem-classic-20251001-120000"}`},
+	})
+
+	j, err := b.Submit(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("%v (calls: %v)", err, r.calls)
+	}
+	if j.ID != "abc-123" || j.Queue != spec.Queue || j.Status != StatusSubmitted {
+		t.Errorf("Submit() = %+v", j)
+	}
+
+	want := `{"command":["--input","s3://in/","--output","s3://out/"],` +
+		`"environment":[{"name":"A","value":"1"},{"name":"B","value":"2"}],` +
+		`"resourceRequirements":[{"type":"VCPU","value":"16"},{"type":"MEMORY","value":"32768"}]}`
+	if string(overrides) != want {
+		t.Errorf("container overrides = %s\nwant %s", overrides, want)
+	}
+
+	spec.Queue = ""
+	if _, err := b.Submit(context.Background(), spec); err == nil {
+		t.Error("expected an error without a job queue")
+	}
+}
+
+func TestBatchSubmitMultiNode(t *testing.T) {
+	spec := &Spec{
+		Name:          "geos-chem-gchp-20251001-120000",
+		Architecture:  "c7a",
+		Queue:         "geos-chem-ondemand-production",
+		JobDefinition: "geos-chem-gchp-c7a-production",
+		MultiNode:     true,
+		VCPUs:         16,
+		Input:         "s3://in/",
+		Output:        "s3://out/",
+	}
+	// Batch rejects container overrides for multi-node parallel jobs, so
+	// they are given for every node
+	submit := "batch submit-job --job-name geos-chem-gchp-20251001-120000" +
+		" --job-queue geos-chem-ondemand-production --job-definition geos-chem-gchp-c7a-production" +
+		` --node-overrides {"nodePropertyOverrides":[{"targetNodes":"0:","containerOverrides":` +
+		`{"command":["--input","s3://in/","--output","s3://out/"],"resourceRequirements":[{"type":"VCPU","value":"16"}]}}]}`
+	b, r := newTestBatch(map[string][]string{
+		submit: {`{"jobId": "abc-123", "jobName": "geos-chem-gchp-20251001-120000"}`},
+	})
+
+	if _, err := b.Submit(context.Background(), spec); err != nil {
+		t.Fatalf("%v (calls: %v)", err, r.calls)
+	}
+}
+
+func TestBatchList(t *testing.T) {
+	list := func(status Status) string {
+		return "batch list-jobs --job-queue geos-chem-spot-x86-production --job-status " + string(status)
+	}
+	responses := map[string][]string{}
+	for _, status := range Statuses {
+		responses[list(status)] = []string{`{"jobSummaryList": []}`}
+	}
+	responses[list(StatusRunning)] = []string{`{"jobSummaryList": [
+		{"jobId": "run-1", "jobName": "a", "status": "RUNNING", "createdAt": 1759320000000, "startedAt": 1759320060000}]}`}
+	responses[list(StatusSucceeded)] = []string{`{"jobSummaryList": [
+		{"jobId": "done-1", "jobName": "b", "status": "SUCCEEDED", "createdAt": 1759310000000,
+		 "startedAt": 1759310060000, "stoppedAt": 1759313660000, "container": {"exitCode": 0}},
+		{"jobId": "done-2", "jobName": "c", "status": "SUCCEEDED", "createdAt": 1759330000000}]}`}
+	b, r := newTestBatch(responses)
+
+	jobs, err := b.List(context.Background(), ListOptions{Queue: "geos-chem-spot-x86-production", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.calls) != len(Statuses) {
+		t.Errorf("list-jobs calls = %d, want one per status", len(r.calls))
+	}
+	if len(jobs) != 2 || jobs[0].ID != "done-2" || jobs[1].ID != "run-1" {
+		t.Fatalf("List() = %v, want the two newest jobs", jobs)
+	}
+	if j := jobs[1]; j.Queue != "geos-chem-spot-x86-production" || j.Status != StatusRunning || j.StartedAt == nil || j.StoppedAt != nil {
+		t.Errorf("running job = %+v", j)
+	}
+
+	r.calls = nil
+	jobs, err = b.List(context.Background(), ListOptions{Queue: "geos-chem-spot-x86-production", Status: StatusSucceeded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.calls) != 1 || len(jobs) != 2 {
+		t.Fatalf("List(SUCCEEDED) = %v with calls %v", jobs, r.calls)
+	}
+	if j := jobs[1]; j.ExitCode == nil || *j.ExitCode != 0 || j.Runtime().Hours() != 1 {
+		t.Errorf("finished job = %+v", j)
+	}
+
+	if _, err := b.List(context.Background(), ListOptions{}); err == nil {
+		t.Error("expected an error without a job queue")
+	}
+}
+
+func TestBatchLogs(t *testing.T) {
+	describe := "batch describe-jobs --jobs abc-123"
+	events := "logs get-log-events --log-group-name /aws/batch/job --log-stream-name stream/abc-123 --start-from-head"
+	pages := map[string][]string{
+		events:                       {`{"events": [{"message": "one"}, {"message": "two"}], "nextForwardToken": "f/1"}`},
+		events + " --next-token f/1": {`{"events": [{"message": "three"}], "nextForwardToken": "f/2"}`},
+		events + " --next-token f/2": {`{"events": [], "nextForwardToken": "f/2"}`},
+	}
+
+	t.Run("pages until the token repeats", func(t *testing.T) {
+		responses := map[string][]string{
+			describe: {`{"jobs": [{"jobId": "abc-123", "status": "SUCCEEDED", "container": {"logStreamName": "stream/abc-123"}}]}`},
+		}
+		for k, v := range pages {
+			responses[k] = v
+		}
+		b, r := newTestBatch(responses)
+
+		var out bytes.Buffer
+		if err := b.Logs(context.Background(), "abc-123", false, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "one\ntwo\nthree\n" {
+			t.Errorf("logs = %q", out.String())
+		}
+		if len(r.calls) != 4 {
+			t.Errorf("calls = %v, want a describe and three pages", r.calls)
+		}
+	})
+
+	t.Run("follow waits for the stream and the end of the job", func(t *testing.T) {
+		responses := map[string][]string{
+			describe: {
+				`{"jobs": [{"jobId": "abc-123", "status": "RUNNABLE"}]}`,
+				`{"jobs": [{"jobId": "abc-123", "status": "RUNNING", "container": {"logStreamName": "stream/abc-123"}}]}`,
+				`{"jobs": [{"jobId": "abc-123", "status": "SUCCEEDED", "container": {"logStreamName": "stream/abc-123"}}]}`,
+			},
+		}
+		for k, v := range pages {
+			responses[k] = v
+		}
+		// The last page is read again once the job has finished
+		responses[events+" --next-token f/2"] = []string{
+			`{"events": [], "nextForwardToken": "f/2"}`,
+			`{"events": [{"message": "four"}], "nextForwardToken": "f/3"}`,
+		}
+		responses[events+" --next-token f/3"] = []string{`{"events": [], "nextForwardToken": "f/3"}`}
+		b, _ := newTestBatch(responses)
+
+		var out bytes.Buffer
+		if err := b.Logs(context.Background(), "abc-123", true, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "one\ntwo\nthree\nfour\n" {
+			t.Errorf("logs = %q", out.String())
+		}
+	})
+
+	t.Run("no stream without follow", func(t *testing.T) {
+		b, _ := newTestBatch(map[string][]string{
+			describe: {`{"jobs": [{"jobId": "abc-123", "status": "RUNNABLE"}]}`},
+		})
+		if err := b.Logs(context.Background(), "abc-123", false, io.Discard); err == nil || !strings.Contains(err.Error(), "no logs yet") {
+			t.Errorf("Logs() = %v, want an error for a job without logs", err)
+		}
+	})
+}
+
+func TestBatchDescribeNotFound(t *testing.T) {
+	b, _ := newTestBatch(map[string][]string{
+		"batch describe-jobs --jobs missing": {`{"jobs": []}`},
+	})
+	if _, err := b.Describe(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Describe() = %v, want a not found error", err)
+	}
+}
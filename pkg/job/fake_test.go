@@ -0,0 +1,186 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is an in-process backend for tests that records submissions without
+// running anything. Tests drive job state with SetStatus and AppendLogs.
+type Fake struct {
+	mu    sync.Mutex
+	next  int
+	jobs  map[string]*Job
+	specs map[string]*Spec
+	logs  map[string][]string
+}
+
+// NewFake creates an empty fake backend
+func NewFake() *Fake {
+	return &Fake{
+		jobs:  make(map[string]*Job),
+		specs: make(map[string]*Spec),
+		logs:  make(map[string][]string),
+	}
+}
+
+// Name returns the backend identifier
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Submit records the job in the SUBMITTED state
+func (f *Fake) Submit(ctx context.Context, spec *Spec) (*Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.next++
+	j := &Job{
+		ID:        fmt.Sprintf("fake-%08d", f.next),
+		Name:      spec.Name,
+		Queue:     spec.Queue,
+		Status:    StatusSubmitted,
+		CreatedAt: time.Now().UTC(),
+	}
+	s := *spec
+	f.jobs[j.ID] = j
+	f.specs[j.ID] = &s
+
+	c := *j
+	return &c, nil
+}
+
+// Describe returns the recorded state of a job
+func (f *Fake) Describe(ctx context.Context, id string) (*Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, ok := f.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	c := *j
+	return &c, nil
+}
+
+// List returns recorded jobs, newest first
+func (f *Fake) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var jobs []*Job
+	for _, j := range f.jobs {
+		if opts.Queue != "" && j.Queue != opts.Queue {
+			continue
+		}
+		if opts.Status != "" && j.Status != opts.Status {
+			continue
+		}
+		c := *j
+		jobs = append(jobs, &c)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].ID > jobs[k].ID
+	})
+
+	if opts.Limit > 0 && len(jobs) > opts.Limit {
+		jobs = jobs[:opts.Limit]
+	}
+	return jobs, nil
+}
+
+// Cancel marks the job FAILED with the given reason
+func (f *Fake) Cancel(ctx context.Context, id string, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, ok := f.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	if j.Status.Done() {
+		return fmt.Errorf("job %s already %s", id, j.Status)
+	}
+	now := time.Now().UTC()
+	j.Status = StatusFailed
+	j.StatusReason = reason
+	j.StoppedAt = &now
+	return nil
+}
+
+// Logs writes the recorded log lines. Follow has no effect because fake
+// jobs never produce output on their own.
+func (f *Fake) Logs(ctx context.Context, id string, follow bool, w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.jobs[id]; !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	for _, line := range f.logs[id] {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Spec returns the spec a job was submitted with
+func (f *Fake) Spec(id string) (*Spec, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.specs[id]
+	return s, ok
+}
+
+// SetStatus moves a job to a new state, setting start and stop times and
+// the exit code as a real backend would
+func (f *Fake) SetStatus(id string, status Status, exitCode int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, ok := f.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	now := time.Now().UTC()
+	j.Status = status
+	if status == StatusRunning && j.StartedAt == nil {
+		j.StartedAt = &now
+	}
+	if status.Done() {
+		if j.StartedAt == nil {
+			j.StartedAt = &now
+		}
+		j.StoppedAt = &now
+		j.ExitCode = &exitCode
+	}
+	return nil
+}
+
+// AppendLogs adds log lines to a job
+func (f *Fake) AppendLogs(id string, lines ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.logs[id] = append(f.logs[id], lines...)
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package job provides job submission and monitoring across batch backends
+package job
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+// Job states, following the AWS Batch lifecycle
+const (
+	StatusSubmitted Status = "SUBMITTED"
+	StatusPending   Status = "PENDING"
+	StatusRunnable  Status = "RUNNABLE"
+	StatusStarting  Status = "STARTING"
+	StatusRunning   Status = "RUNNING"
+	StatusSucceeded Status = "SUCCEEDED"
+	StatusFailed    Status = "FAILED"
+)
+
+// Statuses lists every job state in lifecycle order
+var Statuses = []Status{
+	StatusSubmitted,
+	StatusPending,
+	StatusRunnable,
+	StatusStarting,
+	StatusRunning,
+	StatusSucceeded,
+	StatusFailed,
+}
+
+// ParseStatus converts a case-insensitive status name to a Status
+func ParseStatus(s string) (Status, error) {
+	for _, status := range Statuses {
+		if strings.EqualFold(s, string(status)) {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown job status %q", s)
+}
+
+// Done reports whether the job has reached a terminal state
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// Spec describes a job to submit
+type Spec struct {
+	Name          string            `json:"name"`
+	App           string            `json:"app"`
+	Variant       string            `json:"variant"`
+	Architecture  string            `json:"architecture"`
+	Environment   string            `json:"environment,omitempty"`
+	Queue         string            `json:"queue,omitempty"`
+	JobDefinition string            `json:"job_definition,omitempty"`
+	MultiNode     bool              `json:"multi_node,omitempty"` // a multi-node parallel job
+	Image         string            `json:"image,omitempty"`
+	Platform      string            `json:"platform,omitempty"`
+	VCPUs         int               `json:"vcpus"`
+	MemoryMB      int               `json:"memory_mb"`
+	Input         string            `json:"input"`
+	Output        string            `json:"output"`
+	ScratchGB     int               `json:"scratch_gb,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"` // walltime of each attempt
+	RetryAttempts  int               `json:"retry_attempts,omitempty"`
+	Parameters     map[string]string `json:"parameters,omitempty"`
+}
+
+// Command returns the container command for the job, matching the
+// arguments accepted by the application entrypoint script
+func (s *Spec) Command() []string {
+	return s.command(s.Input, s.Output)
+}
+
+// command returns the container command with the input and output paths
+// as the container sees them. Parameters are passed as --param name=value,
+// sorted by name.
+func (s *Spec) command(input, output string) []string {
+	args := []string{"--input", input, "--output", output}
+	names := make([]string, 0, len(s.Parameters))
+	for name := range s.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--param", name+"="+s.Parameters[name])
+	}
+	return args
+}
+
+// Job is the backend's view of a submitted job
+type Job struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Queue        string     `json:"queue,omitempty"`
+	Status       Status     `json:"status"`
+	StatusReason string     `json:"status_reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	StoppedAt    *time.Time `json:"stopped_at,omitempty"`
+	ExitCode     *int       `json:"exit_code,omitempty"`
+	LogStream    string     `json:"log_stream,omitempty"`
+}
+
+// Runtime returns how long the job has been running, or ran for if stopped
+func (j *Job) Runtime() time.Duration {
+	if j.StartedAt == nil {
+		return 0
+	}
+	if j.StoppedAt != nil {
+		return j.StoppedAt.Sub(*j.StartedAt)
+	}
+	return time.Since(*j.StartedAt)
+}
+
+// ListOptions filters the jobs returned by Backend.List
+type ListOptions struct {
+	Queue  string
+	Status Status // empty matches every status
+	Limit  int    // zero means no limit
+}
+
+// Backend is a batch scheduler that can run application jobs
+type Backend interface {
+	// Name returns the backend identifier (batch, local, ...)
+	Name() string
+
+	// Submit submits a job and returns its initial state
+	Submit(ctx context.Context, spec *Spec) (*Job, error)
+
+	// Describe returns the current state of a job
+	Describe(ctx context.Context, id string) (*Job, error)
+
+	// List returns jobs matching the options, newest first
+	List(ctx context.Context, opts ListOptions) ([]*Job, error)
+
+	// Cancel stops a job, whether queued or running
+	Cancel(ctx context.Context, id string, reason string) error
+
+	// Logs writes the job's log output to w. If follow is set it keeps
+	// streaming until the job finishes or ctx is canceled.
+	Logs(ctx context.Context, id string, follow bool, w io.Writer) error
+}
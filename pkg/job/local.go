@@ -0,0 +1,468 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/command"
+)
+
+// Container paths used by the application entrypoint scripts
+const (
+	containerInputDir   = "/data/input"
+	containerOutputDir  = "/data/output"
+	containerScratchDir = "/scratch"
+)
+
+// passthroughEnv lists host variables forwarded to local containers so
+// s3:// inputs and outputs work with the caller's credentials
+var passthroughEnv = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_REGION",
+	"AWS_DEFAULT_REGION",
+	"AWS_ENDPOINT_URL_S3",
+}
+
+// Local runs jobs as containers on this machine with Docker or Podman.
+// Each job gets a run directory under Root holding its state, scratch
+// space and, once finished, its captured log output.
+type Local struct {
+	Root   string
+	Engine string // docker or podman, detected from PATH when empty
+	Runner command.Runner
+}
+
+// NewLocal creates a local backend storing run directories under root
+func NewLocal(root, engine string) *Local {
+	return &Local{
+		Root:   root,
+		Engine: engine,
+		Runner: command.Exec{},
+	}
+}
+
+// Name returns the backend identifier
+func (l *Local) Name() string {
+	return "local"
+}
+
+// localRecord is the state persisted in each run directory
+type localRecord struct {
+	Spec      *Spec  `json:"spec"`
+	Job       *Job   `json:"job"`
+	Engine    string `json:"engine"`
+	Container string `json:"container"`
+}
+
+// Submit starts the job's container in the background. Local input and
+// output directories are mounted at the paths the entrypoint expects;
+// s3:// URIs are passed through unchanged. The spec's timeout and retry
+// attempts only apply on AWS Batch.
+func (l *Local) Submit(ctx context.Context, spec *Spec) (*Job, error) {
+	if spec.Image == "" {
+		return nil, fmt.Errorf("no container image for job %s", spec.Name)
+	}
+	engine, err := l.engine()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newLocalID()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(l.Root, id)
+	scratch := filepath.Join(dir, "scratch")
+	if err := os.MkdirAll(scratch, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+
+	rec := &localRecord{
+		Spec:      spec,
+		Engine:    engine,
+		Container: "aws-hpc-" + id,
+	}
+
+	args, err := l.runArgs(rec, scratch)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if _, err := command.Output(ctx, l.Runner, engine, args...); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	now := time.Now().UTC()
+	rec.Job = &Job{
+		ID:        id,
+		Name:      spec.Name,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		StartedAt: &now,
+	}
+	if err := l.save(rec); err != nil {
+		return nil, err
+	}
+
+	c := *rec.Job
+	return &c, nil
+}
+
+// runArgs builds the container engine arguments for a job
+func (l *Local) runArgs(rec *localRecord, scratch string) ([]string, error) {
+	spec := rec.Spec
+	args := []string{"run", "--detach",
+		"--name", rec.Container,
+		"--label", "org.hpc.job.id=" + strings.TrimPrefix(rec.Container, "aws-hpc-"),
+		"--volume", scratch + ":" + containerScratchDir,
+		"--env", "SCRATCH_DIR=" + containerScratchDir,
+	}
+	if spec.ScratchGB > 0 {
+		args = append(args, "--env", "SCRATCH_SIZE_GB="+strconv.Itoa(spec.ScratchGB))
+	}
+	if spec.Platform != "" {
+		args = append(args, "--platform", spec.Platform)
+	}
+	if spec.VCPUs > 0 {
+		args = append(args,
+			"--cpus", strconv.Itoa(spec.VCPUs),
+			"--env", "OMP_NUM_THREADS="+strconv.Itoa(spec.VCPUs))
+	}
+	if spec.MemoryMB > 0 {
+		args = append(args, "--memory", strconv.Itoa(spec.MemoryMB)+"m")
+	}
+
+	input, output := spec.Input, spec.Output
+	if !isS3(input) {
+		dir, err := filepath.Abs(input)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("input %s is not a directory", input)
+		}
+		args = append(args, "--volume", dir+":"+containerInputDir+":ro")
+		input = containerInputDir
+	}
+	if !isS3(output) {
+		dir, err := filepath.Abs(output)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+		args = append(args, "--volume", dir+":"+containerOutputDir)
+		output = containerOutputDir
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		awsDir := filepath.Join(home, ".aws")
+		if _, err := os.Stat(awsDir); err == nil {
+			args = append(args, "--volume", awsDir+":/root/.aws:ro")
+		}
+	}
+	for _, name := range passthroughEnv {
+		if _, ok := os.LookupEnv(name); ok {
+			args = append(args, "--env", name)
+		}
+	}
+
+	names := make([]string, 0, len(spec.Env))
+	for name := range spec.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--env", name+"="+spec.Env[name])
+	}
+
+	args = append(args, spec.Image)
+	args = append(args, spec.command(input, output)...)
+	return args, nil
+}
+
+// containerState is the subset of `docker inspect` State we use
+type containerState struct {
+	Status     string    `json:"Status"`
+	ExitCode   int       `json:"ExitCode"`
+	Error      string    `json:"Error"`
+	StartedAt  time.Time `json:"StartedAt"`
+	FinishedAt time.Time `json:"FinishedAt"`
+}
+
+// Describe returns the job state, refreshing it from the container engine
+// until the job finishes. Finished jobs have their exit code, runtime and
+// logs recorded in the run directory and their container removed.
+func (l *Local) Describe(ctx context.Context, id string) (*Job, error) {
+	rec, err := l.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.refresh(ctx, rec); err != nil {
+		return nil, err
+	}
+	c := *rec.Job
+	return &c, nil
+}
+
+func (l *Local) refresh(ctx context.Context, rec *localRecord) error {
+	if rec.Job.Status.Done() {
+		return nil
+	}
+
+	out, err := command.Output(ctx, l.Runner, rec.Engine, "inspect", "--format", "{{json .State}}", rec.Container)
+	if err != nil && !noSuchContainer(err) {
+		// The engine may be stopped or unreachable; the job may still run
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if err != nil {
+		now := time.Now().UTC()
+		rec.Job.Status = StatusFailed
+		rec.Job.StatusReason = "container no longer exists"
+		rec.Job.StoppedAt = &now
+		return l.save(rec)
+	}
+
+	var state containerState
+	if err := json.Unmarshal(out, &state); err != nil {
+		return fmt.Errorf("failed to parse container state: %w", err)
+	}
+
+	switch state.Status {
+	case "created":
+		rec.Job.Status = StatusStarting
+		return l.save(rec)
+	case "running", "paused", "restarting":
+		rec.Job.Status = StatusRunning
+		return l.save(rec)
+	}
+
+	// exited or dead
+	exitCode := state.ExitCode
+	started := state.StartedAt.UTC()
+	stopped := state.FinishedAt.UTC()
+	rec.Job.ExitCode = &exitCode
+	rec.Job.StartedAt = &started
+	rec.Job.StoppedAt = &stopped
+	rec.Job.Status = StatusSucceeded
+	if exitCode != 0 {
+		rec.Job.Status = StatusFailed
+		rec.Job.StatusReason = fmt.Sprintf("container exited with code %d", exitCode)
+		if state.Error != "" {
+			rec.Job.StatusReason = state.Error
+		}
+	}
+	return l.finish(ctx, rec)
+}
+
+// noSuchContainer reports whether an engine error says the container does
+// not exist, as when it was removed outside the platform. Docker and Podman
+// word it differently and by command.
+func noSuchContainer(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "no such container") || strings.Contains(msg, "no such object")
+}
+
+// finish captures the container's logs into the run directory, removes the
+// container and saves the final state
+func (l *Local) finish(ctx context.Context, rec *localRecord) error {
+	f, err := os.Create(l.logPath(rec.Job.ID))
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	err = l.Runner.Run(ctx, f, f, rec.Engine, "logs", rec.Container)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to capture logs: %w", err)
+	}
+
+	if _, err := command.Output(ctx, l.Runner, rec.Engine, "rm", "--force", rec.Container); err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return l.save(rec)
+}
+
+// List returns jobs recorded under Root, newest first
+func (l *Local) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	entries, err := os.ReadDir(l.Root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", l.Root, err)
+	}
+
+	var jobs []*Job
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		rec, err := l.load(e.Name())
+		if err != nil {
+			continue
+		}
+		if err := l.refresh(ctx, rec); err != nil {
+			return nil, err
+		}
+		if opts.Status != "" && rec.Job.Status != opts.Status {
+			continue
+		}
+		c := *rec.Job
+		jobs = append(jobs, &c)
+	}
+
+	sort.SliceStable(jobs, func(i, k int) bool {
+		return jobs[i].CreatedAt.After(jobs[k].CreatedAt)
+	})
+	if opts.Limit > 0 && len(jobs) > opts.Limit {
+		jobs = jobs[:opts.Limit]
+	}
+	return jobs, nil
+}
+
+// Cancel stops the job's container and records it as failed
+func (l *Local) Cancel(ctx context.Context, id string, reason string) error {
+	rec, err := l.load(id)
+	if err != nil {
+		return err
+	}
+	if rec.Job.Status.Done() {
+		return fmt.Errorf("job %s already %s", id, rec.Job.Status)
+	}
+
+	if _, err := command.Output(ctx, l.Runner, rec.Engine, "stop", rec.Container); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+
+	now := time.Now().UTC()
+	rec.Job.Status = StatusFailed
+	rec.Job.StatusReason = reason
+	rec.Job.StoppedAt = &now
+	return l.finish(ctx, rec)
+}
+
+// Logs streams the container's output, or replays the captured log file
+// once the job has finished
+func (l *Local) Logs(ctx context.Context, id string, follow bool, w io.Writer) error {
+	rec, err := l.load(id)
+	if err != nil {
+		return err
+	}
+	if err := l.refresh(ctx, rec); err != nil {
+		return err
+	}
+
+	if rec.Job.Status.Done() {
+		f, err := os.Open(l.logPath(id))
+		if err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "--follow")
+	}
+	args = append(args, rec.Container)
+	return l.Runner.Run(ctx, w, w, rec.Engine, args...)
+}
+
+// engine returns the configured container engine or the first of docker
+// and podman found on PATH
+func (l *Local) engine() (string, error) {
+	if l.Engine != "" {
+		return l.Engine, nil
+	}
+	for _, name := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("neither docker nor podman found in PATH")
+}
+
+func (l *Local) recordPath(id string) string {
+	return filepath.Join(l.Root, id, "job.json")
+}
+
+func (l *Local) logPath(id string) string {
+	return filepath.Join(l.Root, id, "output.log")
+}
+
+func (l *Local) load(id string) (*localRecord, error) {
+	data, err := os.ReadFile(l.recordPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+
+	var rec localRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", id, err)
+	}
+	if rec.Job == nil || rec.Spec == nil {
+		return nil, fmt.Errorf("job %s has an incomplete record", id)
+	}
+	return &rec, nil
+}
+
+func (l *Local) save(rec *localRecord) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", rec.Job.ID, err)
+	}
+	if err := os.WriteFile(l.recordPath(rec.Job.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write job %s: %w", rec.Job.ID, err)
+	}
+	return nil
+}
+
+// newLocalID returns a sortable, unique job ID
+func newLocalID() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return fmt.Sprintf("local-%s-%s", time.Now().UTC().Format("20060102-150405"), hex.EncodeToString(b)), nil
+}
+
+func isS3(uri string) bool {
+	return strings.HasPrefix(uri, "s3://")
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// engineRunner stands in for docker: inspect prints state or fails with
+// inspectErr, and logs prints a fixed line
+type engineRunner struct {
+	state      string
+	inspectErr error
+	calls      []string
+}
+
+func (r *engineRunner) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	r.calls = append(r.calls, name+" "+strings.Join(args, " "))
+	switch args[0] {
+	case "inspect":
+		if r.inspectErr != nil {
+			return r.inspectErr
+		}
+		_, err := io.WriteString(stdout, r.state)
+		return err
+	case "logs":
+		_, err := io.WriteString(stdout, "simulation complete\n")
+		return err
+	}
+	return nil
+}
+
+func (r *engineRunner) ran(subcommand string) bool {
+	for _, c := range r.calls {
+		if strings.HasPrefix(c, "docker "+subcommand+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// newRunningLocal returns a local backend with one RUNNING job recorded
+func newRunningLocal(t *testing.T, r *engineRunner) (*Local, string) {
+	t.Helper()
+	l := NewLocal(t.TempDir(), "docker")
+	l.Runner = r
+
+	id := "local-20251001-120000-abcdef"
+	if err := os.MkdirAll(filepath.Join(l.Root, id), 0755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	rec := &localRecord{
+		Spec:      &Spec{Name: "run"},
+		Job:       &Job{ID: id, Name: "run", Status: StatusRunning, CreatedAt: now, StartedAt: &now},
+		Engine:    "docker",
+		Container: "aws-hpc-" + id,
+	}
+	if err := l.save(rec); err != nil {
+		t.Fatal(err)
+	}
+	return l, id
+}
+
+func TestLocalRunArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range passthroughEnv {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	input := t.TempDir()
+	output := filepath.Join(t.TempDir(), "output")
+	rec := &localRecord{
+		Spec: &Spec{
+			Image:     "geos-chem:classic-c7a",
+			Platform:  "linux/amd64",
+			VCPUs:     4,
+			MemoryMB:  8192,
+			ScratchGB: 100,
+			Input:     input,
+			Output:    output,
+			Env:       map[string]string{"B": "2", "A": "1"},
+		},
+		Container: "aws-hpc-local-1",
+	}
+
+	args, err := (&Local{}).runArgs(rec, "/tmp/scratch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(args, " ")
+	want := "run --detach --name aws-hpc-local-1 --label org.hpc.job.id=local-1" +
+		" --volume /tmp/scratch:/scratch --env SCRATCH_DIR=/scratch --env SCRATCH_SIZE_GB=100" +
+		" --platform linux/amd64 --cpus 4 --env OMP_NUM_THREADS=4 --memory 8192m" +
+		" --volume " + input + ":/data/input:ro --volume " + output + ":/data/output" +
+		" --env AWS_REGION --env A=1 --env B=2" +
+		" geos-chem:classic-c7a --input /data/input --output /data/output"
+	if got != want {
+		t.Errorf("runArgs() =\n%s\nwant\n%s", got, want)
+	}
+	if info, err := os.Stat(output); err != nil || !info.IsDir() {
+		t.Error("output directory not created")
+	}
+
+	// s3:// locations are passed through without mounts
+	rec.Spec = &Spec{Image: "geos-chem:classic-c7a", Input: "s3://in/", Output: "s3://out/"}
+	args, err = (&Local{}).runArgs(rec, "/tmp/scratch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(args, " "); strings.Contains(got, "/data/") || !strings.HasSuffix(got, "--input s3://in/ --output s3://out/") {
+		t.Errorf("runArgs() with s3 = %s", got)
+	}
+
+	rec.Spec.Input = filepath.Join(input, "missing")
+	if _, err := (&Local{}).runArgs(rec, "/tmp/scratch"); err == nil {
+		t.Error("expected an error for an input that is not a directory")
+	}
+}
+
+func TestLocalRefresh(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      string
+		inspectErr error
+		want       Status
+		reason     string
+		finished   bool // logs captured and container removed
+		wantErr    bool
+	}{
+		{
+			name:  "created",
+			state: `{"Status": "created"}`,
+			want:  StatusStarting,
+		},
+		{
+			name:  "running",
+			state: `{"Status": "running"}`,
+			want:  StatusRunning,
+		},
+		{
+			name:     "exited",
+			state:    `{"Status": "exited", "ExitCode": 0, "StartedAt": "2025-10-01T12:00:00Z", "FinishedAt": "2025-10-01T13:00:00Z"}`,
+			want:     StatusSucceeded,
+			finished: true,
+		},
+		{
+			name:     "exited with an error",
+			state:    `{"Status": "exited", "ExitCode": 2, "StartedAt": "2025-10-01T12:00:00Z", "FinishedAt": "2025-10-01T13:00:00Z"}`,
+			want:     StatusFailed,
+			reason:   "container exited with code 2",
+			finished: true,
+		},
+		{
+			name:       "removed container",
+			inspectErr: errors.New("docker failed: exit status 1: Error: No such object: aws-hpc-local-20251001-120000-abcdef"),
+			want:       StatusFailed,
+			reason:     "container no longer exists",
+		},
+		{
+			name:       "engine unavailable",
+			inspectErr: errors.New("docker failed: exit status 1: Cannot connect to the Docker daemon at unix:///var/run/docker.sock"),
+			want:       StatusRunning,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &engineRunner{state: tt.state, inspectErr: tt.inspectErr}
+			l, id := newRunningLocal(t, r)
+
+			j, err := l.Describe(context.Background(), id)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if j.Status != tt.want || j.StatusReason != tt.reason {
+				t.Errorf("Describe() = %s %q, want %s %q", j.Status, j.StatusReason, tt.want, tt.reason)
+			}
+
+			// The recorded state is what Describe reported
+			rec, err := l.load(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Job.Status != tt.want {
+				t.Errorf("recorded status = %s, want %s", rec.Job.Status, tt.want)
+			}
+
+			if r.ran("rm") != tt.finished {
+				t.Errorf("container removed = %v, want %v", r.ran("rm"), tt.finished)
+			}
+			logs, err := os.ReadFile(l.logPath(id))
+			if tt.finished && (err != nil || string(logs) != "simulation complete\n") {
+				t.Errorf("captured logs = %q, %v", logs, err)
+			}
+			if tt.finished && (rec.Job.ExitCode == nil || rec.Job.Runtime() != time.Hour) {
+				t.Errorf("finished job = %+v", rec.Job)
+			}
+		})
+	}
+}
+
+func TestLocalCancel(t *testing.T) {
+	r := &engineRunner{state: `{"Status": "running"}`}
+	l, id := newRunningLocal(t, r)
+
+	if err := l.Cancel(context.Background(), id, "canceled by user"); err != nil {
+		t.Fatal(err)
+	}
+	if !r.ran("stop") || !r.ran("logs") || !r.ran("rm") {
+		t.Errorf("engine calls = %v, want stop, logs and rm", r.calls)
+	}
+	j, err := l.Describe(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != StatusFailed || j.StatusReason != "canceled by user" {
+		t.Errorf("canceled job = %+v", j)
+	}
+	if err := l.Cancel(context.Background(), id, "again"); err == nil {
+		t.Error("expected an error canceling a finished job")
+	}
+}
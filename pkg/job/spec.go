@@ -0,0 +1,239 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/config"
+	"github.com/aws-hpc/platform/pkg/container"
+	"github.com/aws-hpc/platform/pkg/pricing"
+)
+
+// JobDefinitionName returns the AWS Batch job definition name for an
+// application variant on an architecture
+func JobDefinitionName(app, variant, arch string) string {
+	return fmt.Sprintf("%s-%s-%s", app, variant, arch)
+}
+
+// DeployedName returns the name an environment's deployment gives an
+// app.yaml resource such as a job queue or job definition, so that the
+// environments of an application can share an account and region
+func DeployedName(name, env string) string {
+	if name == "" || env == "" {
+		return name
+	}
+	return name + "-" + env
+}
+
+// Job resources used when neither the environment nor the command line
+// sets them, unless an architecture's smallest instance type is smaller
+const (
+	DefaultVCPUs    = 8
+	DefaultMemoryMB = 16384
+)
+
+// HostReservedMemoryMB is the memory the ECS agent and operating system
+// keep on each AWS Batch host, which jobs cannot use
+const HostReservedMemoryMB = 512
+
+// DefaultResources returns the default vCPUs and memory of jobs on an
+// architecture: DefaultVCPUs and DefaultMemoryMB, capped so that every
+// instance type of the architecture can run the job. Instance types the
+// bundled pricing catalog does not describe do not cap them.
+func DefaultResources(arch *config.Architecture) (vcpus, memoryMB int) {
+	vcpus, memoryMB = DefaultVCPUs, DefaultMemoryMB
+	catalog, err := pricing.Bundled()
+	if err != nil {
+		return vcpus, memoryMB
+	}
+	for _, t := range arch.InstanceTypes {
+		i, ok := catalog.Instance(t)
+		if !ok || i.VCPUs == 0 {
+			continue
+		}
+		vcpus = min(vcpus, i.VCPUs)
+		memoryMB = min(memoryMB, int(i.MemoryGiB*1024)-HostReservedMemoryMB)
+	}
+	return vcpus, memoryMB
+}
+
+// Options are job settings from an environment file or the command line.
+// Zero values are unset.
+type Options struct {
+	Variant       string
+	Architecture  string
+	Queue         string
+	VCPUs         int
+	MemoryMB      int
+	Input         string
+	Output        string
+	Timeout       time.Duration
+	RetryAttempts int
+	Parameters    map[string]string
+}
+
+// EnvironmentOptions returns the job settings of an environment file
+func EnvironmentOptions(env *config.EnvironmentConfig) Options {
+	return Options{
+		Variant:       env.Variant,
+		Architecture:  env.Compute.Architecture,
+		Queue:         env.Compute.Queue,
+		VCPUs:         env.Compute.VCPUs,
+		MemoryMB:      env.Compute.MemoryMB,
+		Input:         env.Storage.Input,
+		Output:        env.Storage.Output,
+		Timeout:       time.Duration(env.Runtime.TimeoutHours * float64(time.Hour)),
+		RetryAttempts: env.Runtime.RetryAttempts,
+		Parameters:    env.Parameters,
+	}
+}
+
+// Merge returns o with every set field of override replacing its own.
+// Parameters are merged by name.
+func (o Options) Merge(override Options) Options {
+	set := func(s *string, v string) {
+		if v != "" {
+			*s = v
+		}
+	}
+	setInt := func(n *int, v int) {
+		if v != 0 {
+			*n = v
+		}
+	}
+	set(&o.Variant, override.Variant)
+	set(&o.Architecture, override.Architecture)
+	set(&o.Queue, override.Queue)
+	set(&o.Input, override.Input)
+	set(&o.Output, override.Output)
+	setInt(&o.VCPUs, override.VCPUs)
+	setInt(&o.MemoryMB, override.MemoryMB)
+	setInt(&o.RetryAttempts, override.RetryAttempts)
+	if override.Timeout != 0 {
+		o.Timeout = override.Timeout
+	}
+
+	if len(override.Parameters) > 0 {
+		params := make(map[string]string, len(o.Parameters)+len(override.Parameters))
+		for name, value := range o.Parameters {
+			params[name] = value
+		}
+		for name, value := range override.Parameters {
+			params[name] = value
+		}
+		o.Parameters = params
+	}
+	return o
+}
+
+// Resolve resolves a job spec for an application with settings layered in
+// increasing precedence: the application defaults of NewSpec, the
+// environment's settings if env is not nil, then opts. With an environment
+// the queue and job definition are the ones its deployment created.
+func Resolve(app *config.Application, env *config.Environment, opts Options) (*Spec, error) {
+	if env != nil && env.Settings != nil {
+		opts = EnvironmentOptions(env.Settings).Merge(opts)
+	}
+
+	spec, err := NewSpec(app, opts.Variant, opts.Architecture)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Queue != "" {
+		q := app.GetQueue(opts.Queue)
+		if q == nil {
+			return nil, fmt.Errorf("queue %s not found", opts.Queue)
+		}
+		if !q.Runs(spec.Architecture) {
+			return nil, fmt.Errorf("queue %s does not run architecture %s", opts.Queue, spec.Architecture)
+		}
+		spec.Queue = opts.Queue
+	}
+	if env != nil {
+		spec.Environment = env.Name
+		spec.Queue = DeployedName(spec.Queue, env.Name)
+		spec.JobDefinition = DeployedName(spec.JobDefinition, env.Name)
+	}
+	if opts.VCPUs > 0 {
+		spec.VCPUs = opts.VCPUs
+	}
+	if opts.MemoryMB > 0 {
+		spec.MemoryMB = opts.MemoryMB
+	}
+	spec.Input = opts.Input
+	spec.Output = opts.Output
+	spec.TimeoutSeconds = int(opts.Timeout / time.Second)
+	spec.RetryAttempts = opts.RetryAttempts
+	spec.Parameters = opts.Parameters
+	return spec, nil
+}
+
+// NewSpec resolves a job spec for an application. An empty variant selects
+// the first declared variant and an empty arch selects the first declared
+// architecture. The queue is the highest priority queue with a compute
+// environment that runs the architecture, or empty if no queue does.
+func NewSpec(app *config.Application, variant, arch string) (*Spec, error) {
+	if variant == "" {
+		variant = app.Variants[0].Name
+	}
+	v, err := app.GetVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+
+	if arch == "" {
+		arch = app.Compute.Architectures[0].Name
+	}
+	a, err := app.GetArchitecture(arch)
+	if err != nil {
+		return nil, err
+	}
+
+	vcpus, memoryMB := DefaultResources(a)
+	return &Spec{
+		Name:          fmt.Sprintf("%s-%s-%s", app.Name, variant, time.Now().UTC().Format("20060102-150405")),
+		App:           app.Name,
+		Variant:       variant,
+		Architecture:  arch,
+		Queue:         queueFor(app, arch),
+		JobDefinition: JobDefinitionName(app.Name, variant, arch),
+		MultiNode:     v.Type == "multi-node",
+		Image:         container.ImageRef(app, "", variant, arch),
+		Platform:      container.Platform(a.Family),
+		VCPUs:         vcpus,
+		MemoryMB:      memoryMB,
+		ScratchGB:     app.Storage.Scratch.SizeGB,
+	}, nil
+}
+
+// queueFor returns the highest priority queue (lowest priority number)
+// that can run the architecture
+func queueFor(app *config.Application, arch string) string {
+	queues := make([]config.Queue, len(app.Compute.Batch.Queues))
+	copy(queues, app.Compute.Batch.Queues)
+	sort.SliceStable(queues, func(i, j int) bool {
+		return queues[i].Priority < queues[j].Priority
+	})
+
+	for _, q := range queues {
+		if q.Runs(arch) {
+			return q.Name
+		}
+	}
+	return ""
+}
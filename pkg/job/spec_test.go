@@ -0,0 +1,127 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+func TestResolve(t *testing.T) {
+	app, err := config.LoadApplication(filepath.Join("..", "..", "applications", "geos-chem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	benchmark, err := app.GetEnvironment("benchmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  *config.Environment
+		opts Options
+		want string // variant arch queue vcpus memory input timeout command
+	}{
+		{
+			name: "app defaults",
+			opts: Options{Input: "in", Output: "out"},
+			want: "classic c7a geos-chem-spot-x86 4 7680 in 0 --input in --output out",
+		},
+		{
+			name: "environment",
+			env:  benchmark,
+			want: "classic c7a geos-chem-spot-x86-benchmark 16 32768 s3://geos-chem-input-data/GEOS_4x5/ 21600 " +
+				"--input s3://geos-chem-input-data/GEOS_4x5/ --output s3://geos-chem-results-benchmark/ " +
+				"--param end_date=20190708 --param resolution=4x5 --param simulation=fullchem --param start_date=20190701",
+		},
+		{
+			name: "flags override what they name",
+			env:  benchmark,
+			opts: Options{Architecture: "graviton4", VCPUs: 32, Timeout: time.Hour, Parameters: map[string]string{"end_date": "20190702"}},
+			want: "classic graviton4 geos-chem-spot-graviton-benchmark 32 32768 s3://geos-chem-input-data/GEOS_4x5/ 3600 " +
+				"--input s3://geos-chem-input-data/GEOS_4x5/ --output s3://geos-chem-results-benchmark/ " +
+				"--param end_date=20190702 --param resolution=4x5 --param simulation=fullchem --param start_date=20190701",
+		},
+		{
+			name: "queue preference",
+			opts: Options{Queue: "geos-chem-ondemand", Input: "in", Output: "out"},
+			want: "classic c7a geos-chem-ondemand 4 7680 in 0 --input in --output out",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := Resolve(app, tt.env, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.Join(append([]string{spec.Variant, spec.Architecture, spec.Queue,
+				strconv.Itoa(spec.VCPUs), strconv.Itoa(spec.MemoryMB), spec.Input, strconv.Itoa(spec.TimeoutSeconds)}, spec.Command()...), " ")
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	// An environment's jobs run on the resources its deployment created
+	spec, err := Resolve(app, benchmark, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.JobDefinition != "geos-chem-classic-c7a-benchmark" {
+		t.Errorf("job definition = %s, want geos-chem-classic-c7a-benchmark", spec.JobDefinition)
+	}
+
+	// Multi-node variants are submitted as multi-node parallel jobs
+	if spec.MultiNode {
+		t.Error("classic resolved as a multi-node job")
+	}
+	if gchp, err := Resolve(app, nil, Options{Variant: "gchp"}); err != nil || !gchp.MultiNode {
+		t.Errorf("Resolve(gchp) = %+v, %v, want a multi-node job", gchp, err)
+	}
+
+	// The environment's parameters are not changed by overrides
+	if benchmark.Settings.Parameters["end_date"] != "20190708" {
+		t.Error("Merge modified the environment's parameters")
+	}
+	if _, err := Resolve(app, nil, Options{Queue: "geos-chem-ondemand", Architecture: "graviton4"}); err == nil {
+		t.Error("expected an error for a queue that does not run the architecture")
+	}
+}
+
+func TestDefaultResources(t *testing.T) {
+	tests := []struct {
+		name          string
+		instanceTypes []string
+		vcpus, memory int
+	}{
+		{"capped by the smallest instance", []string{"c7a.xlarge", "c7a.8xlarge"}, 4, 8*1024 - HostReservedMemoryMB},
+		{"large instances", []string{"c7a.4xlarge", "c7a.8xlarge"}, DefaultVCPUs, DefaultMemoryMB},
+		{"unknown instance type", []string{"x9z.large"}, DefaultVCPUs, DefaultMemoryMB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcpus, memory := DefaultResources(&config.Architecture{InstanceTypes: tt.instanceTypes})
+			if vcpus != tt.vcpus || memory != tt.memory {
+				t.Errorf("DefaultResources() = %d, %d, want %d, %d", vcpus, memory, tt.vcpus, tt.memory)
+			}
+		})
+	}
+}
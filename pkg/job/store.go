@@ -0,0 +1,181 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// jobsBucket holds one Record per job, keyed by backend job ID
+var jobsBucket = []byte("jobs")
+
+// Record is the history entry for a submitted job: what was asked for and
+// the last known state reported by the backend
+type Record struct {
+	Backend string `json:"backend"`
+	Region  string `json:"region,omitempty"`
+	Spec    Spec   `json:"spec"`
+	Job     Job    `json:"job"`
+}
+
+// Filter selects records from the store
+type Filter struct {
+	Backend string
+	App     string
+	Status  Status    // empty matches every status
+	Since   time.Time // zero matches every submission time
+	Limit   int       // zero means no limit
+}
+
+func (f Filter) match(r *Record) bool {
+	if f.Backend != "" && r.Backend != f.Backend {
+		return false
+	}
+	if f.App != "" && r.Spec.App != f.App {
+		return false
+	}
+	if f.Status != "" && r.Job.Status != f.Status {
+		return false
+	}
+	if !f.Since.IsZero() && r.Job.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// Store is the local job database, an embedded bbolt file. The file is
+// opened for each operation, so its lock is not held while a command waits
+// on a backend, such as when following logs.
+type Store struct {
+	path string
+}
+
+// DefaultStorePath returns the job database location under dir, normally
+// the platform home directory from config.HomeDir
+func DefaultStorePath(dir string) string {
+	return filepath.Join(dir, "jobs.db")
+}
+
+// OpenStore creates the job database at path if it does not exist
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	s := &Store{path: path}
+	err := s.update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize job database: %w", err)
+	}
+	return s, nil
+}
+
+// update runs fn in a read-write transaction, holding the database's
+// exclusive lock only while it runs
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// view runs fn in a read-only transaction
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (s *Store) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job database %s: %w", s.path, err)
+	}
+	return db, nil
+}
+
+// Put inserts or replaces the record for r.Job.ID
+func (s *Store) Put(r *Record) error {
+	if r.Job.ID == "" {
+		return fmt.Errorf("job record has no ID")
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", r.Job.ID, err)
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(r.Job.ID), data)
+	})
+}
+
+// Get returns the record for a job, or nil if the job is not recorded
+func (s *Store) Get(id string) (*Record, error) {
+	var r *Record
+	err := s.view(func(tx *bolt.Tx) error {
+		data := tx.Bucket(jobsBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		r = &Record{}
+		return json.Unmarshal(data, r)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// List returns records matching the filter, newest submission first
+func (s *Store) List(f Filter) ([]*Record, error) {
+	var records []*Record
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("failed to decode job %s: %w", k, err)
+			}
+			if f.match(&r) {
+				records = append(records, &r)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, k int) bool {
+		return records[i].Job.CreatedAt.After(records[k].Job.CreatedAt)
+	})
+	if f.Limit > 0 && len(records) > f.Limit {
+		records = records[:f.Limit]
+	}
+	return records, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func record(id, backend, app string, status Status, created time.Time) *Record {
+	return &Record{
+		Backend: backend,
+		Spec:    Spec{App: app},
+		Job:     Job{ID: id, Status: status, CreatedAt: created},
+	}
+}
+
+func TestStore(t *testing.T) {
+	s, err := OpenStore(DefaultStorePath(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	for _, r := range []*Record{
+		record("a", "batch", "geos-chem", StatusSucceeded, day),
+		record("b", "batch", "gaussian", StatusRunning, day.Add(time.Hour)),
+		record("c", "local", "geos-chem", StatusRunning, day.Add(2*time.Hour)),
+		record("d", "batch", "geos-chem", StatusRunning, day.Add(3*time.Hour)),
+	} {
+		if err := s.Put(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Put(&Record{}); err == nil {
+		t.Error("expected an error for a record without a job ID")
+	}
+
+	r, err := s.Get("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.Spec.App != "gaussian" || r.Job.Status != StatusRunning {
+		t.Fatalf("Get(b) = %+v", r)
+	}
+	if r, err := s.Get("missing"); err != nil || r != nil {
+		t.Errorf("Get(missing) = %+v, %v, want nil", r, err)
+	}
+
+	// Put replaces the record
+	r.Job.Status = StatusSucceeded
+	if err := s.Put(r); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := s.Get("b"); r.Job.Status != StatusSucceeded {
+		t.Errorf("updated status = %s", r.Job.Status)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{"all, newest first", Filter{}, "dcba"},
+		{"backend", Filter{Backend: "batch"}, "dba"},
+		{"app", Filter{App: "geos-chem"}, "dca"},
+		{"status", Filter{Status: StatusSucceeded}, "ba"},
+		{"since", Filter{Since: day.Add(2 * time.Hour)}, "dc"},
+		{"limit", Filter{Backend: "batch", Limit: 2}, "db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := s.List(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			for _, r := range records {
+				got += r.Job.ID
+			}
+			if got != tt.want {
+				t.Errorf("List() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreShared(t *testing.T) {
+	path := DefaultStorePath(t.TempDir())
+	first, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second command can use the database alongside the first,
+	// as when one follows logs and another submits
+	done := make(chan error, 1)
+	go func() {
+		second, err := OpenStore(path)
+		if err == nil {
+			err = second.Put(record("a", "batch", "geos-chem", StatusSubmitted, time.Now()))
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second store blocked on the first")
+	}
+
+	if r, err := first.Get("a"); err != nil || r == nil {
+		t.Errorf("Get(a) = %+v, %v, want the second store's record", r, err)
+	}
+}
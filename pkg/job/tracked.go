@@ -0,0 +1,161 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"io"
+)
+
+// Tracked wraps a backend and records every submission and state change in
+// the local job store, so history survives after the backend forgets a job
+type Tracked struct {
+	Backend
+	Store  *Store
+	Region string
+
+	// Open creates the backend of jobs recorded by another backend or in
+	// another region, so History can refresh them. If nil, those jobs keep
+	// their recorded state.
+	Open func(name, region string) (Backend, error)
+}
+
+// NewTracked records jobs submitted through backend in store
+func NewTracked(backend Backend, store *Store, region string) *Tracked {
+	return &Tracked{Backend: backend, Store: store, Region: region}
+}
+
+// Submit submits the job and records it
+func (t *Tracked) Submit(ctx context.Context, spec *Spec) (*Job, error) {
+	j, err := t.Backend.Submit(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	r := &Record{
+		Backend: t.Backend.Name(),
+		Region:  t.Region,
+		Spec:    *spec,
+		Job:     *j,
+	}
+	if err := t.Store.Put(r); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Describe asks the backend for the job's state and records it. Finished
+// jobs are answered from the store without contacting the backend.
+func (t *Tracked) Describe(ctx context.Context, id string) (*Job, error) {
+	r, err := t.Store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil && r.Job.Status.Done() {
+		j := r.Job
+		return &j, nil
+	}
+
+	j, err := t.Backend.Describe(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		if err := t.update(r, j); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// List returns recorded jobs for this backend. Naming a queue queries the
+// backend directly instead, which also finds jobs submitted elsewhere.
+func (t *Tracked) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	if opts.Queue != "" {
+		return t.Backend.List(ctx, opts)
+	}
+
+	records, err := t.History(ctx, Filter{
+		Backend: t.Backend.Name(),
+		Status:  opts.Status,
+		Limit:   opts.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, len(records))
+	for i, r := range records {
+		j := r.Job
+		jobs[i] = &j
+	}
+	return jobs, nil
+}
+
+// History returns recorded jobs matching the filter. Jobs that had not
+// finished when last seen are refreshed first from the backend and region
+// they were submitted to, so status filters see current states.
+func (t *Tracked) History(ctx context.Context, f Filter) ([]*Record, error) {
+	pending, err := t.Store.List(Filter{Backend: f.Backend, App: f.App, Since: f.Since})
+	if err != nil {
+		return nil, err
+	}
+	backends := map[[2]string]Backend{{t.Backend.Name(), t.Region}: t.Backend}
+	for _, r := range pending {
+		if r.Job.Status.Done() {
+			continue
+		}
+		key := [2]string{r.Backend, r.Region}
+		backend, ok := backends[key]
+		if !ok && t.Open != nil {
+			// A backend that cannot be created leaves its jobs as recorded
+			backend, _ = t.Open(r.Backend, r.Region)
+			backends[key] = backend
+		}
+		if backend == nil {
+			continue
+		}
+		// Keep the recorded state if the backend no longer knows the job
+		if j, err := backend.Describe(ctx, r.Job.ID); err == nil {
+			if err := t.update(r, j); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t.Store.List(f)
+}
+
+// Cancel cancels the job and records its new state
+func (t *Tracked) Cancel(ctx context.Context, id string, reason string) error {
+	if err := t.Backend.Cancel(ctx, id, reason); err != nil {
+		return err
+	}
+	_, err := t.Describe(ctx, id)
+	return err
+}
+
+// Logs streams the job's logs from the backend
+func (t *Tracked) Logs(ctx context.Context, id string, follow bool, w io.Writer) error {
+	return t.Backend.Logs(ctx, id, follow, w)
+}
+
+// update stores the backend's latest view of a recorded job. Backends do
+// not all report the queue, so a recorded one is kept.
+func (t *Tracked) update(r *Record, j *Job) error {
+	queue := r.Job.Queue
+	r.Job = *j
+	if r.Job.Queue == "" {
+		r.Job.Queue = queue
+	}
+	return t.Store.Put(r)
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func newTestTracked(t *testing.T) (*Tracked, *Fake) {
+	t.Helper()
+	store, err := OpenStore(DefaultStorePath(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := NewFake()
+	tracked := NewTracked(fake, store, "us-east-1")
+	return tracked, fake
+}
+
+func TestTrackedSubmit(t *testing.T) {
+	tracked, fake := newTestTracked(t)
+	ctx := context.Background()
+
+	j, err := tracked.Submit(ctx, &Spec{Name: "run", App: "geos-chem", Queue: "geos-chem-spot-x86-production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tracked.Store.Get(j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.Backend != "fake" || r.Region != "us-east-1" || r.Spec.App != "geos-chem" || r.Job.Status != StatusSubmitted {
+		t.Fatalf("record = %+v", r)
+	}
+
+	// Describe records the backend's state until the job finishes
+	if err := fake.SetStatus(j.ID, StatusRunning, 0); err != nil {
+		t.Fatal(err)
+	}
+	if j, err := tracked.Describe(ctx, j.ID); err != nil || j.Status != StatusRunning {
+		t.Fatalf("Describe() = %+v, %v", j, err)
+	}
+	if r, _ := tracked.Store.Get(j.ID); r.Job.Status != StatusRunning || r.Job.Queue != "geos-chem-spot-x86-production" {
+		t.Errorf("record after Describe = %+v", r.Job)
+	}
+
+	if err := fake.SetStatus(j.ID, StatusSucceeded, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracked.Describe(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Finished jobs are answered from the store
+	if err := fake.SetStatus(j.ID, StatusFailed, 1); err != nil {
+		t.Fatal(err)
+	}
+	if j, err := tracked.Describe(ctx, j.ID); err != nil || j.Status != StatusSucceeded {
+		t.Errorf("Describe() of a finished job = %+v, %v, want the recorded SUCCEEDED", j, err)
+	}
+}
+
+func TestTrackedHistory(t *testing.T) {
+	tracked, fake := newTestTracked(t)
+	ctx := context.Background()
+
+	var ids []string
+	for _, app := range []string{"geos-chem", "geos-chem", "gaussian"} {
+		j, err := tracked.Submit(ctx, &Spec{Name: app, App: app})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, j.ID)
+	}
+
+	// Jobs the backend has moved on are refreshed before filtering
+	if err := fake.SetStatus(ids[0], StatusSucceeded, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetStatus(ids[2], StatusRunning, 0); err != nil {
+		t.Fatal(err)
+	}
+	records, err := tracked.History(ctx, Filter{Status: StatusSucceeded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Job.ID != ids[0] {
+		t.Fatalf("History(SUCCEEDED) = %v", records)
+	}
+	if r, _ := tracked.Store.Get(ids[2]); r.Job.Status != StatusRunning {
+		t.Errorf("pending job not refreshed: %s", r.Job.Status)
+	}
+
+	records, err = tracked.History(ctx, Filter{App: "geos-chem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("History(geos-chem) = %d records, want 2", len(records))
+	}
+
+	// A job the backend no longer knows keeps its recorded state
+	lost := record("lost", "fake", "geos-chem", StatusRunning, records[0].Job.CreatedAt)
+	if err := tracked.Store.Put(lost); err != nil {
+		t.Fatal(err)
+	}
+	records, err = tracked.History(ctx, Filter{Status: StatusRunning})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("History(RUNNING) = %d records, want the refreshed and the lost job", len(records))
+	}
+
+	jobs, err := tracked.List(ctx, ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Errorf("List() = %d jobs, want 2", len(jobs))
+	}
+}
+
+func TestTrackedHistoryOtherBackend(t *testing.T) {
+	tracked, _ := newTestTracked(t)
+	ctx := context.Background()
+
+	// A job submitted in another region is refreshed from that region
+	west := NewFake()
+	j, err := west.Submit(ctx, &Spec{Name: "run", App: "geos-chem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracked.Store.Put(&Record{Backend: "fake", Region: "us-west-2", Job: *j}); err != nil {
+		t.Fatal(err)
+	}
+	if err := west.SetStatus(j.ID, StatusSucceeded, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without Open it keeps its recorded state
+	records, err := tracked.History(ctx, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Job.Status != StatusSubmitted {
+		t.Fatalf("History() without Open = %v", records)
+	}
+
+	var opened []string
+	tracked.Open = func(name, region string) (Backend, error) {
+		opened = append(opened, name+" "+region)
+		return west, nil
+	}
+	records, err = tracked.History(ctx, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Job.Status != StatusSucceeded {
+		t.Errorf("History() = %v, want the job refreshed from us-west-2", records)
+	}
+	if len(opened) != 1 || opened[0] != "fake us-west-2" {
+		t.Errorf("opened %q, want the fake backend in us-west-2", opened)
+	}
+}
+
+func TestTrackedCancelAndLogs(t *testing.T) {
+	tracked, fake := newTestTracked(t)
+	ctx := context.Background()
+
+	j, err := tracked.Submit(ctx, &Spec{Name: "run", App: "geos-chem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.AppendLogs(j.ID, "one", "two")
+	var out bytes.Buffer
+	if err := tracked.Logs(ctx, j.ID, false, &out); err != nil || out.String() != "one\ntwo\n" {
+		t.Errorf("Logs() = %q, %v", out.String(), err)
+	}
+
+	if err := tracked.Cancel(ctx, j.ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := tracked.Store.Get(j.ID); r.Job.Status != StatusFailed || r.Job.StatusReason != "test" {
+		t.Errorf("record after Cancel = %+v", r.Job)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pricing holds an offline catalog of EC2 instance types and their
+// on-demand and spot prices by region
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+// DefaultRegion is the region prices are looked up in when none is set
+const DefaultRegion = "us-east-1"
+
+//go:embed catalog.json
+var bundled []byte
+
+// Catalog is a snapshot of instance type metadata and Linux prices, in USD
+// per hour
+type Catalog struct {
+	Updated   time.Time                   `json:"updated"`
+	Source    string                      `json:"source"`
+	Instances map[string]Instance         `json:"instance_types"`
+	Regions   map[string]map[string]Price `json:"regions"` // region -> instance type -> price
+}
+
+// Instance describes an instance type
+type Instance struct {
+	VCPUs        int     `json:"vcpus"`
+	MemoryGiB    float64 `json:"memory_gib"`
+	Architecture string  `json:"architecture"` // x86_64, arm64
+}
+
+// Price is the hourly price of an instance type in a region. Spot is zero
+// when it is not known.
+type Price struct {
+	OnDemand float64 `json:"on_demand"`
+	Spot     float64 `json:"spot,omitempty"`
+}
+
+// Region returns the region set by AWS_REGION or AWS_DEFAULT_REGION, or
+// DefaultRegion
+func Region() string {
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return region
+		}
+	}
+	return DefaultRegion
+}
+
+// CachePath returns where pricing update writes the catalog
+func CachePath() (string, error) {
+	home, err := config.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "pricing.json"), nil
+}
+
+// Load returns the cached catalog if pricing update has written one, and
+// otherwise the catalog bundled with the platform
+func Load() (*Catalog, error) {
+	path, err := CachePath()
+	if err != nil {
+		return Bundled()
+	}
+	c, err := LoadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Bundled()
+	}
+	return c, err
+}
+
+// Bundled returns the catalog bundled with the platform
+func Bundled() (*Catalog, error) {
+	return parse(bundled, "bundled catalog")
+}
+
+// LoadFile loads a catalog from a JSON file
+func LoadFile(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing catalog: %w", err)
+	}
+	return parse(data, path)
+}
+
+func parse(data []byte, name string) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if c.Instances == nil {
+		c.Instances = map[string]Instance{}
+	}
+	if c.Regions == nil {
+		c.Regions = map[string]map[string]Price{}
+	}
+	return &c, nil
+}
+
+// Save writes the catalog as JSON, creating its directory
+func (c *Catalog) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pricing catalog: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write pricing catalog: %w", err)
+	}
+	return nil
+}
+
+// Instance returns the metadata of an instance type
+func (c *Catalog) Instance(instanceType string) (Instance, bool) {
+	i, ok := c.Instances[instanceType]
+	return i, ok
+}
+
+// Price returns the price of an instance type in a region
+func (c *Catalog) Price(region, instanceType string) (Price, error) {
+	prices, ok := c.Regions[region]
+	if !ok {
+		return Price{}, fmt.Errorf("no prices for region %s; run aws-hpc cost pricing update --region %s", region, region)
+	}
+	p, ok := prices[instanceType]
+	if !ok || p.OnDemand == 0 {
+		return Price{}, fmt.Errorf("no price for %s in %s", instanceType, region)
+	}
+	return p, nil
+}
+
+// InstanceTypes returns the instance types in the catalog, sorted
+func (c *Catalog) InstanceTypes() []string {
+	types := make([]string, 0, len(c.Instances))
+	for t := range c.Instances {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// RegionNames returns the regions in the catalog, sorted
+func (c *Catalog) RegionNames() []string {
+	regions := make([]string, 0, len(c.Regions))
+	for r := range c.Regions {
+		regions = append(regions, r)
+	}
+	sort.Strings(regions)
+	return regions
+}
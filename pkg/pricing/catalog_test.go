@@ -0,0 +1,116 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/config"
+)
+
+func TestBundledCoversApplications(t *testing.T) {
+	c, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := (&config.Registry{Paths: []string{"../../applications"}}).Discover()
+	if len(entries) == 0 {
+		t.Fatal("no applications found")
+	}
+	for _, entry := range entries {
+		if entry.App == nil {
+			t.Fatalf("%s: %v", entry.Name, entry.Err)
+		}
+		for _, arch := range entry.App.Compute.Architectures {
+			for _, instanceType := range arch.InstanceTypes {
+				if i, ok := c.Instance(instanceType); !ok || i.VCPUs == 0 || i.MemoryGiB == 0 {
+					t.Errorf("%s: no metadata for %s", entry.Name, instanceType)
+				}
+				for _, region := range c.RegionNames() {
+					p, err := c.Price(region, instanceType)
+					if err != nil {
+						t.Errorf("%s: %v", entry.Name, err)
+					} else if p.Spot <= 0 || p.Spot >= p.OnDemand {
+						t.Errorf("%s: %s in %s: spot price %v is not below on-demand %v", entry.Name, instanceType, region, p.Spot, p.OnDemand)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPrice(t *testing.T) {
+	c := &Catalog{Regions: map[string]map[string]Price{
+		"us-east-1": {"c7a.2xlarge": {OnDemand: 0.41056, Spot: 0.18}},
+	}}
+	if p, err := c.Price("us-east-1", "c7a.2xlarge"); err != nil || p.OnDemand != 0.41056 || p.Spot != 0.18 {
+		t.Errorf("Price() = %+v, %v", p, err)
+	}
+	if _, err := c.Price("us-east-1", "c8g.2xlarge"); err == nil {
+		t.Error("expected an error for an instance type without a price")
+	}
+	if _, err := c.Price("eu-west-1", "c7a.2xlarge"); err == nil {
+		t.Error("expected an error for a region without prices")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("AWS_HPC_HOME", t.TempDir())
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Source != "AWS Price List snapshot bundled with the platform" {
+		t.Errorf("Load() without a cached catalog = %s, want the bundled one", c.Source)
+	}
+
+	cached := &Catalog{
+		Updated:   time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+		Source:    "test",
+		Instances: map[string]Instance{"c7a.2xlarge": {VCPUs: 8, MemoryGiB: 16, Architecture: "x86_64"}},
+		Regions:   map[string]map[string]Price{"us-east-1": {"c7a.2xlarge": {OnDemand: 0.5}}},
+	}
+	path, err := CachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "pricing.json" {
+		t.Errorf("CachePath() = %s", path)
+	}
+	if err := cached.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	c, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Source != "test" || !c.Updated.Equal(cached.Updated) || c.Instances["c7a.2xlarge"].VCPUs != 8 || c.Regions["us-east-1"]["c7a.2xlarge"].OnDemand != 0.5 {
+		t.Errorf("Load() = %+v, want the cached catalog", c)
+	}
+}
+
+func TestRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	if got := Region(); got != DefaultRegion {
+		t.Errorf("Region() = %s, want %s", got, DefaultRegion)
+	}
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	if got := Region(); got != "eu-west-1" {
+		t.Errorf("Region() = %s, want eu-west-1", got)
+	}
+}
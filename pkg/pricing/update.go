@@ -0,0 +1,317 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws-hpc/platform/pkg/command"
+)
+
+// OfferURL returns the URL of a region's AWS Price List bulk file for EC2
+func OfferURL(region string) string {
+	return "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonEC2/current/" + region + "/index.json"
+}
+
+// Source opens a region's AWS Price List bulk file for EC2
+type Source func(ctx context.Context, region string) (io.ReadCloser, error)
+
+// HTTPSource downloads bulk files from the AWS Price List. They are
+// hundreds of megabytes, and are parsed as they download.
+func HTTPSource(ctx context.Context, region string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, OfferURL(region), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download prices for %s: %w", region, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download prices for %s: %s", region, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Offer is what a bulk file has for the instance types asked for
+type Offer struct {
+	Instances map[string]Instance
+	OnDemand  map[string]float64
+}
+
+// ParseOffer reads the Linux, shared tenancy, on-demand prices of the
+// instance types from an EC2 bulk file. The file is read as a stream; as in
+// the files AWS publishes, products must come before terms.
+func ParseOffer(r io.Reader, instanceTypes []string) (*Offer, error) {
+	wanted := map[string]bool{}
+	for _, t := range instanceTypes {
+		wanted[t] = true
+	}
+	offer := &Offer{Instances: map[string]Instance{}, OnDemand: map[string]float64{}}
+	skus := map[string]string{} // product SKU -> instance type
+
+	dec := json.NewDecoder(r)
+	err := object(dec, func(key string) error {
+		switch key {
+		case "products":
+			return object(dec, func(sku string) error {
+				var p product
+				if err := dec.Decode(&p); err != nil {
+					return err
+				}
+				if a := p.Attributes; wanted[a.InstanceType] && p.linux() {
+					skus[sku] = a.InstanceType
+					offer.Instances[a.InstanceType] = p.instance()
+				}
+				return nil
+			})
+		case "terms":
+			return object(dec, func(kind string) error {
+				if kind != "OnDemand" {
+					return skip(dec)
+				}
+				return object(dec, func(sku string) error {
+					var terms map[string]term
+					if err := dec.Decode(&terms); err != nil {
+						return err
+					}
+					if t, ok := skus[sku]; ok {
+						if price := hourly(terms); price > 0 {
+							offer.OnDemand[t] = price
+						}
+					}
+					return nil
+				})
+			})
+		}
+		return skip(dec)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse price list: %w", err)
+	}
+	return offer, nil
+}
+
+type product struct {
+	ProductFamily string `json:"productFamily"`
+	Attributes    struct {
+		InstanceType      string `json:"instanceType"`
+		VCPU              string `json:"vcpu"`
+		Memory            string `json:"memory"` // "16 GiB"
+		PhysicalProcessor string `json:"physicalProcessor"`
+		OperatingSystem   string `json:"operatingSystem"`
+		Tenancy           string `json:"tenancy"`
+		PreInstalledSW    string `json:"preInstalledSw"`
+		CapacityStatus    string `json:"capacitystatus"`
+		LicenseModel      string `json:"licenseModel"`
+	} `json:"attributes"`
+}
+
+// linux reports whether the product is a plain Linux instance: no
+// preinstalled software, shared tenancy and not a capacity reservation
+func (p *product) linux() bool {
+	a := p.Attributes
+	return p.ProductFamily == "Compute Instance" && a.OperatingSystem == "Linux" &&
+		a.Tenancy == "Shared" && a.PreInstalledSW == "NA" && a.CapacityStatus == "Used" &&
+		(a.LicenseModel == "" || a.LicenseModel == "No License required")
+}
+
+func (p *product) instance() Instance {
+	a := p.Attributes
+	vcpus, _ := strconv.Atoi(a.VCPU)
+	memory, _ := strconv.ParseFloat(strings.TrimSuffix(strings.ReplaceAll(a.Memory, ",", ""), " GiB"), 64)
+	arch := "x86_64"
+	if strings.Contains(a.PhysicalProcessor, "Graviton") {
+		arch = "arm64"
+	}
+	return Instance{VCPUs: vcpus, MemoryGiB: memory, Architecture: arch}
+}
+
+type term struct {
+	PriceDimensions map[string]struct {
+		Unit         string            `json:"unit"`
+		PricePerUnit map[string]string `json:"pricePerUnit"`
+	} `json:"priceDimensions"`
+}
+
+// hourly returns the USD hourly price of a product's on-demand terms
+func hourly(terms map[string]term) float64 {
+	for _, t := range terms {
+		for _, d := range t.PriceDimensions {
+			if d.Unit != "Hrs" {
+				continue
+			}
+			if price, err := strconv.ParseFloat(d.PricePerUnit["USD"], 64); err == nil && price > 0 {
+				return price
+			}
+		}
+	}
+	return 0
+}
+
+// object reads a JSON object from the decoder, calling fn with each key
+// to read its value
+func object(dec *json.Decoder, fn func(key string) error) error {
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return fmt.Errorf("expected an object, found %v", t)
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(t.(string)); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func skip(dec *json.Decoder) error {
+	var v json.RawMessage
+	return dec.Decode(&v)
+}
+
+// SpotPrices returns the current Linux spot price of each instance type in
+// a region, averaged over its availability zones, from the aws CLI
+func SpotPrices(ctx context.Context, r command.Runner, region string, instanceTypes []string) (map[string]float64, error) {
+	args := []string{"ec2", "describe-spot-price-history", "--region", region,
+		"--product-descriptions", "Linux/UNIX", "--start-time", time.Now().UTC().Format(time.RFC3339),
+		"--query", "SpotPriceHistory[].[InstanceType,SpotPrice]", "--output", "text", "--instance-types"}
+	out, err := command.Output(ctx, r, "aws", append(args, instanceTypes...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up spot prices in %s: %w", region, err)
+	}
+
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		sums[fields[0]] += price
+		counts[fields[0]]++
+	}
+	prices := map[string]float64{}
+	for t, sum := range sums {
+		prices[t] = sum / float64(counts[t])
+	}
+	return prices, nil
+}
+
+// Updater refreshes a catalog from the AWS Price List and spot price
+// history
+type Updater struct {
+	Source Source
+	Runner command.Runner // runs the aws CLI for spot prices; nil keeps the old ones
+
+	// Warn is called with problems that leave old prices in place, such
+	// as instance types a region does not offer
+	Warn func(err error)
+}
+
+// Update returns a copy of the catalog with the prices of the instance
+// types in the regions refreshed. Prices the update does not find are
+// kept from the old catalog.
+func (u *Updater) Update(ctx context.Context, old *Catalog, regions, instanceTypes []string) (*Catalog, error) {
+	c := &Catalog{
+		Updated:   time.Now().UTC().Truncate(time.Second),
+		Source:    "AWS Price List and spot price history",
+		Instances: map[string]Instance{},
+		Regions:   map[string]map[string]Price{},
+	}
+	for t, i := range old.Instances {
+		c.Instances[t] = i
+	}
+	for r, prices := range old.Regions {
+		c.Regions[r] = map[string]Price{}
+		for t, p := range prices {
+			c.Regions[r][t] = p
+		}
+	}
+	if u.Runner == nil {
+		c.Source = "AWS Price List; spot prices from " + old.Source
+	}
+	sort.Strings(instanceTypes)
+
+	for _, region := range regions {
+		body, err := u.Source(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		offer, err := ParseOffer(body, instanceTypes)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", region, err)
+		}
+
+		var spot map[string]float64
+		if u.Runner != nil {
+			if spot, err = SpotPrices(ctx, u.Runner, region, instanceTypes); err != nil {
+				u.warn(err)
+			}
+		}
+
+		prices := c.Regions[region]
+		if prices == nil {
+			prices = map[string]Price{}
+			c.Regions[region] = prices
+		}
+		for _, t := range instanceTypes {
+			if i, ok := offer.Instances[t]; ok {
+				c.Instances[t] = i
+			}
+			p := prices[t]
+			if price, ok := offer.OnDemand[t]; ok {
+				p.OnDemand = price
+			} else {
+				u.warn(fmt.Errorf("no on-demand price for %s in %s", t, region))
+			}
+			if price, ok := spot[t]; ok {
+				p.Spot = price
+			}
+			if p.OnDemand > 0 {
+				prices[t] = p
+			}
+		}
+	}
+	return c, nil
+}
+
+func (u *Updater) warn(err error) {
+	if u.Warn != nil {
+		u.Warn(err)
+	}
+}
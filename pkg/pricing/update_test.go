@@ -0,0 +1,180 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// offer is a cut-down EC2 bulk file: the Linux c7a.2xlarge and c8g.2xlarge,
+// and products the catalog ignores
+const offer = `{
+  "formatVersion": "v1.0",
+  "offerCode": "AmazonEC2",
+  "products": {
+    "SKU1": {"sku": "SKU1", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "c7a.2xlarge", "vcpu": "8", "memory": "16 GiB", "physicalProcessor": "AMD EPYC 9R14",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used",
+      "licenseModel": "No License required"}},
+    "SKU2": {"sku": "SKU2", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "c7a.2xlarge", "vcpu": "8", "memory": "16 GiB", "physicalProcessor": "AMD EPYC 9R14",
+      "operatingSystem": "Windows", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "SKU3": {"sku": "SKU3", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "c7a.2xlarge", "vcpu": "8", "memory": "16 GiB", "physicalProcessor": "AMD EPYC 9R14",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "UnusedCapacityReservation"}},
+    "SKU4": {"sku": "SKU4", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "c8g.2xlarge", "vcpu": "8", "memory": "16 GiB", "physicalProcessor": "AWS Graviton4 Processor",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "SKU5": {"sku": "SKU5", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "m7a.large", "vcpu": "2", "memory": "8 GiB", "physicalProcessor": "AMD EPYC 9R14",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "SKU6": {"sku": "SKU6", "productFamily": "Storage", "attributes": {"volumeApiName": "gp3"}}
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU1": {"SKU1.JRTCKXETXF": {"priceDimensions": {"SKU1.JRTCKXETXF.6YS6EN2CT7": {
+        "unit": "Hrs", "pricePerUnit": {"USD": "0.4105600000"}}}}},
+      "SKU2": {"SKU2.JRTCKXETXF": {"priceDimensions": {"SKU2.JRTCKXETXF.6YS6EN2CT7": {
+        "unit": "Hrs", "pricePerUnit": {"USD": "0.7785600000"}}}}},
+      "SKU3": {"SKU3.JRTCKXETXF": {"priceDimensions": {"SKU3.JRTCKXETXF.6YS6EN2CT7": {
+        "unit": "Hrs", "pricePerUnit": {"USD": "0.4105600000"}}}}},
+      "SKU4": {"SKU4.JRTCKXETXF": {"priceDimensions": {"SKU4.JRTCKXETXF.6YS6EN2CT7": {
+        "unit": "Hrs", "pricePerUnit": {"USD": "0.3190400000"}}}}},
+      "SKU5": {"SKU5.JRTCKXETXF": {"priceDimensions": {"SKU5.JRTCKXETXF.6YS6EN2CT7": {
+        "unit": "Hrs", "pricePerUnit": {"USD": "0.1159200000"}}}}}
+    },
+    "Reserved": {
+      "SKU1": {"SKU1.4NA7Y494T4": {"priceDimensions": {"SKU1.4NA7Y494T4.6YS6EN2CT7": {
+        "unit": "Hrs", "pricePerUnit": {"USD": "0.2600000000"}}}}}
+    }
+  }
+}`
+
+func TestParseOffer(t *testing.T) {
+	o, err := ParseOffer(strings.NewReader(offer), []string{"c7a.2xlarge", "c8g.2xlarge", "c7i.2xlarge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.OnDemand) != 2 || o.OnDemand["c7a.2xlarge"] != 0.41056 || o.OnDemand["c8g.2xlarge"] != 0.31904 {
+		t.Errorf("on-demand prices = %v", o.OnDemand)
+	}
+	if got := o.Instances["c8g.2xlarge"]; got != (Instance{VCPUs: 8, MemoryGiB: 16, Architecture: "arm64"}) {
+		t.Errorf("c8g.2xlarge = %+v", got)
+	}
+	if got := o.Instances["c7a.2xlarge"].Architecture; got != "x86_64" {
+		t.Errorf("c7a.2xlarge architecture = %s", got)
+	}
+
+	if _, err := ParseOffer(strings.NewReader(`{"products": [`), nil); err == nil {
+		t.Error("expected an error for a truncated file")
+	}
+}
+
+// spotRunner prints spot price history as aws ec2 describe-spot-price-history
+// --output text does, one line per availability zone
+type spotRunner struct {
+	out  string
+	err  error
+	args []string
+}
+
+func (r *spotRunner) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	r.args = args
+	if r.err != nil {
+		return r.err
+	}
+	_, err := io.WriteString(stdout, r.out)
+	return err
+}
+
+func TestSpotPrices(t *testing.T) {
+	r := &spotRunner{out: "c7a.2xlarge\t0.1800\nc7a.2xlarge\t0.2000\nc8g.2xlarge\t0.1400\n"}
+	prices, err := SpotPrices(context.Background(), r, "us-east-1", []string{"c7a.2xlarge", "c8g.2xlarge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prices["c7a.2xlarge"] != 0.19 || prices["c8g.2xlarge"] != 0.14 {
+		t.Errorf("spot prices = %v, want the average over availability zones", prices)
+	}
+	if got := strings.Join(r.args, " "); !strings.Contains(got, "--region us-east-1") || !strings.HasSuffix(got, "--instance-types c7a.2xlarge c8g.2xlarge") {
+		t.Errorf("aws arguments = %s", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	old := &Catalog{
+		Source:    "old",
+		Instances: map[string]Instance{"c7i.2xlarge": {VCPUs: 8, MemoryGiB: 16, Architecture: "x86_64"}},
+		Regions: map[string]map[string]Price{
+			"us-east-1": {
+				"c7a.2xlarge": {OnDemand: 0.40, Spot: 0.17},
+				"c7i.2xlarge": {OnDemand: 0.357, Spot: 0.15},
+			},
+		},
+	}
+	var warnings []string
+	u := &Updater{
+		Source: func(ctx context.Context, region string) (io.ReadCloser, error) {
+			if region != "us-east-1" {
+				return nil, errors.New("unexpected region " + region)
+			}
+			return io.NopCloser(strings.NewReader(offer)), nil
+		},
+		Runner: &spotRunner{out: "c7a.2xlarge\t0.1900\n"},
+		Warn:   func(err error) { warnings = append(warnings, err.Error()) },
+	}
+
+	c, err := u.Update(context.Background(), old, []string{"us-east-1"}, []string{"c7a.2xlarge", "c7i.2xlarge", "c8g.2xlarge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prices := c.Regions["us-east-1"]
+	if p := prices["c7a.2xlarge"]; p != (Price{OnDemand: 0.41056, Spot: 0.19}) {
+		t.Errorf("c7a.2xlarge = %+v, want both prices updated", p)
+	}
+	if p := prices["c8g.2xlarge"]; p != (Price{OnDemand: 0.31904}) {
+		t.Errorf("c8g.2xlarge = %+v, want the new on-demand price and no spot price", p)
+	}
+	if p := prices["c7i.2xlarge"]; p != (Price{OnDemand: 0.357, Spot: 0.15}) {
+		t.Errorf("c7i.2xlarge = %+v, want the old prices kept", p)
+	}
+	if _, ok := c.Instance("c8g.2xlarge"); !ok {
+		t.Error("c8g.2xlarge metadata not added")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "no on-demand price for c7i.2xlarge in us-east-1") {
+		t.Errorf("warnings = %v", warnings)
+	}
+	if old.Regions["us-east-1"]["c7a.2xlarge"].OnDemand != 0.40 {
+		t.Error("Update modified the old catalog")
+	}
+
+	// Spot prices that cannot be looked up are kept
+	warnings = nil
+	u.Runner = &spotRunner{err: errors.New("aws failed")}
+	c, err = u.Update(context.Background(), old, []string{"us-east-1"}, []string{"c7a.2xlarge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := c.Regions["us-east-1"]["c7a.2xlarge"]; p != (Price{OnDemand: 0.41056, Spot: 0.17}) {
+		t.Errorf("c7a.2xlarge = %+v, want the old spot price kept", p)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "failed to look up spot prices in us-east-1") {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a semantic version, as described at https://semver.org
+type Semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // dot-separated identifiers, such as dev or beta.2
+	Build      string // build metadata, ignored when comparing
+}
+
+// ParseSemver parses a version such as 1.0.0, v1.2.3 or 1.0.0-beta.2+abc
+func ParseSemver(s string) (Semver, error) {
+	p, err := parsePartial(strings.TrimPrefix(strings.TrimSpace(s), "v"))
+	if err != nil {
+		return Semver{}, fmt.Errorf("invalid version %q: %w", s, err)
+	}
+	if p.parts != 3 {
+		return Semver{}, fmt.Errorf("invalid version %q: need major.minor.patch", s)
+	}
+	return p.Semver, nil
+}
+
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v orders before, with or after o. A
+// prerelease orders before its release: 1.0.0-dev < 1.0.0.
+func (v Semver) Compare(o Semver) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(o.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(a) - len(b))
+}
+
+// compareIdentifier orders prerelease identifiers: numeric identifiers
+// numerically, and before alphanumeric ones
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// Constraint is a version range such as >=1.0.0-dev, ^1.2, ~1.2.3,
+// >=1.0.0 <2.0.0, 1.0.0 - 1.4 or ^1.0 || ^2.0. Comparators separated by
+// spaces or commas must all hold; alternatives are separated by ||.
+//
+// Missing components are wildcards, as are x and *: 1.2 and 1.2.x mean
+// >=1.2.0 <1.3.0. ~ allows patch updates (~1.2.3 is >=1.2.3 <1.3.0) and ^
+// allows updates that do not change the leftmost non-zero component (^1.2.3
+// is >=1.2.3 <2.0.0, ^0.2.3 is >=0.2.3 <0.3.0). Prereleases order before
+// their release, so 1.0.0-dev satisfies >=1.0.0-dev but not >=1.0.0, and
+// ranges end before the prereleases of their bound: ^1.2.3 does not allow
+// 2.0.0-beta.
+type Constraint struct {
+	text string
+	sets [][]comparator
+}
+
+type comparator struct {
+	op string // =, !=, >, >=, <, <=
+	v  Semver
+}
+
+// ParseConstraint parses a version constraint
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{text: strings.TrimSpace(s)}
+	for _, alt := range strings.Split(s, "||") {
+		set, err := parseSet(alt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+func (c *Constraint) String() string {
+	return c.text
+}
+
+// Allows reports whether v satisfies the constraint
+func (c *Constraint) Allows(v Semver) bool {
+	for _, set := range c.sets {
+		ok := true
+		for _, cmp := range set {
+			if !cmp.allows(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies reports whether a version satisfies a constraint
+func Satisfies(version, constraint string) (bool, error) {
+	v, err := ParseSemver(version)
+	if err != nil {
+		return false, err
+	}
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Allows(v), nil
+}
+
+func (c comparator) allows(v Semver) bool {
+	d := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return d == 0
+	case "!=":
+		return d != 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	}
+	return false
+}
+
+// operators, longest first so >= is not read as >
+var operators = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+// parseSet parses comparators that must all hold. An empty set allows
+// every version.
+func parseSet(s string) ([]comparator, error) {
+	s = strings.TrimSpace(s)
+	if lo, hi, ok := strings.Cut(s, " - "); ok {
+		return hyphenRange(strings.TrimSpace(lo), strings.TrimSpace(hi))
+	}
+
+	var set []comparator
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	for i := 0; i < len(fields); i++ {
+		term := fields[i]
+		// Allow a space after the operator: >= 1.0.0
+		if isOperator(term) && i+1 < len(fields) {
+			i++
+			term += fields[i]
+		}
+		op := ""
+		for _, o := range operators {
+			if strings.HasPrefix(term, o) {
+				op = o
+				break
+			}
+		}
+		p, err := parsePartial(strings.TrimPrefix(strings.TrimPrefix(term, op), "v"))
+		if err != nil {
+			return nil, err
+		}
+		cmps, err := expand(op, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", term, err)
+		}
+		set = append(set, cmps...)
+	}
+	return set, nil
+}
+
+func isOperator(s string) bool {
+	for _, o := range operators {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+// hyphenRange expands lo - hi, inclusive of every version hi matches
+func hyphenRange(lo, hi string) ([]comparator, error) {
+	from, err := parsePartial(strings.TrimPrefix(lo, "v"))
+	if err != nil {
+		return nil, err
+	}
+	to, err := parsePartial(strings.TrimPrefix(hi, "v"))
+	if err != nil {
+		return nil, err
+	}
+	var set []comparator
+	if from.parts > 0 {
+		set = append(set, comparator{">=", from.Semver})
+	}
+	switch to.parts {
+	case 3:
+		set = append(set, comparator{"<=", to.Semver})
+	case 1, 2:
+		set = append(set, comparator{"<", to.next()})
+	}
+	return set, nil
+}
+
+// expand turns an operator and partial version into plain comparators
+func expand(op string, p partial) ([]comparator, error) {
+	if p.parts == 0 {
+		switch op {
+		case "", "=", ">=", "<=", "~", "^":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s needs a version", op)
+	}
+	if p.parts < 3 && op == "!=" {
+		return nil, fmt.Errorf("!= needs major.minor.patch")
+	}
+
+	v := p.Semver
+	switch op {
+	case "", "=":
+		if p.parts == 3 {
+			return []comparator{{"=", v}}, nil
+		}
+		return []comparator{{">=", v}, {"<", p.next()}}, nil
+	case ">":
+		if p.parts == 3 {
+			return []comparator{{">", v}}, nil
+		}
+		return []comparator{{">=", p.next()}}, nil
+	case "<=":
+		if p.parts == 3 {
+			return []comparator{{"<=", v}}, nil
+		}
+		return []comparator{{"<", p.next()}}, nil
+	case "~":
+		if p.parts == 1 {
+			return []comparator{{">=", v}, {"<", p.next()}}, nil
+		}
+		return []comparator{{">=", v}, {"<", lowest(v.Major, v.Minor+1, 0)}}, nil
+	case "^":
+		var upper Semver
+		switch {
+		case v.Major > 0 || p.parts == 1:
+			upper = lowest(v.Major+1, 0, 0)
+		case v.Minor > 0 || p.parts == 2:
+			upper = lowest(0, v.Minor+1, 0)
+		default:
+			upper = lowest(0, 0, v.Patch+1)
+		}
+		return []comparator{{">=", v}, {"<", upper}}, nil
+	}
+	return []comparator{{op, v}}, nil
+}
+
+// partial is a version that may be missing components: parts is how many
+// of major, minor and patch were given
+type partial struct {
+	Semver
+	parts int
+}
+
+// next returns the first version after every version p matches
+func (p partial) next() Semver {
+	if p.parts == 1 {
+		return lowest(p.Major+1, 0, 0)
+	}
+	return lowest(p.Major, p.Minor+1, 0)
+}
+
+// lowest returns the first version of a release, its lowest possible
+// prerelease, so that ranges ending there exclude its prereleases
+func lowest(major, minor, patch int) Semver {
+	return Semver{Major: major, Minor: minor, Patch: patch, Prerelease: "0"}
+}
+
+func parsePartial(s string) (partial, error) {
+	var p partial
+	s, p.Build, _ = strings.Cut(s, "+")
+	var hasPrerelease bool
+	s, p.Prerelease, hasPrerelease = strings.Cut(s, "-")
+	if hasPrerelease && p.Prerelease == "" {
+		return p, fmt.Errorf("empty prerelease")
+	}
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return p, nil
+	}
+
+	components := strings.Split(s, ".")
+	if len(components) > 3 {
+		return p, fmt.Errorf("%s has more than three components", s)
+	}
+	values := []*int{&p.Major, &p.Minor, &p.Patch}
+	for i, c := range components {
+		if c == "*" || c == "x" || c == "X" {
+			break
+		}
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 0 || (len(c) > 1 && c[0] == '0') {
+			return p, fmt.Errorf("%q is not a version number", c)
+		}
+		*values[i] = n
+		p.parts++
+	}
+	if p.Prerelease != "" {
+		if p.parts < 3 {
+			return p, fmt.Errorf("prerelease %s needs major.minor.patch", p.Prerelease)
+		}
+		for _, id := range strings.Split(p.Prerelease, ".") {
+			if id == "" || strings.Trim(id, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-") != "" {
+				return p, fmt.Errorf("invalid prerelease %q", p.Prerelease)
+			}
+		}
+	}
+	return p, nil
+}
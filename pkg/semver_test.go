@@ -0,0 +1,127 @@
+// Copyright 2025 Scott Friedman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import "testing"
+
+func TestSemverCompare(t *testing.T) {
+	// In ascending order, from the semver.org precedence example
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := mustParse(t, ordered[i]), mustParse(t, ordered[j])
+			if got, want := a.Compare(b), sign(i-j); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+	if mustParse(t, "1.0.0+build.1").Compare(mustParse(t, "1.0.0+build.2")) != 0 {
+		t.Error("build metadata affects precedence")
+	}
+}
+
+func TestParseSemver(t *testing.T) {
+	v := mustParse(t, "v1.2.3-beta.2+abc")
+	if v != (Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta.2", Build: "abc"}) {
+		t.Errorf("parsed %+v", v)
+	}
+	for _, bad := range []string{"", "1.2", "1.2.3.4", "01.2.3", "1.2.x", "1.2.3-", "1.2.3-beta..1", "one.two.three"} {
+		if _, err := ParseSemver(bad); err == nil {
+			t.Errorf("ParseSemver(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allows     []string
+		rejects    []string
+	}{
+		{">=1.0.0-dev", []string{"1.0.0-dev", "1.0.0-rc.1", "1.0.0", "2.3.0"}, []string{"1.0.0-alpha", "0.9.9"}},
+		{">=1.0.0", []string{"1.0.0", "1.0.1"}, []string{"1.0.0-dev"}},
+		{">= 1.0.0, < 2.0.0", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.1.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9", "1.2.0"}, []string{"1.3.0-alpha", "1.3.0"}},
+		{"1.2", []string{"1.2.0", "1.2.7"}, []string{"1.1.9", "1.3.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"=1.2.3", []string{"1.2.3", "1.2.3+build"}, []string{"1.2.4"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.2.2", "1.3.0"}},
+		{"~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0.2", []string{"0.2.0", "0.2.9"}, []string{"0.3.0"}},
+		{"^1.0.0-beta", []string{"1.0.0-beta.2", "1.5.0"}, []string{"1.0.0-alpha", "2.0.0"}},
+		{"1.0.0 - 1.4", []string{"1.0.0", "1.4.9"}, []string{"1.5.0", "0.9.0"}},
+		{"1.0.0 - 1.4.2", []string{"1.4.2"}, []string{"1.4.3"}},
+		{"^1.0 || ^3.0", []string{"1.2.0", "3.1.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.0.1", "9.9.9-rc.1"}, nil},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.constraint, err)
+			continue
+		}
+		for _, v := range tt.allows {
+			if !c.Allows(mustParse(t, v)) {
+				t.Errorf("%q does not allow %s", tt.constraint, v)
+			}
+		}
+		for _, v := range tt.rejects {
+			if c.Allows(mustParse(t, v)) {
+				t.Errorf("%q allows %s", tt.constraint, v)
+			}
+		}
+	}
+
+	for _, bad := range []string{">=1.0.0-", "~>", ">", "!=1.2", ">=1.2-beta", "1.2.3.4", "abc"} {
+		if _, err := ParseConstraint(bad); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestVersionIsSemver(t *testing.T) {
+	for _, v := range []string{Version, MinAppSpecVersion, AppSpecVersion} {
+		if _, err := ParseSemver(v); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Semver {
+	t.Helper()
+	v, err := ParseSemver(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -25,6 +25,10 @@ const (
 
 	// MinAppSpecVersion is the minimum app.yaml version supported
 	MinAppSpecVersion = "0.1.0"
+
+	// AppSpecVersion is the app.yaml spec_version this platform reads and
+	// writes; older documents are migrated to it
+	AppSpecVersion = "0.2.0"
 )
 
 // VersionInfo contains detailed version information